@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/circuit"
+	"undergroundempire/modules/governance"
+	"undergroundempire/modules/params"
+	"undergroundempire/modules/treasury"
+	"undergroundempire/modules/upgrade"
+)
+
+func init() {
+	addNodeFlag(governanceCmd)
+
+	for _, cmd := range []*cobra.Command{governanceSubmitCmd, governanceDepositCmd, governanceVoteCmd} {
+		addTxFlags(cmd)
+	}
+	governanceTallyCmd.Flags().StringP("output", "o", "text", "output format (text|json)")
+	governanceListCmd.Flags().StringP("output", "o", "text", "output format (text|json)")
+	governanceListCmd.Flags().String("status", "", "only list proposals with this status (deposit_period|active|passed|rejected|executed|failed)")
+
+	governanceCmd.AddCommand(governanceSubmitCmd)
+	governanceCmd.AddCommand(governanceDepositCmd)
+	governanceCmd.AddCommand(governanceVoteCmd)
+	governanceCmd.AddCommand(governanceTallyCmd)
+	governanceCmd.AddCommand(governanceListCmd)
+}
+
+// governanceCmd represents the governance command group
+var governanceCmd = &cobra.Command{
+	Use:   "governance",
+	Short: "Participate in network governance",
+	Long: `Participate in the governance of the Underground Empire network.
+
+Governance features include:
+- Submitting proposals for protocol upgrades
+- Voting on proposals
+- Parameter change proposals
+- Emergency proposals for critical issues
+
+Transactions are signed with a key from the keyring and broadcast to the
+node selected with --node; queries read the state of that node.`,
+}
+
+// proposalFile is the JSON file read by submit-proposal
+type proposalFile struct {
+	Title       string
+	Description string
+	Type        types.ProposalType
+	Content     json.RawMessage
+	Expedited   bool
+	Deposit     string // Initial deposit, such as "1000UE"
+}
+
+// governanceSubmitCmd submits a proposal described in a JSON file
+var governanceSubmitCmd = &cobra.Command{
+	Use:   "submit-proposal <from-key> <proposal-file>",
+	Short: "Submit a proposal described in a JSON file",
+	Long: `Submit a proposal described in a JSON file, such as:
+
+  {
+    "Title": "Lengthen epochs",
+    "Description": "...",
+    "Type": "parameter_change",
+    "Content": {"Changes": [{"Subspace": "chain", "Key": "EpochDuration", "Value": 200}]},
+    "Deposit": "1000UE"
+  }
+
+Type is one of:
+  text                  no Content
+  parameter_change      {"Changes": [{"Subspace", "Key", "Value"}], "EffectiveHeight"}
+  community_pool_spend  {"Recipient", "Amount": {"Amount", "Denom"}}
+  software_upgrade      {"Name", "Height", "Info"}
+  circuit_breaker       {"Pause": [routes], "Resume": [routes]}
+
+The deposit is taken from the key's account. The proposal enters its voting
+period once its deposits reach the minimum deposit.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("failed to read proposal: %v", err)
+		}
+		var proposal proposalFile
+		if err := strictUnmarshal(data, &proposal); err != nil {
+			return fmt.Errorf("invalid proposal %s: %v", args[1], err)
+		}
+		if err := validateProposalContent(proposal.Type, proposal.Content); err != nil {
+			return fmt.Errorf("invalid proposal %s: %v", args[1], err)
+		}
+
+		deposit := types.NewUECoins(types.ZeroInt())
+		if proposal.Deposit != "" {
+			if deposit, err = parseUEAmount(proposal.Deposit); err != nil {
+				return fmt.Errorf("invalid deposit: %v", err)
+			}
+		}
+
+		msg, err := types.NewModuleMsg(governance.MsgTypeSubmitProposal, governance.MsgSubmitProposal{
+			Title:          proposal.Title,
+			Description:    proposal.Description,
+			Type:           proposal.Type,
+			Content:        proposal.Content,
+			Expedited:      proposal.Expedited,
+			InitialDeposit: deposit.Amount,
+		})
+		if err != nil {
+			return err
+		}
+		return broadcastMsg(cmd, args[0], types.GovernanceModuleName, msg)
+	},
+}
+
+// governanceDepositCmd adds to the deposit of a proposal
+var governanceDepositCmd = &cobra.Command{
+	Use:   "deposit <from-key> <proposal-id> <amount>",
+	Short: "Add to the deposit of a proposal",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseProposalID(args[1])
+		if err != nil {
+			return err
+		}
+		amount, err := parseUEAmount(args[2])
+		if err != nil {
+			return err
+		}
+
+		msg, err := types.NewModuleMsg(governance.MsgTypeDeposit, governance.MsgDeposit{ProposalID: id, Amount: amount.Amount})
+		if err != nil {
+			return err
+		}
+		return broadcastMsg(cmd, args[0], types.GovernanceModuleName, msg)
+	},
+}
+
+// governanceVoteCmd votes on a proposal
+var governanceVoteCmd = &cobra.Command{
+	Use:   "vote <from-key> <proposal-id> <yes|no|abstain|no_with_veto>",
+	Short: "Vote on a proposal in its voting period",
+	Long: `Vote on a proposal in its voting period. Votes are weighted by the
+voter's bonded stake when the voting period ends, and a later vote replaces
+an earlier one.`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseProposalID(args[1])
+		if err != nil {
+			return err
+		}
+		option, err := types.ParseVoteOption(args[2])
+		if err != nil {
+			return err
+		}
+
+		msg, err := types.NewModuleMsg(governance.MsgTypeVote, governance.MsgVote{ProposalID: id, Option: option})
+		if err != nil {
+			return err
+		}
+		return broadcastMsg(cmd, args[0], types.GovernanceModuleName, msg)
+	},
+}
+
+// governanceTallyCmd prints the current tally of a proposal
+var governanceTallyCmd = &cobra.Command{
+	Use:   "tally <proposal-id>",
+	Short: "Show the current stake-weighted tally of a proposal",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseProposalID(args[0])
+		if err != nil {
+			return err
+		}
+		client, err := nodeClient()
+		if err != nil {
+			return err
+		}
+		proposal, err := client.Proposal(id)
+		if err != nil {
+			return err
+		}
+		tally, err := client.Tally(id)
+		if err != nil {
+			return err
+		}
+
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			return printJSON(cmd.OutOrStdout(), tally)
+		}
+
+		w := cmd.OutOrStdout()
+		fmt.Fprintf(w, "Proposal %d: %s (%s)\n", proposal.ID, proposal.Title, proposal.Status)
+		voted := tally.TotalVoted()
+		for _, line := range []struct {
+			label string
+			power types.Int
+		}{
+			{"Yes", tally.Yes},
+			{"No", tally.No},
+			{"Abstain", tally.Abstain},
+			{"No with veto", tally.NoWithVeto},
+		} {
+			fmt.Fprintf(w, "  %-13s %s (%s)\n", line.label+":", types.FormatCoin(types.NewUECoins(line.power)), percent(line.power, voted))
+		}
+		fmt.Fprintf(w, "  Turnout:      %s of %s bonded (%s)\n",
+			types.FormatCoin(types.NewUECoins(voted)), types.FormatCoin(types.NewUECoins(tally.TotalBonded)), percent(voted, tally.TotalBonded))
+		return nil
+	},
+}
+
+// governanceListCmd lists proposals
+var governanceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List proposals",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, err := parseProposalStatus(cmd)
+		if err != nil {
+			return err
+		}
+		client, err := nodeClient()
+		if err != nil {
+			return err
+		}
+		proposals, err := client.Proposals(status)
+		if err != nil {
+			return err
+		}
+
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			return printJSON(cmd.OutOrStdout(), proposals)
+		}
+		if len(proposals) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No proposals found")
+		}
+		w := cmd.OutOrStdout()
+		for _, proposal := range proposals {
+			fmt.Fprintf(w, "- id: %d\n", proposal.ID)
+			fmt.Fprintf(w, "  title: %s\n", proposal.Title)
+			fmt.Fprintf(w, "  type: %s\n", proposal.Type)
+			fmt.Fprintf(w, "  status: %s\n", proposal.Status)
+			fmt.Fprintf(w, "  proposer: %s\n", proposal.Proposer)
+			fmt.Fprintf(w, "  deposit: %s\n", types.FormatCoin(types.NewUECoins(proposal.TotalDeposit)))
+			switch proposal.Status {
+			case types.ProposalStatusDeposit:
+				fmt.Fprintf(w, "  deposit end height: %d\n", proposal.DepositEndHeight)
+			case types.ProposalStatusActive:
+				fmt.Fprintf(w, "  voting end height: %d\n", proposal.VotingEndHeight)
+			}
+		}
+		return nil
+	},
+}
+
+// parseProposalStatus returns the proposal status selected with --status,
+// empty when every status is listed
+func parseProposalStatus(cmd *cobra.Command) (types.ProposalStatus, error) {
+	status, _ := cmd.Flags().GetString("status")
+	switch types.ProposalStatus(status) {
+	case "", types.ProposalStatusDeposit, types.ProposalStatusActive, types.ProposalStatusPassed,
+		types.ProposalStatusRejected, types.ProposalStatusExecuted, types.ProposalStatusFailed:
+		return types.ProposalStatus(status), nil
+	default:
+		return "", fmt.Errorf("unknown proposal status: %s", status)
+	}
+}
+
+// validateProposalContent checks that the content of a proposal decodes
+// into the payload its type expects
+func validateProposalContent(proposalType types.ProposalType, content json.RawMessage) error {
+	empty := len(bytes.TrimSpace(content)) == 0 || string(bytes.TrimSpace(content)) == "null"
+
+	var payload interface{}
+	switch proposalType {
+	case types.ProposalTypeText:
+		if !empty {
+			return fmt.Errorf("text proposals have no content")
+		}
+		return nil
+	case types.ProposalTypeParameterChange:
+		payload = &params.ParameterChangeProposal{}
+	case types.ProposalTypeCommunitySpend:
+		payload = &treasury.CommunityPoolSpendProposal{}
+	case types.ProposalTypeSoftwareUpgrade:
+		payload = &upgrade.Plan{}
+	case types.ProposalTypeCircuitBreaker:
+		payload = &circuit.CircuitBreakerProposal{}
+	default:
+		return fmt.Errorf("unknown proposal type %q", proposalType)
+	}
+
+	if empty {
+		return fmt.Errorf("%s proposals need content", proposalType)
+	}
+	if err := strictUnmarshal(content, payload); err != nil {
+		return fmt.Errorf("invalid %s content: %v", proposalType, err)
+	}
+	if plan, ok := payload.(*upgrade.Plan); ok {
+		return plan.Validate()
+	}
+	return nil
+}
+
+// strictUnmarshal decodes JSON, rejecting unknown fields
+func strictUnmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// parseProposalID parses a proposal ID argument
+func parseProposalID(s string) (uint64, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid proposal ID %q", s)
+	}
+	return id, nil
+}
+
+// parseUEAmount parses a positive amount of the native token
+func parseUEAmount(s string) (types.CoinAmount, error) {
+	amount, err := types.ParseCoinAmount(s)
+	if err != nil {
+		return types.CoinAmount{}, err
+	}
+	if amount.Denom != types.NativeDenom || !amount.IsPositive() {
+		return types.CoinAmount{}, fmt.Errorf("amount must be a positive amount of %s, got %s", types.DisplayDenom, s)
+	}
+	return amount, nil
+}
+
+// percent formats part as a percentage of total with two decimals
+func percent(part, total types.Int) string {
+	if !total.IsPositive() {
+		return "0.00%"
+	}
+	basisPoints := part.Mul(types.NewInt(10000)).Quo(total).Uint64()
+	return fmt.Sprintf("%d.%02d%%", basisPoints/100, basisPoints%100)
+}
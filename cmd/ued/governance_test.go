@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"undergroundempire/core/types"
+)
+
+func TestValidateProposalContent(t *testing.T) {
+	tests := []struct {
+		name         string
+		proposalType types.ProposalType
+		content      string
+		wantErr      bool
+	}{
+		{"text without content", types.ProposalTypeText, "", false},
+		{"text with null content", types.ProposalTypeText, "null", false},
+		{"text with content", types.ProposalTypeText, `{"Title":"x"}`, true},
+		{"parameter change", types.ProposalTypeParameterChange, `{"Changes":[],"EffectiveHeight":10}`, false},
+		{"parameter change with unknown field", types.ProposalTypeParameterChange, `{"Changes":[],"Height":10}`, true},
+		{"community spend", types.ProposalTypeCommunitySpend, `{"Amount":{"Amount":"5","Denom":"aue"}}`, false},
+		{"community spend without content", types.ProposalTypeCommunitySpend, "", true},
+		{"software upgrade", types.ProposalTypeSoftwareUpgrade, `{"Name":"v2","Height":100}`, false},
+		{"software upgrade without height", types.ProposalTypeSoftwareUpgrade, `{"Name":"v2"}`, true},
+		{"software upgrade with malformed JSON", types.ProposalTypeSoftwareUpgrade, `{"Name":`, true},
+		{"circuit breaker", types.ProposalTypeCircuitBreaker, `{"Pause":["treasury/send"]}`, false},
+		{"unknown type", types.ProposalType("grant"), `{}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateProposalContent(tt.proposalType, json.RawMessage(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateProposalContent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseUEAmount(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    types.Int
+		wantErr bool
+	}{
+		{"5UE", types.NewUEAmount(5), false},
+		{"1000aue", types.NewInt(1000), false},
+		{"0.5UE", types.NewUEAmount(5).Quo(types.NewInt(10)), false},
+		{"0UE", types.Int{}, true},
+		{"-1UE", types.Int{}, true},
+		{"5gold", types.Int{}, true},
+		{"5ue", types.Int{}, true},
+		{"UE", types.Int{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseUEAmount(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseUEAmount(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Denom != types.NativeDenom || !got.Amount.Equal(tt.want) {
+				t.Errorf("parseUEAmount(%q) = %s%s, want %s%s", tt.input, got.Amount, got.Denom, tt.want, types.NativeDenom)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		part, total int64
+		want        string
+	}{
+		{0, 0, "0.00%"},
+		{5, 0, "0.00%"},
+		{0, 10, "0.00%"},
+		{1, 3, "33.33%"},
+		{2, 3, "66.66%"},
+		{1, 8, "12.50%"},
+		{10, 10, "100.00%"},
+	}
+
+	for _, tt := range tests {
+		if got := percent(types.NewInt(tt.part), types.NewInt(tt.total)); got != tt.want {
+			t.Errorf("percent(%d, %d) = %s, want %s", tt.part, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestParseProposalStatus(t *testing.T) {
+	tests := []struct {
+		status  string
+		want    types.ProposalStatus
+		wantErr bool
+	}{
+		{"", "", false},
+		{"deposit_period", types.ProposalStatusDeposit, false},
+		{"active", types.ProposalStatusActive, false},
+		{"passed", types.ProposalStatusPassed, false},
+		{"rejected", types.ProposalStatusRejected, false},
+		{"executed", types.ProposalStatusExecuted, false},
+		{"failed", types.ProposalStatusFailed, false},
+		{"voting", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().String("status", "", "")
+			cmd.Flags().Set("status", tt.status)
+
+			got, err := parseProposalStatus(cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseProposalStatus(%q) error = %v, wantErr %v", tt.status, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseProposalStatus(%q) = %q, want %q", tt.status, got, tt.want)
+			}
+		})
+	}
+}
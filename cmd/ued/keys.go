@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"undergroundempire/core/keyring"
+)
+
+// keyringDir is the keyring directory selected with --keyring-dir
+var keyringDir string
+
+// stdinReader reads prompted input when stdin is not a terminal, so that
+// several answers can be piped in, one per line
+var stdinReader = bufio.NewReader(os.Stdin)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&keyringDir, "keyring-dir", "", "keyring directory (default <home>/keyring)")
+
+	keysAddCmd.Flags().Bool("recover", false, "recover the key from a BIP39 mnemonic")
+	keysListCmd.Flags().StringP("output", "o", "text", "output format (text|json)")
+	keysShowCmd.Flags().StringP("output", "o", "text", "output format (text|json)")
+	keysShowCmd.Flags().BoolP("address", "a", false, "print only the address")
+
+	keysCmd.AddCommand(keysAddCmd)
+	keysCmd.AddCommand(keysListCmd)
+	keysCmd.AddCommand(keysShowCmd)
+	keysCmd.AddCommand(keysDeleteCmd)
+	keysCmd.AddCommand(keysExportCmd)
+	keysCmd.AddCommand(keysImportCmd)
+	rootCmd.AddCommand(keysCmd)
+}
+
+// keysCmd represents the keys command group
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage the keys that sign transactions",
+	Long: `Manage named account keys. Keys are stored in <home>/keyring, each
+encrypted with its own passphrase, and are derived from a BIP39 mnemonic
+along the path m/44'/28846'/0'/0'/0'.
+
+Passphrases are read from the terminal without echo. When stdin is not a
+terminal, every prompt reads one line from stdin instead.`,
+}
+
+// keysAddCmd creates or recovers a key
+var keysAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Create a new key, or recover one from its mnemonic",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		recoverKey, _ := cmd.Flags().GetBool("recover")
+		kr := openKeyring()
+
+		var mnemonic string
+		if recoverKey {
+			var err error
+			if mnemonic, err = readLine("Enter your BIP39 mnemonic: "); err != nil {
+				return err
+			}
+		}
+		passphrase, err := readPassphrase("Enter a passphrase to encrypt the key: ", true)
+		if err != nil {
+			return err
+		}
+
+		var info keyring.Info
+		if recoverKey {
+			info, err = kr.Recover(args[0], mnemonic, passphrase)
+		} else {
+			info, mnemonic, err = kr.NewMnemonic(args[0], passphrase)
+		}
+		if err != nil {
+			return err
+		}
+
+		printKey(cmd.OutOrStdout(), info)
+		if !recoverKey {
+			fmt.Fprintf(cmd.OutOrStdout(), "\n**Write this mnemonic down and keep it safe. It is the only way to recover the key.**\n\n%s\n", mnemonic)
+		}
+		return nil
+	},
+}
+
+// keysListCmd lists the keys in the keyring
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all keys",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		infos, err := openKeyring().List()
+		if err != nil {
+			return err
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "json" {
+			return printJSON(cmd.OutOrStdout(), infos)
+		}
+		if len(infos) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No keys found")
+		}
+		for _, info := range infos {
+			printKey(cmd.OutOrStdout(), info)
+		}
+		return nil
+	},
+}
+
+// keysShowCmd shows one key
+var keysShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show the address and public key of a key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := openKeyring().Key(args[0])
+		if err != nil {
+			return err
+		}
+
+		if addressOnly, _ := cmd.Flags().GetBool("address"); addressOnly {
+			fmt.Fprintln(cmd.OutOrStdout(), info.Address)
+			return nil
+		}
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			return printJSON(cmd.OutOrStdout(), info)
+		}
+		printKey(cmd.OutOrStdout(), info)
+		return nil
+	},
+}
+
+// keysDeleteCmd deletes a key
+var keysDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a key, confirmed with its passphrase",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		passphrase, err := readPassphrase(fmt.Sprintf("Enter the passphrase of %s to delete it: ", args[0]), false)
+		if err != nil {
+			return err
+		}
+		if err := openKeyring().Delete(args[0], passphrase); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Key %s deleted\n", args[0])
+		return nil
+	},
+}
+
+// keysExportCmd exports a key as an encrypted armored block
+var keysExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Export a key, encrypted with an export passphrase",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		passphrase, err := readPassphrase(fmt.Sprintf("Enter the passphrase of %s: ", args[0]), false)
+		if err != nil {
+			return err
+		}
+		exportPassphrase, err := readPassphrase("Enter a passphrase to encrypt the exported key: ", true)
+		if err != nil {
+			return err
+		}
+
+		armor, err := openKeyring().Export(args[0], passphrase, exportPassphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(cmd.OutOrStdout(), armor)
+		return nil
+	},
+}
+
+// keysImportCmd imports a key exported with keys export
+var keysImportCmd = &cobra.Command{
+	Use:   "import <name> <armor-file>",
+	Short: "Import a key exported with keys export",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		armor, err := os.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("failed to read armored key: %v", err)
+		}
+		exportPassphrase, err := readPassphrase("Enter the passphrase of the exported key: ", false)
+		if err != nil {
+			return err
+		}
+		passphrase, err := readPassphrase("Enter a passphrase to encrypt the key: ", true)
+		if err != nil {
+			return err
+		}
+
+		info, err := openKeyring().Import(args[0], string(armor), exportPassphrase, passphrase)
+		if err != nil {
+			return err
+		}
+		printKey(cmd.OutOrStdout(), info)
+		return nil
+	},
+}
+
+// openKeyring opens the file keyring selected with --keyring-dir
+func openKeyring() *keyring.Keyring {
+	dir := keyringDir
+	if dir == "" {
+		dir = filepath.Join(homeDir, keyring.DirName)
+	}
+	return keyring.NewFileKeyring(dir)
+}
+
+// printKey prints a key in the text output format
+func printKey(w io.Writer, info keyring.Info) {
+	fmt.Fprintf(w, "- name: %s\n", info.Name)
+	fmt.Fprintf(w, "  address: %s\n", info.Address)
+	fmt.Fprintf(w, "  pubkey: %s\n", base64.StdEncoding.EncodeToString(info.PubKey))
+}
+
+// printJSON prints a value as indented JSON
+func printJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// readLine prompts for one line of input
+func readLine(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read input: %v", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readPassphrase prompts for a passphrase without echo, asking twice if
+// confirm is set
+func readPassphrase(prompt string, confirm bool) (string, error) {
+	read := func(prompt string) (string, error) {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return readLine(prompt)
+		}
+
+		fmt.Fprint(os.Stderr, prompt)
+		passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %v", err)
+		}
+		return string(passphrase), nil
+	}
+
+	passphrase, err := read(prompt)
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		again, err := read("Repeat the passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
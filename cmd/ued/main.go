@@ -3,19 +3,32 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"undergroundempire/core/crypto"
 	"undergroundempire/core/types"
 	"undergroundempire/modules/consensus"
 	"undergroundempire/modules/validator"
+	app "undergroundempire/node"
+	"undergroundempire/node/rpc"
 )
 
 var (
 	// Version is set during build
 	Version = "dev"
 
+	// DefaultNodeHome is the default node home directory
+	DefaultNodeHome = defaultNodeHome()
+
+	// homeDir is the node home directory selected with --home
+	homeDir string
+
 	// Root command
 	rootCmd = &cobra.Command{
 		Use:   "ued",
@@ -37,8 +50,39 @@ Key Features:
 )
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&homeDir, "home", DefaultNodeHome, "node home directory")
+
+	initCmd.Flags().String("chain-id", types.DefaultChainID, "chain ID of the new network")
+	initCmd.Flags().Bool("overwrite", false, "overwrite an existing genesis file")
+
+	// Genesis transactions
+	gentxCmd.Flags().String("commission", "0.10", "commission rate of the validator (0-1)")
+	gentxCmd.Flags().String("output-document", "", "write the gentx to this file instead of <home>/config/gentx")
+	collectGentxsCmd.Flags().String("gentx-dir", "", "directory of gentx files (default <home>/config/gentx)")
+
+	// Node configuration overrides, one flag per config key
+	for _, key := range app.ConfigKeys() {
+		startCmd.Flags().String(configFlagName(key), "", fmt.Sprintf("override %s (env %s)", key, app.ConfigEnvVar(key)))
+	}
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	// Local testnet
+	testnetDefaults := app.DefaultTestnetOptions()
+	testnetCmd.Flags().IntP("validators", "n", testnetDefaults.Validators, "number of validator nodes")
+	testnetCmd.Flags().StringP("output", "o", testnetDefaults.OutputDir, "directory for the node home directories")
+	testnetCmd.Flags().String("chain-id", testnetDefaults.ChainID, "chain ID of the testnet")
+	testnetCmd.Flags().Int("starting-port", testnetDefaults.StartingPort, "first port assigned on localhost")
+
 	// Add subcommands
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(addGenesisAccountCmd)
+	rootCmd.AddCommand(gentxCmd)
+	rootCmd.AddCommand(collectGentxsCmd)
+	rootCmd.AddCommand(testnetCmd)
 	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(validatorCmd)
 	rootCmd.AddCommand(treasuryCmd)
@@ -46,28 +90,348 @@ func init() {
 	rootCmd.AddCommand(demoConsensusCmd)
 }
 
+// initCmd writes a default genesis file to the node home
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Initialize the node home and genesis file",
+	Long: `Create the node home directory and write a genesis file with default
+parameters to <home>/config/genesis.json, along with a default node
+configuration in <home>/config/config.toml if there is none. Accounts and validators can then be
+added to the genesis file before the network is started.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chainID, _ := cmd.Flags().GetString("chain-id")
+		overwrite, _ := cmd.Flags().GetBool("overwrite")
+
+		path := genesisPath()
+		if _, err := os.Stat(path); err == nil && !overwrite {
+			return fmt.Errorf("genesis file %s already exists, use --overwrite to replace it", path)
+		}
+
+		genesis := app.DefaultGenesis(chainID)
+		if err := genesis.Validate(); err != nil {
+			return err
+		}
+		if err := genesis.Save(path); err != nil {
+			return err
+		}
+		if _, err := os.Stat(configPath()); os.IsNotExist(err) {
+			if err := app.DefaultConfig().Save(configPath()); err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(filepath.Join(homeDir, "data"), 0o755); err != nil {
+			return fmt.Errorf("failed to create data directory: %v", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Initialized chain %s\n", genesis.ChainID)
+		fmt.Fprintf(cmd.OutOrStdout(), "Genesis file: %s\n", path)
+		return nil
+	},
+}
+
+// addGenesisAccountCmd funds an account in the genesis file
+var addGenesisAccountCmd = &cobra.Command{
+	Use:   "add-genesis-account <address> <coins>",
+	Short: "Fund an account in the genesis file",
+	Long: `Add an account to the genesis file, or add to its balance if it is
+already there. Coins are a comma-separated list such as "50000UE,100stake".`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address, err := types.NewAddress(args[0])
+		if err != nil {
+			return err
+		}
+		coins, err := types.ParseCoins(args[1])
+		if err != nil {
+			return err
+		}
+
+		genesis, err := app.ReadGenesis(genesisPath())
+		if err != nil {
+			return err
+		}
+		if err := genesis.AddAccount(address, coins); err != nil {
+			return err
+		}
+		if err := genesis.Validate(); err != nil {
+			return err
+		}
+		return genesis.Save(genesisPath())
+	},
+}
+
+// gentxCmd signs a create-validator transaction for a genesis launch
+var gentxCmd = &cobra.Command{
+	Use:   "gentx <validator-id> <stake>",
+	Short: "Sign a genesis transaction creating a validator",
+	Long: `Sign a create-validator transaction with the operator key in
+<home>/config/validator_key.json, creating the key if needed. The stake, such
+as "30000UE", is bonded from the operator's genesis account. Send the gentx
+file to the launch coordinator, who merges all of them with collect-gentxs.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		genesis, err := app.ReadGenesis(genesisPath())
+		if err != nil {
+			return err
+		}
+
+		stake, err := types.ParseCoinAmount(args[1])
+		if err != nil {
+			return err
+		}
+		if stake.Denom != types.NativeDenom {
+			return fmt.Errorf("stake must be in %s, got %s", types.DisplayDenom, stake.Denom)
+		}
+		if !genesis.Params.Chain.IsValidatorEligible(stake.Amount) {
+			return fmt.Errorf("stake %s is below the minimum %s", types.FormatCoin(stake),
+				types.FormatCoin(types.NewUECoins(genesis.Params.Chain.MinValidatorStake)))
+		}
+
+		commissionFlag, _ := cmd.Flags().GetString("commission")
+		commission, err := types.ParseDec(commissionFlag)
+		if err != nil {
+			return fmt.Errorf("invalid commission: %v", err)
+		}
+
+		key, err := crypto.LoadOrGenerateKeyFile(filepath.Join(homeDir, "config", app.ValidatorKeyFile))
+		if err != nil {
+			return err
+		}
+
+		tx := app.NewGenTx(key.PrivKey, app.MsgCreateValidator{
+			ChainID:    genesis.ChainID,
+			ID:         args[0],
+			Stake:      stake.Amount,
+			Commission: commission,
+		})
+
+		output, _ := cmd.Flags().GetString("output-document")
+		if output == "" {
+			output = filepath.Join(homeDir, "config", app.GenTxDir, fmt.Sprintf("gentx-%s.json", args[0]))
+		}
+		if err := tx.Save(output); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Operator address: %s\n", key.Address)
+		fmt.Fprintf(cmd.OutOrStdout(), "Genesis transaction written to %s\n", output)
+		return nil
+	},
+}
+
+// collectGentxsCmd merges signed genesis transactions into the genesis file
+var collectGentxsCmd = &cobra.Command{
+	Use:   "collect-gentxs",
+	Short: "Add the validators of signed genesis transactions to the genesis file",
+	Long: `Verify the signature and stake of every gentx file and add its
+validator to the genesis file. The genesis file is left unchanged if any
+gentx is invalid.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		genesis, err := app.ReadGenesis(genesisPath())
+		if err != nil {
+			return err
+		}
+
+		dir, _ := cmd.Flags().GetString("gentx-dir")
+		if dir == "" {
+			dir = filepath.Join(homeDir, "config", app.GenTxDir)
+		}
+
+		count, err := genesis.CollectGenTxs(dir)
+		if err != nil {
+			return err
+		}
+		if err := genesis.Save(genesisPath()); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Collected %d genesis transactions, %d genesis validators\n",
+			count, len(genesis.Validators))
+		return nil
+	},
+}
+
+// testnetCmd generates the node homes of a local testnet
+var testnetCmd = &cobra.Command{
+	Use:   "testnet",
+	Short: "Generate the node homes of a local testnet",
+	Long: `Generate a home directory per validator, each with a validator key, a
+config and the shared genesis file. Every node is assigned a P2P and an RPC
+port on localhost, so the nodes can be started side by side with
+"ued start --home <output>/node<i>".
+
+Peer networking is not implemented yet: the nodes do not connect to each
+other, and each one produces its own copy of the chain from the shared
+genesis.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := app.DefaultTestnetOptions()
+		opts.Validators, _ = cmd.Flags().GetInt("validators")
+		opts.OutputDir, _ = cmd.Flags().GetString("output")
+		opts.ChainID, _ = cmd.Flags().GetString("chain-id")
+		opts.StartingPort, _ = cmd.Flags().GetInt("starting-port")
+
+		nodes, err := app.InitTestnet(opts)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Generated %d-validator testnet %s in %s\n", len(nodes), opts.ChainID, opts.OutputDir)
+		for _, node := range nodes {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s  %s  p2p=%s rpc=%s\n", node.Moniker, node.Address,
+				node.Config.P2P.ListenAddress, node.Config.RPC.ListenAddress)
+		}
+		return nil
+	},
+}
+
 // startCmd represents the start command
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the Underground Empire node",
-	Long: `Start the Underground Empire blockchain node. This command initializes
-the node, connects to the network, and begins participating in consensus.
-
-The node will:
-- Initialize the blockchain state
-- Connect to peer nodes in the network
-- Begin participating in block validation
-- Start the consensus mechanism
-- Enable smart contract execution`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Start the Underground Empire blockchain node from its home directory.
+The node loads the genesis file, replays the blocks stored in <home>/data and
+then produces a new block every block time. Clients reach the node through the
+JSON-RPC server on rpc.listen_address, and subscribe to block, transaction,
+vote, validator set, slash and proposal events over its /websocket endpoint.
+
+Settings are taken from the command-line flags, then from UED_* environment
+variables such as UED_P2P_LISTEN_ADDRESS, then from <home>/config/config.toml,
+then from the defaults.
+
+The node runs until it receives SIGINT or SIGTERM, then finishes the block in
+progress and flushes its storage before exiting. It also exits if the chain
+halts, for example at the height of a software upgrade it does not know.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := app.LoadConfig(homeDir)
+		if err != nil {
+			return err
+		}
+		for _, key := range app.ConfigKeys() {
+			if flag := cmd.Flags().Lookup(configFlagName(key)); flag != nil && flag.Changed {
+				if err := config.Set(key, flag.Value.String()); err != nil {
+					return err
+				}
+			}
+		}
+		if err := config.Validate(); err != nil {
+			return err
+		}
+
 		fmt.Println("Starting Underground Empire node...")
-		fmt.Println("Node initialization complete")
-		fmt.Println("Connecting to network...")
-		fmt.Println("Node is now running and participating in consensus")
+		node := app.NewUEApp(Version)
+		if err := node.Open(homeDir, config); err != nil {
+			return err
+		}
+		if err := node.Start(); err != nil {
+			return err
+		}
+		server := rpc.NewServer(node, config.RPC)
+		if err := server.Start(); err != nil {
+			node.Stop()
+			return err
+		}
 		fmt.Println("Press Ctrl+C to stop the node")
 
-		// TODO: Implement actual node startup logic
-		// This is a placeholder for the first commit
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(signals)
+
+		select {
+		case sig := <-signals:
+			fmt.Printf("Received %s, shutting down\n", sig)
+		case <-node.Halted():
+		}
+
+		if err := server.Stop(); err != nil {
+			fmt.Printf("Failed to stop the RPC server: %v\n", err)
+		}
+		if err := node.Stop(); err != nil {
+			return err
+		}
+		return node.HaltError()
+	},
+}
+
+// configCmd represents the config command group
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and edit the node configuration",
+	Long: `Inspect and edit <home>/config/config.toml. Keys are dotted section
+names such as p2p.listen_address or consensus.timeout_commit.`,
+}
+
+// configShowCmd prints the resolved node configuration
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the configuration with environment overrides applied",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := app.LoadConfig(homeDir)
+		if err != nil {
+			return err
+		}
+		data, err := config.Encode()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "# home = %q\n", homeDir)
+		fmt.Fprint(cmd.OutOrStdout(), string(data))
+		return nil
+	},
+}
+
+// configSetCmd changes one setting in the config file
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting in the config file",
+	Long: `Change a setting in <home>/config/config.toml, creating the file with
+the defaults if there is none. Lists are comma-separated and durations use
+units, such as "500ms" or "5s".`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config := app.DefaultConfig()
+		if _, err := os.Stat(configPath()); err == nil {
+			if config, err = app.ReadConfig(configPath()); err != nil {
+				return err
+			}
+		}
+
+		if err := config.Set(args[0], args[1]); err != nil {
+			return err
+		}
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		if err := config.Save(configPath()); err != nil {
+			return err
+		}
+
+		value, _ := config.Get(args[0])
+		fmt.Fprintf(cmd.OutOrStdout(), "Set %s = %s in %s\n", args[0], value, configPath())
+		return nil
+	},
+}
+
+// configValidateCmd checks the config file and environment overrides
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file and environment overrides",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := app.ReadConfig(configPath()); err != nil {
+			return err
+		}
+		if _, err := app.LoadConfig(homeDir); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Config %s is valid\n", configPath())
+		return nil
 	},
 }
 
@@ -86,45 +450,6 @@ var versionCmd = &cobra.Command{
 	},
 }
 
-// validatorCmd represents the validator command group
-var validatorCmd = &cobra.Command{
-	Use:   "validator",
-	Short: "Manage validator operations",
-	Long: `Manage validator operations for the Underground Empire network.
-
-Validators are responsible for:
-- Proposing and validating blocks
-- Participating in consensus
-- Maintaining network security
-- Earning rewards for honest participation
-
-Minimum requirements:
-- 28,846 UE stake
-- Reliable network connection
-- Consistent uptime`,
-}
-
-// treasuryCmd represents the treasury command group
-var treasuryCmd = &cobra.Command{
-	Use:   "treasury",
-	Short: "Manage treasury operations",
-	Long: `Manage treasury operations including token transfers, balance queries,
-and account management for the Underground Empire network.`,
-}
-
-// governanceCmd represents the governance command group
-var governanceCmd = &cobra.Command{
-	Use:   "governance",
-	Short: "Participate in network governance",
-	Long: `Participate in the governance of the Underground Empire network.
-
-Governance features include:
-- Submitting proposals for protocol upgrades
-- Voting on proposals
-- Parameter change proposals
-- Emergency proposals for critical issues`,
-}
-
 var demoConsensusCmd = &cobra.Command{
 	Use:   "demo-consensus",
 	Short: "Run a simulated in-memory consensus round with multiple validators",
@@ -133,17 +458,19 @@ var demoConsensusCmd = &cobra.Command{
 
 		// 1. Setup validators
 		valMgr := validator.NewValidatorManager()
+		ctx := types.Context{Params: types.DefaultChainParams()}
 		vals := []validator.ValidatorNode{
-			{ID: "val1", StakeAmount: 30000},
-			{ID: "val2", StakeAmount: 30000},
-			{ID: "val3", StakeAmount: 30000},
+			{ID: "val1", StakeAmount: types.NewUEAmount(30000)},
+			{ID: "val2", StakeAmount: types.NewUEAmount(30000)},
+			{ID: "val3", StakeAmount: types.NewUEAmount(30000)},
 		}
 		for _, v := range vals {
-			valMgr.RegisterNode(types.Context{}, v)
+			valMgr.RegisterNode(ctx, v)
 		}
 
 		// 2. Setup consensus engine
 		engine := consensus.NewInMemoryConsensusEngine(valMgr, vals)
+		engine.SetLogger(types.NewLogger(os.Stdout, types.LogLevelDebug).With("Consensus"))
 
 		// 3. Simulate consensus for 200 blocks
 		for i := 0; i < 200; i++ {
@@ -170,6 +497,36 @@ var demoConsensusCmd = &cobra.Command{
 	},
 }
 
+// genesisPath returns the genesis file of the selected node home
+func genesisPath() string {
+	return filepath.Join(homeDir, "config", app.GenesisFile)
+}
+
+// configPath returns the config file of the selected node home
+func configPath() string {
+	return filepath.Join(homeDir, "config", app.ConfigFile)
+}
+
+// configFlagName returns the start flag overriding a config key, such as
+// --p2p.listen-address for p2p.listen_address
+func configFlagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
+
+// defaultNodeHome returns $UED_HOME if set, otherwise ~/.ued, falling back
+// to the working directory
+func defaultNodeHome() string {
+	if home := os.Getenv(app.EnvPrefix + "HOME"); home != "" {
+		return home
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".ued"
+	}
+	return filepath.Join(home, ".ued")
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"undergroundempire/core/types"
+)
+
+func init() {
+	addNodeFlag(treasuryCmd)
+
+	addTxFlags(treasurySendCmd)
+	treasuryBalanceCmd.Flags().StringP("output", "o", "text", "output format (text|json)")
+	treasurySupplyCmd.Flags().StringP("output", "o", "text", "output format (text|json)")
+	treasurySpendsCmd.Flags().StringP("output", "o", "text", "output format (text|json)")
+
+	treasuryCmd.AddCommand(treasurySendCmd)
+	treasuryCmd.AddCommand(treasuryBalanceCmd)
+	treasuryCmd.AddCommand(treasurySupplyCmd)
+	treasuryCmd.AddCommand(treasurySpendsCmd)
+}
+
+// treasuryCmd represents the treasury command group
+var treasuryCmd = &cobra.Command{
+	Use:   "treasury",
+	Short: "Manage treasury operations",
+	Long: `Manage treasury operations including token transfers, balance queries,
+and account management for the Underground Empire network.
+
+Transfers are signed with a key from the keyring and broadcast to the node
+selected with --node; queries read the state of that node.`,
+}
+
+// treasurySendCmd transfers tokens from a keyring key
+var treasurySendCmd = &cobra.Command{
+	Use:   "send <from-key> <to-address> <amount>",
+	Short: "Send tokens from a key's account to an address",
+	Long: `Send tokens from the account of a keyring key. The amount is a single
+coin such as "10UE" or "500aue". The fee is gas * gas price, or set it
+directly with --fees.`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, err := types.NewAddress(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid recipient: %v", err)
+		}
+		amount, err := types.ParseCoinAmount(args[2])
+		if err != nil {
+			return err
+		}
+		if !amount.IsPositive() {
+			return fmt.Errorf("amount must be positive")
+		}
+		return broadcastTx(cmd, args[0], to, amount, nil)
+	},
+}
+
+// treasuryBalanceCmd prints the balances of an account
+var treasuryBalanceCmd = &cobra.Command{
+	Use:   "balance <address>",
+	Short: "Show the balances and next nonce of an account",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address, err := types.NewAddress(args[0])
+		if err != nil {
+			return err
+		}
+		client, err := nodeClient()
+		if err != nil {
+			return err
+		}
+		account, err := client.Account(address)
+		if err != nil {
+			return err
+		}
+
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			return printJSON(cmd.OutOrStdout(), account)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Address: %s\n", account.Address)
+		fmt.Fprintf(cmd.OutOrStdout(), "Nonce: %d\n", account.Nonce)
+		fmt.Fprintln(cmd.OutOrStdout(), "Balances:")
+		if account.Balances.IsZero() {
+			fmt.Fprintf(cmd.OutOrStdout(), "  0%s\n", types.DisplayDenom)
+		}
+		for _, coin := range account.Balances {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", types.FormatCoin(coin))
+		}
+		return nil
+	},
+}
+
+// treasurySupplyCmd prints the token supply
+var treasurySupplyCmd = &cobra.Command{
+	Use:   "supply",
+	Short: "Show the total supply, burned tokens and community pool",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := nodeClient()
+		if err != nil {
+			return err
+		}
+		supply, err := client.Supply()
+		if err != nil {
+			return err
+		}
+
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			return printJSON(cmd.OutOrStdout(), supply)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Total supply:")
+		for _, coin := range supply.Total {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", types.FormatCoin(coin))
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Burned: %s\n", types.FormatCoin(supply.Burned))
+		fmt.Fprintf(cmd.OutOrStdout(), "Community pool: %s\n", types.FormatCoin(supply.CommunityPool))
+		return nil
+	},
+}
+
+// treasurySpendsCmd prints the payouts made from the community pool
+var treasurySpendsCmd = &cobra.Command{
+	Use:   "spends",
+	Short: "Show the community pool spends paid by governance proposals",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := nodeClient()
+		if err != nil {
+			return err
+		}
+		spends, err := client.CommunityPoolSpends()
+		if err != nil {
+			return err
+		}
+
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			return printJSON(cmd.OutOrStdout(), spends)
+		}
+		if len(spends) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No community pool spends found")
+			return nil
+		}
+		for _, spend := range spends {
+			fmt.Fprintf(cmd.OutOrStdout(), "Proposal %d: %s to %s at height %d (%s)\n",
+				spend.ProposalID, types.FormatCoin(spend.Amount), spend.Recipient,
+				spend.Height, spend.Timestamp.Format(time.RFC3339))
+		}
+		return nil
+	},
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"undergroundempire/core/types"
+	app "undergroundempire/node"
+	"undergroundempire/node/rpc"
+)
+
+// nodeAddress is the RPC address selected with --node
+var nodeAddress string
+
+// addNodeFlag adds --node to a command group that talks to a running node
+func addNodeFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&nodeAddress, "node", "", "RPC address of the node (default rpc.listen_address of the node home)")
+}
+
+// addTxFlags adds the flags of a command that signs and broadcasts a
+// transaction
+func addTxFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64("gas", types.DefaultGasLimit, "gas limit of the transaction")
+	cmd.Flags().Uint64("gas-price", types.DefaultGasPrice, "gas price in "+types.NativeDenom)
+	cmd.Flags().String("fees", "", "total fee, such as \"0.001UE\"; sets the gas price to fees / gas")
+	cmd.Flags().StringP("broadcast-mode", "b", rpc.BroadcastSync, "when to return: sync (checked by the node), async (received by the node) or commit (included in a block)")
+	cmd.Flags().StringP("output", "o", "text", "output format (text|json)")
+}
+
+// nodeClient returns a client for the node selected with --node, or for
+// the RPC address configured in the node home
+func nodeClient() (*rpc.Client, error) {
+	address := nodeAddress
+	if address == "" {
+		config, err := app.LoadConfig(homeDir)
+		if err != nil {
+			return nil, err
+		}
+		address = config.RPC.ListenAddress
+	}
+
+	// A node listening on every interface is reached on localhost
+	if host, port, err := net.SplitHostPort(address); err == nil && (host == "" || host == "0.0.0.0" || host == "::") {
+		address = net.JoinHostPort("127.0.0.1", port)
+	}
+	return rpc.NewClient(address), nil
+}
+
+// broadcastMsg signs a transaction carrying a module message and submits
+// it to the node
+func broadcastMsg(cmd *cobra.Command, keyName, module string, msg []byte) error {
+	return broadcastTx(cmd, keyName, types.ModuleAddress(module), types.NewUECoins(types.ZeroInt()), msg)
+}
+
+// broadcastTx signs a transaction with a keyring key, using the account's
+// next nonce and the gas flags of cmd, and submits it to the node
+func broadcastTx(cmd *cobra.Command, keyName string, to types.Address, amount types.CoinAmount, data []byte) error {
+	client, err := nodeClient()
+	if err != nil {
+		return err
+	}
+	kr := openKeyring()
+	info, err := kr.Key(keyName)
+	if err != nil {
+		return err
+	}
+
+	status, err := client.Status()
+	if err != nil {
+		return err
+	}
+	account, err := client.Account(info.Address)
+	if err != nil {
+		return err
+	}
+
+	gas, gasPrice, err := gasFlags(cmd)
+	if err != nil {
+		return err
+	}
+	tx := types.NewTransaction(info.Address, to, amount, gas, gasPrice, data, account.Nonce)
+	tx.Timestamp = time.Now().Unix()
+	if err := tx.Validate(); err != nil {
+		return err
+	}
+
+	passphrase, err := readPassphrase(fmt.Sprintf("Enter the passphrase of %s: ", keyName), false)
+	if err != nil {
+		return err
+	}
+	signature, pubKey, err := kr.Sign(keyName, passphrase, tx.SignBytes(status.ChainID))
+	if err != nil {
+		return err
+	}
+	tx.PubKey = pubKey
+	tx.Signature = signature
+
+	mode, _ := cmd.Flags().GetString("broadcast-mode")
+	result, err := client.BroadcastTx(tx, mode)
+	if err != nil {
+		return err
+	}
+
+	if output, _ := cmd.Flags().GetString("output"); output == "json" {
+		return printJSON(cmd.OutOrStdout(), result)
+	}
+	switch mode {
+	case rpc.BroadcastAsync:
+		fmt.Fprintf(cmd.OutOrStdout(), "Transaction %s sent to %s\n", result.Hash, status.Moniker)
+	case rpc.BroadcastCommit:
+		if result.Error != "" {
+			return fmt.Errorf("transaction %s failed at height %d: %s", result.Hash, result.Height, result.Error)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Transaction %s committed at height %d\n", result.Hash, result.Height)
+	default:
+		fmt.Fprintf(cmd.OutOrStdout(), "Transaction %s accepted into the mempool of %s\n", result.Hash, status.Moniker)
+	}
+	return nil
+}
+
+// gasFlags returns the gas limit and gas price of a transaction. With
+// --fees the gas price is the fee divided by the gas limit, rounded up.
+func gasFlags(cmd *cobra.Command) (uint64, uint64, error) {
+	gas, _ := cmd.Flags().GetUint64("gas")
+	gasPrice, _ := cmd.Flags().GetUint64("gas-price")
+	if gas == 0 {
+		return 0, 0, fmt.Errorf("gas cannot be zero")
+	}
+
+	feesFlag, _ := cmd.Flags().GetString("fees")
+	if feesFlag == "" {
+		return gas, gasPrice, nil
+	}
+	if cmd.Flags().Changed("gas-price") {
+		return 0, 0, fmt.Errorf("set either --fees or --gas-price, not both")
+	}
+
+	fees, err := types.ParseCoinAmount(feesFlag)
+	if err != nil {
+		return 0, 0, err
+	}
+	if fees.Denom != types.NativeDenom || !fees.IsPositive() {
+		return 0, 0, fmt.Errorf("fees must be a positive amount of %s", types.DisplayDenom)
+	}
+
+	gasInt := types.NewIntFromUint64(gas)
+	price := fees.Amount.Add(gasInt).Sub(types.NewInt(1)).Quo(gasInt)
+	if !price.IsUint64() {
+		return 0, 0, fmt.Errorf("fees %s are too high for %d gas", feesFlag, gas)
+	}
+	return gas, price.Uint64(), nil
+}
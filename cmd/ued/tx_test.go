@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"undergroundempire/core/types"
+)
+
+func TestGasFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    map[string]string
+		gas      uint64
+		gasPrice uint64
+		wantErr  bool
+	}{
+		{"defaults", nil, types.DefaultGasLimit, types.DefaultGasPrice, false},
+		{"gas price", map[string]string{"gas": "21000", "gas-price": "7"}, 21000, 7, false},
+		{"fees divide evenly", map[string]string{"gas": "1000", "fees": "5000aue"}, 1000, 5, false},
+		{"fees round up", map[string]string{"gas": "1000", "fees": "5001aue"}, 1000, 6, false},
+		{"fees below one per gas", map[string]string{"gas": "1000", "fees": "1aue"}, 1000, 1, false},
+		{"fees in UE", map[string]string{"gas": "21000", "fees": "0.000021UE"}, 21000, 1000000000, false},
+		{"fees and gas price", map[string]string{"fees": "5000aue", "gas-price": "7"}, 0, 0, true},
+		{"zero gas", map[string]string{"gas": "0"}, 0, 0, true},
+		{"zero fees", map[string]string{"fees": "0aue"}, 0, 0, true},
+		{"fees in another denom", map[string]string{"fees": "5000gold"}, 0, 0, true},
+		{"fees too high", map[string]string{"gas": "1", "fees": "18446744073709551616aue"}, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			addTxFlags(cmd)
+			for name, value := range tt.flags {
+				if err := cmd.Flags().Set(name, value); err != nil {
+					t.Fatalf("set --%s: %v", name, err)
+				}
+			}
+
+			gas, gasPrice, err := gasFlags(cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gasFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gas != tt.gas || gasPrice != tt.gasPrice {
+				t.Errorf("gasFlags() = %d, %d, want %d, %d", gas, gasPrice, tt.gas, tt.gasPrice)
+			}
+		})
+	}
+}
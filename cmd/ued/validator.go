@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/validator"
+)
+
+func init() {
+	addNodeFlag(validatorCmd)
+
+	for _, cmd := range []*cobra.Command{validatorCreateCmd, validatorEditCmd, validatorUnjailCmd} {
+		addTxFlags(cmd)
+	}
+	validatorCreateCmd.Flags().String("commission", "0.10", "commission rate of the validator (0-1)")
+	validatorCreateCmd.Flags().String("description", "", "description of the validator")
+	validatorCreateCmd.Flags().String("website", "", "website of the validator")
+	validatorEditCmd.Flags().String("commission", "", "new commission rate (0-1)")
+	validatorEditCmd.Flags().String("description", "", "new description")
+	validatorEditCmd.Flags().String("website", "", "new website")
+
+	validatorListCmd.Flags().String("status", "", "only list validators with this status (active|candidate|jailed|inactive|slashed)")
+	for _, cmd := range []*cobra.Command{validatorListCmd, validatorShowCmd, slashHistoryCmd} {
+		cmd.Flags().StringP("output", "o", "text", "output format (text|json)")
+	}
+
+	validatorCmd.AddCommand(validatorCreateCmd)
+	validatorCmd.AddCommand(validatorEditCmd)
+	validatorCmd.AddCommand(validatorUnjailCmd)
+	validatorCmd.AddCommand(validatorListCmd)
+	validatorCmd.AddCommand(validatorShowCmd)
+	validatorCmd.AddCommand(slashHistoryCmd)
+}
+
+// validatorCmd represents the validator command group
+var validatorCmd = &cobra.Command{
+	Use:   "validator",
+	Short: "Manage validator operations",
+	Long: `Manage validator operations for the Underground Empire network.
+
+Validators are responsible for:
+- Proposing and validating blocks
+- Participating in consensus
+- Maintaining network security
+- Earning rewards for honest participation
+
+Minimum requirements:
+- 28,846 UE stake
+- Reliable network connection
+- Consistent uptime
+
+Transactions are signed with a key from the keyring and broadcast to the
+node selected with --node; queries read the state of that node.`,
+}
+
+// validatorCreateCmd registers a new validator operated by a key
+var validatorCreateCmd = &cobra.Command{
+	Use:   "create <key-name> <validator-id> <stake>",
+	Short: "Create a validator bonding a self-stake from the key's account",
+	Long: `Create a validator operated by the account of a keyring key. The
+stake, such as "30000UE", is bonded from the account and must meet the
+minimum validator stake.`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stake, err := types.ParseCoinAmount(args[2])
+		if err != nil {
+			return err
+		}
+		if stake.Denom != types.NativeDenom {
+			return fmt.Errorf("stake must be in %s, got %s", types.DisplayDenom, stake.Denom)
+		}
+
+		commissionFlag, _ := cmd.Flags().GetString("commission")
+		commission, err := types.ParseDec(commissionFlag)
+		if err != nil {
+			return fmt.Errorf("invalid commission: %v", err)
+		}
+		description, _ := cmd.Flags().GetString("description")
+		website, _ := cmd.Flags().GetString("website")
+
+		msg, err := types.NewModuleMsg(validator.MsgTypeCreateValidator, validator.MsgCreateValidator{
+			ID:          args[1],
+			Stake:       stake.Amount,
+			Commission:  commission,
+			Description: description,
+			Website:     website,
+		})
+		if err != nil {
+			return err
+		}
+		return broadcastMsg(cmd, args[0], types.ValidatorModuleName, msg)
+	},
+}
+
+// validatorEditCmd changes the commission or description of a validator
+var validatorEditCmd = &cobra.Command{
+	Use:   "edit <key-name> <validator-id>",
+	Short: "Change the commission or description of a validator you operate",
+	Long: `Change the commission, description or website of a validator. Only
+the flags given are changed, and the key must be the validator's operator.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		content := validator.MsgEditValidator{ID: args[1]}
+		if cmd.Flags().Changed("commission") {
+			commissionFlag, _ := cmd.Flags().GetString("commission")
+			commission, err := types.ParseDec(commissionFlag)
+			if err != nil {
+				return fmt.Errorf("invalid commission: %v", err)
+			}
+			content.Commission = &commission
+		}
+		if cmd.Flags().Changed("description") {
+			description, _ := cmd.Flags().GetString("description")
+			content.Description = &description
+		}
+		if cmd.Flags().Changed("website") {
+			website, _ := cmd.Flags().GetString("website")
+			content.Website = &website
+		}
+		if content.Commission == nil && content.Description == nil && content.Website == nil {
+			return fmt.Errorf("nothing to change: set --commission, --description or --website")
+		}
+
+		msg, err := types.NewModuleMsg(validator.MsgTypeEditValidator, content)
+		if err != nil {
+			return err
+		}
+		return broadcastMsg(cmd, args[0], types.ValidatorModuleName, msg)
+	},
+}
+
+// validatorUnjailCmd returns a jailed validator to the bonded set
+var validatorUnjailCmd = &cobra.Command{
+	Use:   "unjail <key-name> <validator-id>",
+	Short: "Unjail a validator you operate once its jail period has passed",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		msg, err := types.NewModuleMsg(validator.MsgTypeUnjail, validator.MsgUnjail{ID: args[1]})
+		if err != nil {
+			return err
+		}
+		return broadcastMsg(cmd, args[0], types.ValidatorModuleName, msg)
+	},
+}
+
+// validatorListCmd lists the validators of the node
+var validatorListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List validators ordered by stake",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, err := parseValidatorStatus(cmd)
+		if err != nil {
+			return err
+		}
+		client, err := nodeClient()
+		if err != nil {
+			return err
+		}
+		validators, err := client.Validators(status)
+		if err != nil {
+			return err
+		}
+
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			return printJSON(cmd.OutOrStdout(), validators)
+		}
+		if len(validators) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No validators found")
+		}
+		for _, val := range validators {
+			printValidator(cmd.OutOrStdout(), val)
+		}
+		return nil
+	},
+}
+
+// validatorShowCmd shows one validator
+var validatorShowCmd = &cobra.Command{
+	Use:   "show <validator-id>",
+	Short: "Show a validator",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := nodeClient()
+		if err != nil {
+			return err
+		}
+		val, err := client.Validator(args[0])
+		if err != nil {
+			return err
+		}
+
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			return printJSON(cmd.OutOrStdout(), val)
+		}
+		printValidator(cmd.OutOrStdout(), val)
+		return nil
+	},
+}
+
+// slashHistoryCmd prints the slash ledger of a validator
+var slashHistoryCmd = &cobra.Command{
+	Use:   "slash-history <validator-id>",
+	Short: "Show the slash history of a validator",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := nodeClient()
+		if err != nil {
+			return err
+		}
+		records, err := client.SlashHistory(args[0])
+		if err != nil {
+			return err
+		}
+
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			return printJSON(cmd.OutOrStdout(), records)
+		}
+
+		if len(records) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No slashes recorded for validator %s\n", args[0])
+			return nil
+		}
+
+		for _, record := range records {
+			fmt.Fprintf(cmd.OutOrStdout(), "Height %d (infraction at %d): %s\n",
+				record.Height, record.InfractionHeight, record.Reason)
+			fmt.Fprintf(cmd.OutOrStdout(), "  Slashed: %s (self-stake %s, delegations %s, fraction %s)\n",
+				types.FormatCoin(types.NewUECoins(record.Amount)),
+				types.FormatCoin(types.NewUECoins(record.SelfStakeAmount)),
+				types.FormatCoin(types.NewUECoins(record.DelegatedAmount)),
+				record.Fraction)
+			if record.EvidenceHash != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "  Evidence: %s\n", record.EvidenceHash)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "  Time: %s\n", record.Timestamp.Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+// printValidator prints a validator in the text output format
+func printValidator(w io.Writer, val validator.ValidatorNode) {
+	fmt.Fprintf(w, "- id: %s\n", val.ID)
+	fmt.Fprintf(w, "  operator: %s\n", val.Address)
+	fmt.Fprintf(w, "  status: %s\n", val.Status)
+	fmt.Fprintf(w, "  stake: %s (self %s, delegated %s)\n",
+		types.FormatCoin(types.NewUECoins(val.TotalStake())),
+		types.FormatCoin(types.NewUECoins(val.StakeAmount)),
+		types.FormatCoin(types.NewUECoins(val.DelegatedAmount)))
+	fmt.Fprintf(w, "  commission: %s\n", val.Commission)
+	if val.Status == validator.ValidatorStatusJailed {
+		fmt.Fprintf(w, "  jailed until: %d\n", val.JailedUntil)
+	}
+	if val.Tombstoned {
+		fmt.Fprintln(w, "  tombstoned: true")
+	}
+	if val.Description != "" {
+		fmt.Fprintf(w, "  description: %s\n", val.Description)
+	}
+	if val.Website != "" {
+		fmt.Fprintf(w, "  website: %s\n", val.Website)
+	}
+}
+
+// parseValidatorStatus returns the validator status selected with --status,
+// empty when every status is listed
+func parseValidatorStatus(cmd *cobra.Command) (validator.ValidatorStatus, error) {
+	status, _ := cmd.Flags().GetString("status")
+	switch validator.ValidatorStatus(status) {
+	case "", validator.ValidatorStatusActive, validator.ValidatorStatusCandidate, validator.ValidatorStatusJailed,
+		validator.ValidatorStatusInactive, validator.ValidatorStatusSlashed:
+		return validator.ValidatorStatus(status), nil
+	default:
+		return "", fmt.Errorf("unknown validator status: %s", status)
+	}
+}
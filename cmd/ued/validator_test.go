@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"undergroundempire/modules/validator"
+)
+
+func TestParseValidatorStatus(t *testing.T) {
+	tests := []struct {
+		status  string
+		want    validator.ValidatorStatus
+		wantErr bool
+	}{
+		{"", "", false},
+		{"active", validator.ValidatorStatusActive, false},
+		{"candidate", validator.ValidatorStatusCandidate, false},
+		{"jailed", validator.ValidatorStatusJailed, false},
+		{"inactive", validator.ValidatorStatusInactive, false},
+		{"slashed", validator.ValidatorStatusSlashed, false},
+		{"bonded", "", true},
+		{"Active", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().String("status", "", "")
+			cmd.Flags().Set("status", tt.status)
+
+			got, err := parseValidatorStatus(cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseValidatorStatus(%q) error = %v, wantErr %v", tt.status, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseValidatorStatus(%q) = %q, want %q", tt.status, got, tt.want)
+			}
+		})
+	}
+}
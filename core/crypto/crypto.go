@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"undergroundempire/core/types"
+)
+
+// PubKey is an ed25519 public key
+type PubKey []byte
+
+// PrivKey is an ed25519 private key
+type PrivKey []byte
+
+// GenerateKey creates a new random private key
+func GenerateKey() (PrivKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+	return PrivKey(priv), nil
+}
+
+// PrivKeyFromSeed derives a private key from a 32-byte seed
+func PrivKeyFromSeed(seed []byte) (PrivKey, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid seed length: expected %d, got %d", ed25519.SeedSize, len(seed))
+	}
+	return PrivKey(ed25519.NewKeyFromSeed(seed)), nil
+}
+
+// PubKey returns the public key of the private key
+func (k PrivKey) PubKey() PubKey {
+	return PubKey(ed25519.PrivateKey(k).Public().(ed25519.PublicKey))
+}
+
+// Sign signs a message
+func (k PrivKey) Sign(msg []byte) []byte {
+	return ed25519.Sign(ed25519.PrivateKey(k), msg)
+}
+
+// Validate checks that the private key is well formed
+func (k PrivKey) Validate() error {
+	if len(k) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid private key length: expected %d, got %d", ed25519.PrivateKeySize, len(k))
+	}
+	return nil
+}
+
+// Address derives the account address of the public key: the first 20
+// bytes of its SHA-256 hash
+func (p PubKey) Address() types.Address {
+	var addr types.Address
+	hash := sha256.Sum256(p)
+	copy(addr[:], hash[:types.AddressLength])
+	return addr
+}
+
+// Verify checks a signature of a message
+func (p PubKey) Verify(msg, signature []byte) bool {
+	if len(p) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(p), msg, signature)
+}
+
+// KeyFile is a private key stored unencrypted on disk, such as a node's
+// validator key
+type KeyFile struct {
+	Address types.Address
+	PubKey  PubKey
+	PrivKey PrivKey
+}
+
+// NewKeyFile wraps a private key for storage
+func NewKeyFile(priv PrivKey) KeyFile {
+	pub := priv.PubKey()
+	return KeyFile{Address: pub.Address(), PubKey: pub, PrivKey: priv}
+}
+
+// LoadKeyFile reads a key file and checks that it is consistent
+func LoadKeyFile(path string) (KeyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return KeyFile{}, fmt.Errorf("failed to read key file: %v", err)
+	}
+
+	var key KeyFile
+	if err := json.Unmarshal(data, &key); err != nil {
+		return KeyFile{}, fmt.Errorf("corrupt key file %s: %v", path, err)
+	}
+	if err := key.PrivKey.Validate(); err != nil {
+		return KeyFile{}, fmt.Errorf("corrupt key file %s: %v", path, err)
+	}
+	if key.PubKey.Address() != key.Address || string(key.PrivKey.PubKey()) != string(key.PubKey) {
+		return KeyFile{}, fmt.Errorf("corrupt key file %s: keys do not match the address", path)
+	}
+	return key, nil
+}
+
+// LoadOrGenerateKeyFile reads a key file, creating it with a new key if it
+// does not exist
+func LoadOrGenerateKeyFile(path string) (KeyFile, error) {
+	if _, err := os.Stat(path); err == nil {
+		return LoadKeyFile(path)
+	}
+
+	priv, err := GenerateKey()
+	if err != nil {
+		return KeyFile{}, err
+	}
+	key := NewKeyFile(priv)
+	if err := key.Save(path); err != nil {
+		return KeyFile{}, err
+	}
+	return key, nil
+}
+
+// Save writes the key file, readable only by its owner
+func (k KeyFile) Save(path string) error {
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create key directory: %v", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	priv, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	pub := priv.PubKey()
+
+	msg := []byte("create validator")
+	signature := priv.Sign(msg)
+	if !pub.Verify(msg, signature) {
+		t.Fatal("expected signature to verify")
+	}
+	if pub.Verify([]byte("create validators"), signature) {
+		t.Fatal("expected signature over a different message to fail")
+	}
+
+	other, _ := GenerateKey()
+	if other.PubKey().Address() == pub.Address() {
+		t.Fatal("expected distinct keys to have distinct addresses")
+	}
+}
+
+func TestKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "validator_key.json")
+
+	created, err := LoadOrGenerateKeyFile(path)
+	if err != nil {
+		t.Fatalf("generate key file: %v", err)
+	}
+	loaded, err := LoadOrGenerateKeyFile(path)
+	if err != nil {
+		t.Fatalf("load key file: %v", err)
+	}
+	if loaded.Address != created.Address || loaded.Address != loaded.PubKey.Address() {
+		t.Fatalf("loaded key %s does not match created key %s", loaded.Address, created.Address)
+	}
+
+	seed := make([]byte, 32)
+	a, _ := PrivKeyFromSeed(seed)
+	b, _ := PrivKeyFromSeed(seed)
+	if a.PubKey().Address() != b.PubKey().Address() {
+		t.Fatal("expected the same seed to derive the same key")
+	}
+}
+
+func TestMnemonicDerivation(t *testing.T) {
+	// SLIP-0010 ed25519 test vector 1, chain m/0H/1H
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if got := hex.EncodeToString(deriveEd25519(seed, []uint32{0, 1})); got != "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2" {
+		t.Fatalf("unexpected derived key %s", got)
+	}
+
+	mnemonic, err := NewMnemonic()
+	if err != nil {
+		t.Fatalf("mnemonic: %v", err)
+	}
+	a, err := PrivKeyFromMnemonic(mnemonic, "")
+	if err != nil {
+		t.Fatalf("derive: %v", err)
+	}
+	b, _ := PrivKeyFromMnemonic("  "+mnemonic+"\n", "")
+	if a.PubKey().Address() != b.PubKey().Address() {
+		t.Fatal("expected the same mnemonic to derive the same key")
+	}
+	if c, _ := PrivKeyFromMnemonic(mnemonic, "extra"); c.PubKey().Address() == a.PubKey().Address() {
+		t.Fatal("expected a BIP39 passphrase to derive a different key")
+	}
+	if _, err := PrivKeyFromMnemonic("not a valid mnemonic", ""); err == nil {
+		t.Fatal("expected an invalid mnemonic to be rejected")
+	}
+}
@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// HDPath is the BIP44 path of account keys derived from a mnemonic:
+// purpose 44, the UE coin type, then the first account. All levels are
+// hardened, as SLIP-0010 requires for ed25519.
+const HDPath = "m/44'/28846'/0'/0'/0'"
+
+// hdIndexes are the levels of HDPath
+var hdIndexes = []uint32{44, 28846, 0, 0, 0}
+
+// NewMnemonic generates a random 24-word BIP39 mnemonic
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate entropy: %v", err)
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// PrivKeyFromMnemonic derives the account key of a BIP39 mnemonic along
+// HDPath, using SLIP-0010 derivation for ed25519
+func PrivKeyFromMnemonic(mnemonic, bip39Passphrase string) (PrivKey, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, bip39Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %v", err)
+	}
+
+	return PrivKeyFromSeed(deriveEd25519(seed, hdIndexes))
+}
+
+// deriveEd25519 derives the ed25519 seed of a hardened SLIP-0010 path
+func deriveEd25519(seed []byte, indexes []uint32) []byte {
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	for _, index := range indexes {
+		data := make([]byte, 37)
+		copy(data[1:33], key)
+		binary.BigEndian.PutUint32(data[33:], index|0x80000000)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+		key, chainCode = sum[:32], sum[32:]
+	}
+	return key
+}
@@ -0,0 +1,410 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+
+	"undergroundempire/core/crypto"
+	"undergroundempire/core/types"
+)
+
+// DirName is the directory of the file keyring inside the node home
+const DirName = "keyring"
+
+// armorType is the PEM block type of exported keys
+const armorType = "UE PRIVATE KEY"
+
+// nameRegex restricts key names to characters that are safe in file names
+var nameRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+// Info describes a stored key without its private part
+type Info struct {
+	Name    string
+	Address types.Address
+	PubKey  crypto.PubKey
+}
+
+// encryptedKey is a private key encrypted with AES-256-GCM under a key
+// derived from a passphrase with scrypt
+type encryptedKey struct {
+	Salt       []byte
+	Nonce      []byte
+	Ciphertext []byte
+	ScryptN    int
+}
+
+// record is how a key is stored by a backend
+type record struct {
+	Info
+	Key encryptedKey
+}
+
+// Backend stores encrypted key records by name
+type Backend interface {
+	Get(name string) ([]byte, error)
+	Set(name string, data []byte) error
+	Delete(name string) error
+	List() ([]string, error)
+}
+
+// Keyring stores named keys encrypted with a passphrase
+type Keyring struct {
+	backend Backend
+	scryptN int
+}
+
+// NewFileKeyring opens a keyring storing one file per key in dir
+func NewFileKeyring(dir string) *Keyring {
+	return &Keyring{backend: &fileBackend{dir: dir}, scryptN: 1 << 15}
+}
+
+// NewMemoryKeyring creates an empty keyring that lives in memory, for
+// tests. It uses a cheap key derivation, so it is not meant for real keys.
+func NewMemoryKeyring() *Keyring {
+	return &Keyring{backend: &memoryBackend{records: make(map[string][]byte)}, scryptN: 1 << 10}
+}
+
+// NewMnemonic creates a key from a new 24-word mnemonic. The mnemonic is
+// returned once and is the only way to recover the key.
+func (k *Keyring) NewMnemonic(name, passphrase string) (Info, string, error) {
+	mnemonic, err := crypto.NewMnemonic()
+	if err != nil {
+		return Info{}, "", err
+	}
+	info, err := k.Recover(name, mnemonic, passphrase)
+	if err != nil {
+		return Info{}, "", err
+	}
+	return info, mnemonic, nil
+}
+
+// Recover stores the key derived from a BIP39 mnemonic
+func (k *Keyring) Recover(name, mnemonic, passphrase string) (Info, error) {
+	priv, err := crypto.PrivKeyFromMnemonic(mnemonic, "")
+	if err != nil {
+		return Info{}, err
+	}
+	return k.store(name, priv, passphrase)
+}
+
+// store encrypts a private key and saves it under a new name
+func (k *Keyring) store(name string, priv crypto.PrivKey, passphrase string) (Info, error) {
+	if !nameRegex.MatchString(name) {
+		return Info{}, fmt.Errorf("invalid key name %q: use up to 64 letters, digits, '.', '_' or '-'", name)
+	}
+	if passphrase == "" {
+		return Info{}, fmt.Errorf("passphrase cannot be empty")
+	}
+	if _, err := k.backend.Get(name); err == nil {
+		return Info{}, fmt.Errorf("key %s already exists", name)
+	}
+
+	pub := priv.PubKey()
+	info := Info{Name: name, Address: pub.Address(), PubKey: pub}
+	encrypted, err := encrypt(priv, passphrase, k.scryptN)
+	if err != nil {
+		return Info{}, err
+	}
+
+	data, err := json.MarshalIndent(record{Info: info, Key: encrypted}, "", "  ")
+	if err != nil {
+		return Info{}, err
+	}
+	if err := k.backend.Set(name, data); err != nil {
+		return Info{}, err
+	}
+	return info, nil
+}
+
+// get loads the record of a key
+func (k *Keyring) get(name string) (record, error) {
+	data, err := k.backend.Get(name)
+	if err != nil {
+		return record{}, err
+	}
+
+	var r record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return record{}, fmt.Errorf("corrupt key %s: %v", name, err)
+	}
+	return r, nil
+}
+
+// unlock decrypts the private key of a key
+func (k *Keyring) unlock(name, passphrase string) (crypto.PrivKey, error) {
+	r, err := k.get(name)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := decrypt(r.Key, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if priv.PubKey().Address() != r.Address {
+		return nil, fmt.Errorf("corrupt key %s: private key does not match the address", name)
+	}
+	return priv, nil
+}
+
+// Key returns the public information of a key
+func (k *Keyring) Key(name string) (Info, error) {
+	r, err := k.get(name)
+	if err != nil {
+		return Info{}, err
+	}
+	return r.Info, nil
+}
+
+// List returns every key, sorted by name
+func (k *Keyring) List() ([]Info, error) {
+	names, err := k.backend.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	infos := make([]Info, 0, len(names))
+	for _, name := range names {
+		info, err := k.Key(name)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Delete removes a key after checking its passphrase
+func (k *Keyring) Delete(name, passphrase string) error {
+	if _, err := k.unlock(name, passphrase); err != nil {
+		return err
+	}
+	return k.backend.Delete(name)
+}
+
+// Sign signs a message with a key
+func (k *Keyring) Sign(name, passphrase string, msg []byte) ([]byte, crypto.PubKey, error) {
+	priv, err := k.unlock(name, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv.Sign(msg), priv.PubKey(), nil
+}
+
+// Export returns a key as an ASCII-armored block, encrypted with a
+// separate export passphrase
+func (k *Keyring) Export(name, passphrase, exportPassphrase string) (string, error) {
+	priv, err := k.unlock(name, passphrase)
+	if err != nil {
+		return "", err
+	}
+	if exportPassphrase == "" {
+		return "", fmt.Errorf("export passphrase cannot be empty")
+	}
+
+	encrypted, err := encrypt(priv, exportPassphrase, k.scryptN)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	block := &pem.Block{
+		Type:    armorType,
+		Headers: map[string]string{"address": priv.PubKey().Address().String()},
+		Bytes:   data,
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// Import stores a key exported with Export under a new name
+func (k *Keyring) Import(name, armor, exportPassphrase, passphrase string) (Info, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(armor)))
+	if block == nil || block.Type != armorType {
+		return Info{}, fmt.Errorf("invalid armored key")
+	}
+
+	var encrypted encryptedKey
+	if err := json.Unmarshal(block.Bytes, &encrypted); err != nil {
+		return Info{}, fmt.Errorf("invalid armored key: %v", err)
+	}
+	priv, err := decrypt(encrypted, exportPassphrase)
+	if err != nil {
+		return Info{}, err
+	}
+	return k.store(name, priv, passphrase)
+}
+
+// encrypt seals a private key with a passphrase
+func encrypt(priv crypto.PrivKey, passphrase string, scryptN int) (encryptedKey, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return encryptedKey{}, fmt.Errorf("failed to generate salt: %v", err)
+	}
+
+	gcm, err := newGCM(passphrase, salt, scryptN)
+	if err != nil {
+		return encryptedKey{}, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return encryptedKey{}, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	return encryptedKey{
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, priv, nil),
+		ScryptN:    scryptN,
+	}, nil
+}
+
+// decrypt opens a private key sealed with encrypt
+func decrypt(key encryptedKey, passphrase string) (crypto.PrivKey, error) {
+	if key.ScryptN < 1<<10 || key.ScryptN > 1<<20 {
+		return nil, fmt.Errorf("unsupported key derivation cost %d", key.ScryptN)
+	}
+	gcm, err := newGCM(passphrase, key.Salt, key.ScryptN)
+	if err != nil {
+		return nil, err
+	}
+	if len(key.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid key nonce")
+	}
+
+	plaintext, err := gcm.Open(nil, key.Nonce, key.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid passphrase")
+	}
+	priv := crypto.PrivKey(plaintext)
+	if err := priv.Validate(); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// newGCM derives an AES-256-GCM cipher from a passphrase
+func newGCM(passphrase string, salt []byte, scryptN int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// fileBackend stores each record in <dir>/<name>.json, readable only by
+// its owner
+type fileBackend struct {
+	dir string
+}
+
+func (b *fileBackend) path(name string) string {
+	return filepath.Join(b.dir, name+".json")
+}
+
+func (b *fileBackend) Get(name string) ([]byte, error) {
+	data, err := os.ReadFile(b.path(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("key %s not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %v", name, err)
+	}
+	return data, nil
+}
+
+func (b *fileBackend) Set(name string, data []byte) error {
+	if err := os.MkdirAll(b.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create keyring directory: %v", err)
+	}
+	return os.WriteFile(b.path(name), append(data, '\n'), 0o600)
+}
+
+func (b *fileBackend) Delete(name string) error {
+	if err := os.Remove(b.path(name)); err != nil {
+		return fmt.Errorf("failed to delete key %s: %v", name, err)
+	}
+	return nil
+}
+
+func (b *fileBackend) List() ([]string, error) {
+	entries, err := os.ReadDir(b.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+	return names, nil
+}
+
+// memoryBackend keeps records in a map
+type memoryBackend struct {
+	mu      sync.RWMutex
+	records map[string][]byte
+}
+
+func (b *memoryBackend) Get(name string) ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	data, exists := b.records[name]
+	if !exists {
+		return nil, fmt.Errorf("key %s not found", name)
+	}
+	return data, nil
+}
+
+func (b *memoryBackend) Set(name string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.records[name] = data
+	return nil
+}
+
+func (b *memoryBackend) Delete(name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.records[name]; !exists {
+		return fmt.Errorf("key %s not found", name)
+	}
+	delete(b.records, name)
+	return nil
+}
+
+func (b *memoryBackend) List() ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	names := make([]string, 0, len(b.records))
+	for name := range b.records {
+		names = append(names, name)
+	}
+	return names, nil
+}
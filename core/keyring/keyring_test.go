@@ -0,0 +1,88 @@
+package keyring
+
+import (
+	"testing"
+
+	"undergroundempire/core/crypto"
+)
+
+func TestKeyringLifecycle(t *testing.T) {
+	kr := NewMemoryKeyring()
+
+	info, mnemonic, err := kr.NewMnemonic("alice", "secret")
+	if err != nil {
+		t.Fatalf("new key: %v", err)
+	}
+	if _, _, err := kr.NewMnemonic("alice", "secret"); err == nil {
+		t.Fatal("expected a duplicate name to be rejected")
+	}
+	if _, _, err := kr.NewMnemonic("../bob", "secret"); err == nil {
+		t.Fatal("expected an unsafe name to be rejected")
+	}
+
+	msg := []byte("transfer")
+	signature, pub, err := kr.Sign("alice", "secret", msg)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if !pub.Verify(msg, signature) || pub.Address() != info.Address {
+		t.Fatal("expected a valid signature from the key's address")
+	}
+	if _, _, err := kr.Sign("alice", "wrong", msg); err == nil {
+		t.Fatal("expected a wrong passphrase to be rejected")
+	}
+
+	// Recovering the mnemonic in another keyring yields the same address
+	recovered, err := NewMemoryKeyring().Recover("alice", mnemonic, "other")
+	if err != nil || recovered.Address != info.Address {
+		t.Fatalf("recover: %v, address %s want %s", err, recovered.Address, info.Address)
+	}
+
+	armor, err := kr.Export("alice", "secret", "export")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := kr.Import("alice2", armor, "wrong", "secret"); err == nil {
+		t.Fatal("expected a wrong export passphrase to be rejected")
+	}
+	imported, err := kr.Import("alice2", armor, "export", "secret2")
+	if err != nil || imported.Address != info.Address {
+		t.Fatalf("import: %v", err)
+	}
+
+	infos, err := kr.List()
+	if err != nil || len(infos) != 2 || infos[0].Name != "alice" || infos[1].Name != "alice2" {
+		t.Fatalf("list: %+v, %v", infos, err)
+	}
+
+	if err := kr.Delete("alice2", "secret"); err == nil {
+		t.Fatal("expected delete with a wrong passphrase to fail")
+	}
+	if err := kr.Delete("alice2", "secret2"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := kr.Key("alice2"); err == nil {
+		t.Fatal("expected the deleted key to be gone")
+	}
+}
+
+func TestFileKeyring(t *testing.T) {
+	dir := t.TempDir()
+	priv, _ := crypto.GenerateKey()
+
+	kr := NewFileKeyring(dir)
+	kr.scryptN = 1 << 10
+	info, err := kr.store("validator", priv, "secret")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	reopened := NewFileKeyring(dir)
+	loaded, err := reopened.Key("validator")
+	if err != nil || loaded.Address != info.Address {
+		t.Fatalf("reopen: %v", err)
+	}
+	if _, _, err := reopened.Sign("validator", "secret", []byte("msg")); err != nil {
+		t.Fatalf("sign after reopen: %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"time"
 )
 
@@ -10,8 +13,21 @@ import (
 type BlockData struct {
 	Height       uint64
 	Hash         string
+	ParentHash   string // Hash of the previous block, empty for the first block
 	Timestamp    time.Time
 	Proposer     string
 	Transactions []Transaction
 	Consensus    ConsensusData
 }
+
+// CalculateHash calculates the block hash over its header and the hashes
+// of its transactions. Consensus votes are not part of the hash.
+func (b BlockData) CalculateHash() string {
+	data := fmt.Sprintf("%d%s%d%s", b.Height, b.ParentHash, b.Timestamp.UnixNano(), b.Proposer)
+	for _, tx := range b.Transactions {
+		data += tx.CalculateHash()
+	}
+
+	hash := sha256.Sum256([]byte(data))
+	return "0x" + hex.EncodeToString(hash[:])
+}
@@ -1,7 +1,30 @@
 package types
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestBlockData_Struct(t *testing.T) {
 	// Placeholder test for BlockData struct
 }
+
+func TestBlockData_CalculateHash(t *testing.T) {
+	block := BlockData{Height: 2, ParentHash: "0x01", Timestamp: time.Unix(100, 0), Proposer: "val1"}
+	hash := block.CalculateHash()
+	if hash != block.CalculateHash() {
+		t.Fatal("expected the block hash to be deterministic")
+	}
+
+	block.Transactions = []Transaction{{From: Address{1}, To: Address{2}, Nonce: 1}}
+	if block.CalculateHash() == hash {
+		t.Fatal("expected the transactions to change the block hash")
+	}
+
+	block.Consensus.Finalized = true
+	withTx := block.CalculateHash()
+	block.Consensus.Finalized = false
+	if block.CalculateHash() != withTx {
+		t.Fatal("expected consensus data to be excluded from the block hash")
+	}
+}
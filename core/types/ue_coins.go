@@ -0,0 +1,187 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// denomRegex matches valid denominations: a letter followed by 1-127
+// letters, digits or the separators / : . _ -
+var denomRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9/:._-]{1,127}$`)
+
+// ValidateDenom checks that a denomination is well formed
+func ValidateDenom(denom string) error {
+	if !denomRegex.MatchString(denom) {
+		return fmt.Errorf("invalid denom: %q", denom)
+	}
+	return nil
+}
+
+// Coins is a multi-denomination amount. A normalized Coins value is sorted
+// by denomination, holds at most one entry per denomination and contains
+// no zero amounts; the empty value is zero.
+type Coins []CoinAmount
+
+// NewCoins creates a normalized Coins value, merging duplicate
+// denominations and dropping zero amounts
+func NewCoins(coins ...CoinAmount) (Coins, error) {
+	result := Coins{}
+	for _, coin := range coins {
+		if err := ValidateDenom(coin.Denom); err != nil {
+			return nil, err
+		}
+		if coin.Amount.IsNegative() {
+			return nil, fmt.Errorf("coin %s cannot have a negative amount", coin.Denom)
+		}
+		var err error
+		if result, err = result.SafeAdd(Coins{coin}); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Validate checks that the coins are normalized with valid denominations
+func (c Coins) Validate() error {
+	for i, coin := range c {
+		if err := ValidateDenom(coin.Denom); err != nil {
+			return err
+		}
+		if !coin.IsPositive() {
+			return fmt.Errorf("coin %s must have a positive amount", coin.Denom)
+		}
+		if i > 0 && c[i-1].Denom >= coin.Denom {
+			return fmt.Errorf("coins are not sorted or contain duplicate denom %s", coin.Denom)
+		}
+	}
+	return nil
+}
+
+// String returns the coins as a comma-separated list, such as "100aue,5stake"
+func (c Coins) String() string {
+	parts := make([]string, len(c))
+	for i, coin := range c {
+		parts[i] = coin.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// AmountOf returns the amount of a denomination
+func (c Coins) AmountOf(denom string) Int {
+	i := sort.Search(len(c), func(i int) bool { return c[i].Denom >= denom })
+	if i < len(c) && c[i].Denom == denom {
+		return c[i].Amount
+	}
+	return ZeroInt()
+}
+
+// IsZero reports whether the coins hold nothing
+func (c Coins) IsZero() bool {
+	for _, coin := range c {
+		if !coin.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns the sum of two Coins values. It panics on overflow, which a
+// valid supply can never reach; use SafeAdd for untrusted amounts.
+func (c Coins) Add(other Coins) Coins {
+	sum, err := c.SafeAdd(other)
+	if err != nil {
+		panic(err)
+	}
+	return sum
+}
+
+// SafeAdd returns the sum of two Coins values, failing on overflow
+func (c Coins) SafeAdd(other Coins) (Coins, error) {
+	sum := Coins{}
+	i, j := 0, 0
+
+	for i < len(c) || j < len(other) {
+		switch {
+		case j == len(other) || (i < len(c) && c[i].Denom < other[j].Denom):
+			sum = sum.appendNonZero(c[i])
+			i++
+		case i == len(c) || other[j].Denom < c[i].Denom:
+			sum = sum.appendNonZero(other[j])
+			j++
+		default:
+			coin, err := c[i].Add(other[j])
+			if err != nil {
+				return nil, err
+			}
+			sum = sum.appendNonZero(coin)
+			i++
+			j++
+		}
+	}
+
+	return sum, nil
+}
+
+// Sub returns the difference of two Coins values, failing if any
+// denomination would become negative
+func (c Coins) Sub(other Coins) (Coins, error) {
+	if !c.IsAllGTE(other) {
+		return nil, fmt.Errorf("insufficient balance: %s < %s", c, other)
+	}
+
+	difference := Coins{}
+	for _, coin := range c {
+		coin.Amount = coin.Amount.Sub(other.AmountOf(coin.Denom))
+		difference = difference.appendNonZero(coin)
+	}
+	return difference, nil
+}
+
+// IsAllGTE reports whether c holds at least as much of every denomination as other
+func (c Coins) IsAllGTE(other Coins) bool {
+	for _, coin := range other {
+		if c.AmountOf(coin.Denom).LT(coin.Amount) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize drops zero amounts from an otherwise normalized value
+func (c Coins) normalize() Coins {
+	result := Coins{}
+	for _, coin := range c {
+		result = result.appendNonZero(coin)
+	}
+	return result
+}
+
+// appendNonZero appends a coin unless its amount is zero
+func (c Coins) appendNonZero(coin CoinAmount) Coins {
+	if coin.IsZero() {
+		return c
+	}
+	return append(c, coin)
+}
+
+// ParseCoins parses a comma-separated list of coin amounts such as
+// "100aue,5stake". It is the inverse of Coins.String.
+func ParseCoins(s string) (Coins, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Coins{}, nil
+	}
+
+	var coins []CoinAmount
+	for _, part := range strings.Split(s, ",") {
+		coin, err := ParseCoinAmount(part)
+		if err != nil {
+			return nil, err
+		}
+		coins = append(coins, coin)
+	}
+
+	return NewCoins(coins...)
+}
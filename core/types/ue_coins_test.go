@@ -0,0 +1,105 @@
+package types
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseCoins_RoundTrip(t *testing.T) {
+	coins, err := ParseCoins("5stake, 100aue,20stake")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if got := coins.String(); got != "100aue,25stake" {
+		t.Fatalf("String() = %q, want %q", got, "100aue,25stake")
+	}
+	if err := coins.Validate(); err != nil {
+		t.Fatalf("parsed coins are not normalized: %v", err)
+	}
+
+	reparsed, err := ParseCoins(coins.String())
+	if err != nil || reparsed.String() != coins.String() {
+		t.Fatalf("round trip gave %q (err %v)", reparsed, err)
+	}
+
+	// Display units convert to the base denomination
+	display, err := ParseCoins("100UE,5stake")
+	if err != nil || display.String() != "100000000000000000000aue,5stake" {
+		t.Fatalf("display units gave %q (err %v)", display, err)
+	}
+
+	for _, invalid := range []string{"100", "ue", "100u", "10 0aue", "100aue,", "5$take", "1.5aue", "1.5stake"} {
+		if _, err := ParseCoins(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestCoins_Arithmetic(t *testing.T) {
+	a, _ := ParseCoins("100aue,5stake")
+	b, _ := ParseCoins("50aue,7atom")
+
+	sum := a.Add(b)
+	if sum.String() != "7atom,150aue,5stake" {
+		t.Fatalf("sum = %s", sum)
+	}
+
+	if !sum.IsAllGTE(a) || a.IsAllGTE(b) {
+		t.Fatal("unexpected IsAllGTE result")
+	}
+
+	difference, err := sum.Sub(a)
+	if err != nil || difference.String() != "7atom,50aue" {
+		t.Fatalf("difference = %s (err %v)", difference, err)
+	}
+	if _, err := a.Sub(b); err == nil {
+		t.Fatal("expected subtraction below zero to fail")
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), maxBitLen)
+	max := Coins{NewUECoins(NewIntFromBigInt(limit.Sub(limit, big.NewInt(1))))}
+	if _, err := max.SafeAdd(Coins{NewUECoins(NewInt(1))}); err == nil {
+		t.Fatal("expected overflow to be reported")
+	}
+	if _, err := max[0].Mul(2); err == nil {
+		t.Fatal("expected multiplication overflow to be reported")
+	}
+}
+
+func TestParseCoinAmount_DisplayUnits(t *testing.T) {
+	coin, err := ParseCoinAmount("1.5UE")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if coin.Denom != NativeDenom || coin.Amount.String() != "1500000000000000000" {
+		t.Fatalf("1.5UE parsed as %s", coin)
+	}
+	if got := FormatCoin(coin); got != "1.5UE" {
+		t.Fatalf("FormatCoin = %q, want 1.5UE", got)
+	}
+
+	if _, err := ParseCoinAmount("0.0000000000000000001UE"); err == nil {
+		t.Fatal("expected an amount below one base unit to be rejected")
+	}
+	if _, err := ParseCoinAmount("100ue"); err == nil || !strings.Contains(err.Error(), NativeDenom) {
+		t.Fatalf("expected the legacy ue denomination to be rejected with a migration hint, got %v", err)
+	}
+}
+
+func TestDec(t *testing.T) {
+	rate := MustParseDec("0.05")
+	if !rate.Equal(NewDecWithPrec(5, 2)) {
+		t.Fatalf("0.05 parsed as %s", rate)
+	}
+	if got := rate.MulInt(NewInt(1234)).TruncateInt(); !got.Equal(NewInt(61)) {
+		t.Fatalf("5%% of 1234 = %s, want 61", got)
+	}
+	if got := OneDec().Quo(NewDec(3)).String(); got != "0.333333333333333333" {
+		t.Fatalf("1/3 = %s", got)
+	}
+	if _, err := ParseDec("1.2.3"); err == nil {
+		t.Fatal("expected malformed decimal to be rejected")
+	}
+}
@@ -11,6 +11,7 @@ type Context struct {
 	Height    uint64
 	Timestamp time.Time
 	ChainID   string
+	Params    ChainParams // Live chain parameters
 }
 
 // UEError represents Underground Empire specific errors
@@ -23,10 +24,13 @@ func (e UEError) Error() string {
 	return e.Message
 }
 
-// UE-specific constants
+// UE-specific constants. The validator, consensus, network and gas values
+// are genesis defaults; the live values are held in the on-chain parameter
+// store and reach modules through Context.Params.
 const (
 	// Validator Requirements
-	MinValidatorStake = 28846 // UE coins required to become validator
+	MinValidatorStake    = 28846 // UE coins required to become validator
+	DefaultMaxValidators = 100   // Size of the active validator set
 
 	// Consensus Parameters
 	ConsensusThreshold = 67 // Percentage for block finalization
@@ -37,10 +41,22 @@ const (
 
 	// Gas Parameters
 	DefaultGasLimit = 200000
-	DefaultGasPrice = 1000000000 // 1 gwei in wei
+	DefaultGasPrice = 1000000000 // 1 gwei in aue
 
 	// Chain Parameters
 	DefaultChainID = "underground-empire-1"
+	NativeDenom    = "aue" // Base unit, 10^-18 UE
+	DisplayDenom   = "UE"
+	NativeExponent = 18
+
+	// Module Accounts
+	FeeCollectorName       = "fee_collector"  // Receives block rewards before distribution
+	DistributionModuleName = "distribution"   // Holds allocated rewards until withdrawn
+	CommunityPoolName      = "community_pool" // Receives the community tax
+	BondedPoolName         = "bonded_pool"    // Holds staked tokens
+	GovernanceModuleName   = "governance"     // Holds proposal deposits
+	TokenFactoryModuleName = "tokenfactory"   // Receives token factory messages
+	ValidatorModuleName    = "validator"      // Receives validator messages
 
 	// Address Parameters
 	AddressLength = 20 // bytes
@@ -64,6 +80,7 @@ func (c Context) WithHeight(height uint64) Context {
 		Height:    height,
 		Timestamp: c.Timestamp,
 		ChainID:   c.ChainID,
+		Params:    c.Params,
 	}
 }
 
@@ -74,43 +91,17 @@ func (c Context) WithTimestamp(timestamp time.Time) Context {
 		Height:    c.Height,
 		Timestamp: timestamp,
 		ChainID:   c.ChainID,
+		Params:    c.Params,
 	}
 }
 
-// IsValidatorEligible checks if a stake amount meets validator requirements
-func IsValidatorEligible(stakeAmount uint64) bool {
-	return stakeAmount >= MinValidatorStake
-}
-
-// CalculateValidatorReward calculates reward for a validator based on stake
-func CalculateValidatorReward(stakeAmount uint64, totalStake uint64, blockReward uint64) uint64 {
-	if totalStake == 0 {
-		return 0
-	}
-	return (stakeAmount * blockReward) / totalStake
-}
-
-// IsConsensusReached checks if consensus threshold is met
-func IsConsensusReached(votes uint64, totalValidators uint64) bool {
-	if totalValidators == 0 {
-		return false
+// WithChainParams returns a new context with updated chain parameters
+func (c Context) WithChainParams(params ChainParams) Context {
+	return Context{
+		Context:   c.Context,
+		Height:    c.Height,
+		Timestamp: c.Timestamp,
+		ChainID:   c.ChainID,
+		Params:    params,
 	}
-	percentage := (votes * 100) / totalValidators
-	return percentage >= ConsensusThreshold
-}
-
-// CalculateEpochNumber calculates the current epoch number
-func CalculateEpochNumber(blockHeight uint64) uint64 {
-	return blockHeight / EpochDuration
-}
-
-// IsEpochBoundary checks if the current block is at epoch boundary
-func IsEpochBoundary(blockHeight uint64) bool {
-	return blockHeight%EpochDuration == 0
-}
-
-// CalculateNextEpochHeight calculates the height of the next epoch
-func CalculateNextEpochHeight(currentHeight uint64) uint64 {
-	currentEpoch := CalculateEpochNumber(currentHeight)
-	return (currentEpoch + 1) * EpochDuration
 }
@@ -0,0 +1,186 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// DenomUnit is a unit a denomination can be displayed in
+type DenomUnit struct {
+	Denom    string
+	Exponent uint32 // Power of ten relating the unit to the base denomination
+	Aliases  []string
+}
+
+// Metadata describes a denomination and its units, mapping display units
+// such as "UE" to the base denomination balances are kept in
+type Metadata struct {
+	Description string
+	Base        string
+	Display     string
+	Name        string
+	Symbol      string
+	DenomUnits  []DenomUnit // Ordered by exponent, starting with the base at exponent 0
+}
+
+// NativeMetadata returns the metadata of the native UE token
+func NativeMetadata() Metadata {
+	return Metadata{
+		Description: "The native staking and fee token of Underground Empire",
+		Base:        NativeDenom,
+		Display:     DisplayDenom,
+		Name:        "Underground Empire",
+		Symbol:      DisplayDenom,
+		DenomUnits: []DenomUnit{
+			{Denom: NativeDenom, Exponent: 0},
+			{Denom: DisplayDenom, Exponent: NativeExponent},
+		},
+	}
+}
+
+// Validate checks that the metadata is well formed
+func (m Metadata) Validate() error {
+	if err := ValidateDenom(m.Base); err != nil {
+		return err
+	}
+	if len(m.DenomUnits) == 0 || m.DenomUnits[0].Denom != m.Base || m.DenomUnits[0].Exponent != 0 {
+		return fmt.Errorf("the first denom unit of %s must be the base denom with exponent 0", m.Base)
+	}
+
+	seen := make(map[string]bool)
+	hasDisplay := false
+	for i, unit := range m.DenomUnits {
+		if i > 0 && unit.Exponent <= m.DenomUnits[i-1].Exponent {
+			return fmt.Errorf("denom units of %s must be ordered by increasing exponent", m.Base)
+		}
+		if unit.Exponent > DecPrecision {
+			return fmt.Errorf("denom unit %s exponent %d exceeds %d", unit.Denom, unit.Exponent, DecPrecision)
+		}
+		for _, name := range append([]string{unit.Denom}, unit.Aliases...) {
+			if err := ValidateDenom(name); err != nil {
+				return err
+			}
+			if seen[name] {
+				return fmt.Errorf("duplicate denom unit %s", name)
+			}
+			seen[name] = true
+		}
+		if unit.Denom == m.Display {
+			hasDisplay = true
+		}
+	}
+
+	if !hasDisplay {
+		return fmt.Errorf("display denom %s is not one of the denom units", m.Display)
+	}
+	return nil
+}
+
+// unit returns the unit with the given denom or alias
+func (m Metadata) unit(denom string) (DenomUnit, bool) {
+	for _, unit := range m.DenomUnits {
+		if unit.Denom == denom {
+			return unit, true
+		}
+		for _, alias := range unit.Aliases {
+			if alias == denom {
+				return unit, true
+			}
+		}
+	}
+	return DenomUnit{}, false
+}
+
+// ToBaseCoin converts an amount in one of the units to the base denomination.
+// The result must be a whole number of base units.
+func (m Metadata) ToBaseCoin(amount Dec, denom string) (CoinAmount, error) {
+	unit, exists := m.unit(denom)
+	if !exists {
+		return CoinAmount{}, fmt.Errorf("%s is not a unit of %s", denom, m.Base)
+	}
+	if amount.IsNegative() {
+		return CoinAmount{}, fmt.Errorf("amount cannot be negative")
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(unit.Exponent)), nil)
+	base := amount.MulInt(NewIntFromBigInt(scale))
+	if !base.IsInteger() {
+		return CoinAmount{}, fmt.Errorf("%s%s is smaller than one %s", amount, denom, m.Base)
+	}
+
+	return NewCoinAmount(base.TruncateInt(), m.Base), nil
+}
+
+// FormatDisplay formats a base amount in the display unit, such as "1.5UE"
+func (m Metadata) FormatDisplay(coin CoinAmount) string {
+	unit, exists := m.unit(m.Display)
+	if !exists || coin.Denom != m.Base {
+		return coin.String()
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(unit.Exponent)), nil)
+	whole, fraction := new(big.Int).QuoRem(coin.Amount.bigInt(), scale, new(big.Int))
+	if fraction.Sign() == 0 {
+		return whole.String() + m.Display
+	}
+
+	digits := fmt.Sprintf("%0*s", unit.Exponent, fraction.String())
+	for digits[len(digits)-1] == '0' {
+		digits = digits[:len(digits)-1]
+	}
+	return whole.String() + "." + digits + m.Display
+}
+
+// denomRegistry maps every unit and alias to its metadata
+var denomRegistry = struct {
+	sync.RWMutex
+	units map[string]Metadata
+}{units: make(map[string]Metadata)}
+
+func init() {
+	if err := RegisterDenomMetadata(NativeMetadata()); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterDenomMetadata makes a denomination's units known to ParseCoinAmount
+func RegisterDenomMetadata(metadata Metadata) error {
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+
+	denomRegistry.Lock()
+	defer denomRegistry.Unlock()
+
+	for _, unit := range metadata.DenomUnits {
+		for _, name := range append([]string{unit.Denom}, unit.Aliases...) {
+			if existing, exists := denomRegistry.units[name]; exists && existing.Base != metadata.Base {
+				return fmt.Errorf("denom unit %s is already registered for %s", name, existing.Base)
+			}
+		}
+	}
+	for _, unit := range metadata.DenomUnits {
+		for _, name := range append([]string{unit.Denom}, unit.Aliases...) {
+			denomRegistry.units[name] = metadata
+		}
+	}
+	return nil
+}
+
+// GetDenomMetadata returns the metadata of the denomination a unit belongs to
+func GetDenomMetadata(unit string) (Metadata, bool) {
+	denomRegistry.RLock()
+	defer denomRegistry.RUnlock()
+
+	metadata, exists := denomRegistry.units[unit]
+	return metadata, exists
+}
+
+// FormatCoin formats a coin in its display unit if its metadata is registered
+func FormatCoin(coin CoinAmount) string {
+	if metadata, exists := GetDenomMetadata(coin.Denom); exists {
+		return metadata.FormatDisplay(coin)
+	}
+	return coin.String()
+}
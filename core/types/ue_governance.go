@@ -0,0 +1,70 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// GovernanceProposal represents a governance proposal
+type GovernanceProposal struct {
+	ID          uint64
+	Title       string
+	Description string
+	Proposer    Address
+	Type        ProposalType
+	Content     json.RawMessage // Type-specific payload, executed when the proposal passes
+	Expedited   bool            // Shorter voting period and higher threshold
+	Status      ProposalStatus
+	Votes       map[VoteOption]Int // Stake-weighted tally
+	CreatedAt   time.Time
+	EndTime     time.Time
+
+	// Lifecycle, measured in blocks
+	SubmitHeight      uint64
+	DepositEndHeight  uint64
+	VotingStartHeight uint64
+	VotingEndHeight   uint64
+	TotalDeposit      Int
+}
+
+// ProposalType identifies what a proposal does when it passes
+type ProposalType string
+
+const (
+	ProposalTypeText            ProposalType = "text"
+	ProposalTypeParameterChange ProposalType = "parameter_change"
+	ProposalTypeSoftwareUpgrade ProposalType = "software_upgrade"
+	ProposalTypeCommunitySpend  ProposalType = "community_pool_spend"
+	ProposalTypeCircuitBreaker  ProposalType = "circuit_breaker"
+)
+
+// ProposalStatus represents the status of a proposal
+type ProposalStatus string
+
+const (
+	ProposalStatusDeposit  ProposalStatus = "deposit_period"
+	ProposalStatusActive   ProposalStatus = "active" // Voting period
+	ProposalStatusPassed   ProposalStatus = "passed"
+	ProposalStatusRejected ProposalStatus = "rejected"
+	ProposalStatusExecuted ProposalStatus = "executed"
+	ProposalStatusFailed   ProposalStatus = "failed" // Passed but execution failed
+)
+
+// VoteOption represents a voting option
+type VoteOption string
+
+const (
+	VoteOptionYes        VoteOption = "yes"
+	VoteOptionNo         VoteOption = "no"
+	VoteOptionAbstain    VoteOption = "abstain"
+	VoteOptionNoWithVeto VoteOption = "no_with_veto"
+)
+
+// ParseVoteOption converts a string into a vote option
+func ParseVoteOption(s string) (VoteOption, error) {
+	switch option := VoteOption(s); option {
+	case VoteOptionYes, VoteOptionNo, VoteOptionAbstain, VoteOptionNoWithVeto:
+		return option, nil
+	}
+	return "", UEError{Code: "invalid_vote_option", Message: "invalid vote option: " + s}
+}
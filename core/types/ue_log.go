@@ -0,0 +1,81 @@
+package types
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// LogLevel is the minimum severity of the messages a Logger writes
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelError
+)
+
+// ParseLogLevel parses a log level name: debug, info or error
+func ParseLogLevel(level string) (LogLevel, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return LogLevelDebug, nil
+	case "info", "":
+		return LogLevelInfo, nil
+	case "error":
+		return LogLevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q, expected debug, info or error", level)
+	}
+}
+
+// Logger writes the messages of the node and its modules, each on its own
+// line prefixed with the name of the module reporting it. The zero Logger
+// discards everything, so modules log nothing until they are given one.
+type Logger struct {
+	out    io.Writer
+	mu     *sync.Mutex // Shared by the loggers writing to out
+	level  LogLevel
+	module string
+}
+
+// NewLogger creates a logger writing the messages at or above level to out
+func NewLogger(out io.Writer, level LogLevel) Logger {
+	return Logger{out: out, mu: &sync.Mutex{}, level: level}
+}
+
+// With returns a logger prefixing its messages with a module name
+func (l Logger) With(module string) Logger {
+	l.module = module
+	return l
+}
+
+// Debugf writes a message useful when following the node step by step
+func (l Logger) Debugf(format string, args ...interface{}) {
+	l.log(LogLevelDebug, format, args...)
+}
+
+// Infof writes a message about the normal operation of the node
+func (l Logger) Infof(format string, args ...interface{}) {
+	l.log(LogLevelInfo, format, args...)
+}
+
+// Errorf writes a message about a failure
+func (l Logger) Errorf(format string, args ...interface{}) {
+	l.log(LogLevelError, format, args...)
+}
+
+func (l Logger) log(level LogLevel, format string, args ...interface{}) {
+	if l.out == nil || level < l.level {
+		return
+	}
+
+	line := fmt.Sprintf(format, args...) + "\n"
+	if l.module != "" {
+		line = "[" + l.module + "] " + line
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.out, line)
+}
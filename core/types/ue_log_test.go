@@ -0,0 +1,25 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger(&out, LogLevelInfo)
+
+	logger.With("Upgrade").Infof("Applied upgrade %s", "v2")
+	logger.With("Consensus").Debugf("PreVote by %s", "node0")
+	logger.Errorf("halted")
+	if got, want := out.String(), "[Upgrade] Applied upgrade v2\nhalted\n"; got != want {
+		t.Fatalf("logged %q, want %q", got, want)
+	}
+
+	// The zero logger discards messages
+	Logger{}.Errorf("dropped")
+
+	if _, err := ParseLogLevel("verbose"); err == nil {
+		t.Fatal("expected an unknown log level to be rejected")
+	}
+}
@@ -0,0 +1,431 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// maxBitLen caps the size of amounts. Results that need more bits are
+// treated as overflows, so a bug cannot mint unbounded supply.
+const maxBitLen = 256
+
+// Int is an arbitrary-precision integer used for token amounts and stakes.
+// Values are immutable and the zero value is zero.
+type Int struct {
+	i *big.Int
+}
+
+// NewInt creates an Int from an int64
+func NewInt(n int64) Int {
+	return Int{big.NewInt(n)}
+}
+
+// NewIntFromUint64 creates an Int from a uint64
+func NewIntFromUint64(n uint64) Int {
+	return Int{new(big.Int).SetUint64(n)}
+}
+
+// NewIntFromBigInt creates an Int from a copy of a big.Int
+func NewIntFromBigInt(b *big.Int) Int {
+	if b == nil {
+		return ZeroInt()
+	}
+	return Int{new(big.Int).Set(b)}
+}
+
+// ZeroInt returns zero
+func ZeroInt() Int {
+	return Int{new(big.Int)}
+}
+
+// ParseInt parses a base-10 integer
+func ParseInt(s string) (Int, error) {
+	b, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+	if !ok {
+		return Int{}, fmt.Errorf("invalid integer: %q", s)
+	}
+	if b.BitLen() > maxBitLen {
+		return Int{}, fmt.Errorf("integer out of range: %s", s)
+	}
+	return Int{b}, nil
+}
+
+// bigInt returns the underlying value, treating the zero value as zero
+func (i Int) bigInt() *big.Int {
+	if i.i == nil {
+		return new(big.Int)
+	}
+	return i.i
+}
+
+// BigInt returns a copy of the value as a big.Int
+func (i Int) BigInt() *big.Int {
+	return new(big.Int).Set(i.bigInt())
+}
+
+// IsZero reports whether the value is zero
+func (i Int) IsZero() bool {
+	return i.bigInt().Sign() == 0
+}
+
+// IsPositive reports whether the value is greater than zero
+func (i Int) IsPositive() bool {
+	return i.bigInt().Sign() > 0
+}
+
+// IsNegative reports whether the value is below zero
+func (i Int) IsNegative() bool {
+	return i.bigInt().Sign() < 0
+}
+
+// Equal reports whether two values are equal
+func (i Int) Equal(other Int) bool {
+	return i.bigInt().Cmp(other.bigInt()) == 0
+}
+
+// GT reports whether i > other
+func (i Int) GT(other Int) bool {
+	return i.bigInt().Cmp(other.bigInt()) > 0
+}
+
+// GTE reports whether i >= other
+func (i Int) GTE(other Int) bool {
+	return i.bigInt().Cmp(other.bigInt()) >= 0
+}
+
+// LT reports whether i < other
+func (i Int) LT(other Int) bool {
+	return i.bigInt().Cmp(other.bigInt()) < 0
+}
+
+// LTE reports whether i <= other
+func (i Int) LTE(other Int) bool {
+	return i.bigInt().Cmp(other.bigInt()) <= 0
+}
+
+// SafeAdd returns i + other, failing on overflow
+func (i Int) SafeAdd(other Int) (Int, error) {
+	return checked(new(big.Int).Add(i.bigInt(), other.bigInt()))
+}
+
+// SafeSub returns i - other, failing on overflow
+func (i Int) SafeSub(other Int) (Int, error) {
+	return checked(new(big.Int).Sub(i.bigInt(), other.bigInt()))
+}
+
+// SafeMul returns i * other, failing on overflow
+func (i Int) SafeMul(other Int) (Int, error) {
+	return checked(new(big.Int).Mul(i.bigInt(), other.bigInt()))
+}
+
+// Add returns i + other. It panics on overflow.
+func (i Int) Add(other Int) Int {
+	return mustChecked(i.SafeAdd(other))
+}
+
+// Sub returns i - other. It panics on overflow.
+func (i Int) Sub(other Int) Int {
+	return mustChecked(i.SafeSub(other))
+}
+
+// Mul returns i * other. It panics on overflow.
+func (i Int) Mul(other Int) Int {
+	return mustChecked(i.SafeMul(other))
+}
+
+// Quo returns i / other truncated toward zero. It panics if other is zero.
+func (i Int) Quo(other Int) Int {
+	if other.IsZero() {
+		panic("division by zero")
+	}
+	return Int{new(big.Int).Quo(i.bigInt(), other.bigInt())}
+}
+
+// AddRaw returns i + n
+func (i Int) AddRaw(n uint64) Int {
+	return i.Add(NewIntFromUint64(n))
+}
+
+// MulRaw returns i * n
+func (i Int) MulRaw(n uint64) Int {
+	return i.Mul(NewIntFromUint64(n))
+}
+
+// QuoRaw returns i / n truncated toward zero
+func (i Int) QuoRaw(n uint64) Int {
+	return i.Quo(NewIntFromUint64(n))
+}
+
+// MulDiv returns i * numerator / denominator, truncated, without
+// intermediate overflow. It returns zero if denominator is zero.
+func (i Int) MulDiv(numerator, denominator Int) Int {
+	if denominator.IsZero() {
+		return ZeroInt()
+	}
+	result := new(big.Int).Mul(i.bigInt(), numerator.bigInt())
+	return mustChecked(checked(result.Quo(result, denominator.bigInt())))
+}
+
+// IsUint64 reports whether the value fits in a uint64
+func (i Int) IsUint64() bool {
+	return i.bigInt().IsUint64()
+}
+
+// Uint64 returns the value as a uint64. It panics if the value does not fit.
+func (i Int) Uint64() uint64 {
+	if !i.IsUint64() {
+		panic(fmt.Sprintf("%s does not fit in uint64", i))
+	}
+	return i.bigInt().Uint64()
+}
+
+// String returns the base-10 representation
+func (i Int) String() string {
+	return i.bigInt().String()
+}
+
+// MarshalJSON encodes the value as a string so it survives JSON number limits
+func (i Int) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON decodes a quoted or bare integer
+func (i *Int) UnmarshalJSON(data []byte) error {
+	parsed, err := ParseInt(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*i = parsed
+	return nil
+}
+
+// MinInt returns the smaller of two values
+func MinInt(a, b Int) Int {
+	if a.LT(b) {
+		return a
+	}
+	return b
+}
+
+// checked rejects results wider than maxBitLen
+func checked(b *big.Int) (Int, error) {
+	if b.BitLen() > maxBitLen {
+		return Int{}, fmt.Errorf("integer overflow")
+	}
+	return Int{b}, nil
+}
+
+// mustChecked panics on an overflow error
+func mustChecked(i Int, err error) Int {
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+// DecPrecision is the number of decimal places kept by Dec
+const DecPrecision = 18
+
+// decOne is 10^DecPrecision, the scaled representation of 1
+var decOne = new(big.Int).Exp(big.NewInt(10), big.NewInt(DecPrecision), nil)
+
+// Dec is a fixed-point decimal with 18 decimal places used for rates such
+// as commission and inflation. Values are immutable and the zero value is zero.
+type Dec struct {
+	i *big.Int // Value scaled by 10^18
+}
+
+// NewDec creates a Dec from an integer
+func NewDec(n int64) Dec {
+	return Dec{new(big.Int).Mul(big.NewInt(n), decOne)}
+}
+
+// NewDecWithPrec creates the Dec n * 10^-prec, for example NewDecWithPrec(5, 2) is 0.05
+func NewDecWithPrec(n int64, prec int64) Dec {
+	if prec < 0 || prec > DecPrecision {
+		panic(fmt.Sprintf("invalid precision %d", prec))
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(DecPrecision-prec), nil)
+	return Dec{new(big.Int).Mul(big.NewInt(n), scale)}
+}
+
+// NewDecFromInt converts an Int to a Dec
+func NewDecFromInt(i Int) Dec {
+	return Dec{new(big.Int).Mul(i.bigInt(), decOne)}
+}
+
+// ZeroDec returns zero
+func ZeroDec() Dec {
+	return Dec{new(big.Int)}
+}
+
+// OneDec returns one
+func OneDec() Dec {
+	return NewDec(1)
+}
+
+// ParseDec parses a decimal such as "0.05" or "12"
+func ParseDec(s string) (Dec, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Dec{}, fmt.Errorf("invalid decimal: empty string")
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if whole == "" || strings.ContainsAny(whole+fraction, "+-") {
+		return Dec{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+	if len(fraction) > DecPrecision {
+		return Dec{}, fmt.Errorf("decimal %q has more than %d decimal places", s, DecPrecision)
+	}
+
+	combined := whole + fraction + strings.Repeat("0", DecPrecision-len(fraction))
+	value, ok := new(big.Int).SetString(combined, 10)
+	if !ok {
+		return Dec{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+	if value.BitLen() > maxBitLen+60 {
+		return Dec{}, fmt.Errorf("decimal out of range: %s", s)
+	}
+	if negative {
+		value.Neg(value)
+	}
+	return Dec{value}, nil
+}
+
+// MustParseDec parses a decimal, panicking on invalid input. It is meant
+// for constants.
+func MustParseDec(s string) Dec {
+	d, err := ParseDec(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// bigInt returns the scaled value, treating the zero value as zero
+func (d Dec) bigInt() *big.Int {
+	if d.i == nil {
+		return new(big.Int)
+	}
+	return d.i
+}
+
+// IsZero reports whether the value is zero
+func (d Dec) IsZero() bool {
+	return d.bigInt().Sign() == 0
+}
+
+// IsPositive reports whether the value is greater than zero
+func (d Dec) IsPositive() bool {
+	return d.bigInt().Sign() > 0
+}
+
+// IsNegative reports whether the value is below zero
+func (d Dec) IsNegative() bool {
+	return d.bigInt().Sign() < 0
+}
+
+// Equal reports whether two values are equal
+func (d Dec) Equal(other Dec) bool {
+	return d.bigInt().Cmp(other.bigInt()) == 0
+}
+
+// GT reports whether d > other
+func (d Dec) GT(other Dec) bool {
+	return d.bigInt().Cmp(other.bigInt()) > 0
+}
+
+// GTE reports whether d >= other
+func (d Dec) GTE(other Dec) bool {
+	return d.bigInt().Cmp(other.bigInt()) >= 0
+}
+
+// LT reports whether d < other
+func (d Dec) LT(other Dec) bool {
+	return d.bigInt().Cmp(other.bigInt()) < 0
+}
+
+// LTE reports whether d <= other
+func (d Dec) LTE(other Dec) bool {
+	return d.bigInt().Cmp(other.bigInt()) <= 0
+}
+
+// Add returns d + other
+func (d Dec) Add(other Dec) Dec {
+	return Dec{new(big.Int).Add(d.bigInt(), other.bigInt())}
+}
+
+// Sub returns d - other
+func (d Dec) Sub(other Dec) Dec {
+	return Dec{new(big.Int).Sub(d.bigInt(), other.bigInt())}
+}
+
+// Mul returns d * other, truncated to 18 decimal places
+func (d Dec) Mul(other Dec) Dec {
+	result := new(big.Int).Mul(d.bigInt(), other.bigInt())
+	return Dec{result.Quo(result, decOne)}
+}
+
+// Quo returns d / other, truncated to 18 decimal places. It panics if other is zero.
+func (d Dec) Quo(other Dec) Dec {
+	if other.IsZero() {
+		panic("division by zero")
+	}
+	result := new(big.Int).Mul(d.bigInt(), decOne)
+	return Dec{result.Quo(result, other.bigInt())}
+}
+
+// MulInt returns d * i
+func (d Dec) MulInt(i Int) Dec {
+	return Dec{new(big.Int).Mul(d.bigInt(), i.bigInt())}
+}
+
+// QuoInt returns d / i, truncated. It panics if i is zero.
+func (d Dec) QuoInt(i Int) Dec {
+	if i.IsZero() {
+		panic("division by zero")
+	}
+	return Dec{new(big.Int).Quo(d.bigInt(), i.bigInt())}
+}
+
+// TruncateInt drops the fractional part
+func (d Dec) TruncateInt() Int {
+	return Int{new(big.Int).Quo(d.bigInt(), decOne)}
+}
+
+// IsInteger reports whether the value has no fractional part
+func (d Dec) IsInteger() bool {
+	return new(big.Int).Rem(d.bigInt(), decOne).Sign() == 0
+}
+
+// String returns the value with 18 decimal places, such as "0.050000000000000000"
+func (d Dec) String() string {
+	abs := new(big.Int).Abs(d.bigInt())
+	whole, fraction := new(big.Int).QuoRem(abs, decOne, new(big.Int))
+
+	sign := ""
+	if d.IsNegative() {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%s.%0*s", sign, whole, DecPrecision, fraction.String())
+}
+
+// MarshalJSON encodes the value as a string
+func (d Dec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a quoted or bare decimal
+func (d *Dec) UnmarshalJSON(data []byte) error {
+	parsed, err := ParseDec(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
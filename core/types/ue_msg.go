@@ -0,0 +1,65 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ModuleMsg is a message to a module, carried in the Data of a transaction
+// sent to the module account. Content holds the type-specific payload.
+type ModuleMsg struct {
+	Type    string
+	Content json.RawMessage
+}
+
+// NewModuleMsg encodes a message for the Data of a transaction
+func NewModuleMsg(msgType string, content interface{}) ([]byte, error) {
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(ModuleMsg{Type: msgType, Content: raw})
+}
+
+// Decode unmarshals the payload of a message
+func (m ModuleMsg) Decode(content interface{}) error {
+	if err := json.Unmarshal(m.Content, content); err != nil {
+		return fmt.Errorf("invalid %s message: %v", m.Type, err)
+	}
+	return nil
+}
+
+// FeeKeeper collects the fees of transactions
+type FeeKeeper interface {
+	DeductFees(ctx Context, tx Transaction) (Coins, error)
+}
+
+// MsgHandler executes a module message sent by an account
+type MsgHandler func(ctx Context, sender Address, msg ModuleMsg) error
+
+// ExecuteModuleTx executes the message in the Data of a transaction sent
+// to the account of a module. The fee is collected once the message
+// decodes and is kept even if the handler fails.
+func ExecuteModuleTx(ctx Context, tx Transaction, module string, fees FeeKeeper, handle MsgHandler) error {
+	if err := tx.Validate(); err != nil {
+		return err
+	}
+
+	if tx.To != ModuleAddress(module) {
+		return fmt.Errorf("transaction is not addressed to the %s module", module)
+	}
+	if !tx.Amount.IsZero() {
+		return fmt.Errorf("%s transactions cannot carry an amount", module)
+	}
+
+	var msg ModuleMsg
+	if err := json.Unmarshal(tx.Data, &msg); err != nil {
+		return fmt.Errorf("invalid %s message: %v", module, err)
+	}
+
+	if _, err := fees.DeductFees(ctx, tx); err != nil {
+		return err
+	}
+
+	return handle(ctx, tx.From, msg)
+}
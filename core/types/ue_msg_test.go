@@ -0,0 +1,55 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+// countingFees records the transactions it charged
+type countingFees struct {
+	charged int
+}
+
+func (f *countingFees) DeductFees(ctx Context, tx Transaction) (Coins, error) {
+	f.charged++
+	return Coins{}, nil
+}
+
+func TestExecuteModuleTx(t *testing.T) {
+	data, err := NewModuleMsg("ping", struct{ N int }{N: 7})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	to := ModuleAddress(ValidatorModuleName)
+	fees := &countingFees{}
+
+	var got struct{ N int }
+	handle := func(ctx Context, sender Address, msg ModuleMsg) error {
+		if err := msg.Decode(&got); err != nil {
+			return err
+		}
+		return errors.New("handler failed")
+	}
+
+	tx := NewTransaction(Address{1}, to, NewUECoins(ZeroInt()), 1, 1, data, 0)
+	if err := ExecuteModuleTx(Context{}, tx, ValidatorModuleName, fees, handle); err == nil || got.N != 7 {
+		t.Fatalf("expected the decoded message to reach the handler, got %+v, %v", got, err)
+	}
+	if fees.charged != 1 {
+		t.Fatalf("fee charged %d times, want once even though the handler failed", fees.charged)
+	}
+
+	rejected := []Transaction{
+		NewTransaction(Address{1}, ModuleAddress(GovernanceModuleName), NewUECoins(ZeroInt()), 1, 1, data, 0),
+		NewTransaction(Address{1}, to, NewUECoins(NewInt(5)), 1, 1, data, 0),
+		NewTransaction(Address{1}, to, NewUECoins(ZeroInt()), 1, 1, []byte("not json"), 0),
+	}
+	for _, tx := range rejected {
+		if err := ExecuteModuleTx(Context{}, tx, ValidatorModuleName, fees, handle); err == nil {
+			t.Errorf("expected %+v to be rejected", tx)
+		}
+	}
+	if fees.charged != 1 {
+		t.Fatalf("rejected transactions were charged a fee")
+	}
+}
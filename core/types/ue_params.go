@@ -0,0 +1,76 @@
+package types
+
+import (
+	"fmt"
+)
+
+// ChainParams holds the consensus and economic parameters that used to be
+// compile-time constants. They live in the on-chain parameter store and can
+// be changed by governance without a hard fork.
+type ChainParams struct {
+	MinValidatorStake  Int    // Base units required to become validator
+	ConsensusThreshold uint64 // Percentage for block finalization
+	BlockTime          uint64 // Seconds per block
+	EpochDuration      uint64 // Blocks per epoch
+	DefaultGasLimit    uint64
+}
+
+// DefaultChainParams returns the genesis chain parameters
+func DefaultChainParams() ChainParams {
+	return ChainParams{
+		MinValidatorStake:  NewUEAmount(MinValidatorStake),
+		ConsensusThreshold: ConsensusThreshold,
+		BlockTime:          BlockTime,
+		EpochDuration:      EpochDuration,
+		DefaultGasLimit:    DefaultGasLimit,
+	}
+}
+
+// Validate checks that the parameters are well formed
+func (p ChainParams) Validate() error {
+	if !p.MinValidatorStake.IsPositive() {
+		return fmt.Errorf("min validator stake must be positive")
+	}
+	// BFT safety needs more than two thirds of the votes
+	if p.ConsensusThreshold < 67 || p.ConsensusThreshold > 100 {
+		return fmt.Errorf("consensus threshold must be between 67 and 100 percent, got %d", p.ConsensusThreshold)
+	}
+	if p.BlockTime == 0 {
+		return fmt.Errorf("block time must be positive")
+	}
+	if p.EpochDuration == 0 {
+		return fmt.Errorf("epoch duration must be positive")
+	}
+	if p.DefaultGasLimit == 0 {
+		return fmt.Errorf("default gas limit must be positive")
+	}
+	return nil
+}
+
+// IsValidatorEligible checks if a stake amount meets validator requirements
+func (p ChainParams) IsValidatorEligible(stakeAmount Int) bool {
+	return stakeAmount.GTE(p.MinValidatorStake)
+}
+
+// IsConsensusReached checks if consensus threshold is met
+func (p ChainParams) IsConsensusReached(votes uint64, totalValidators uint64) bool {
+	if totalValidators == 0 {
+		return false
+	}
+	return (votes*100)/totalValidators >= p.ConsensusThreshold
+}
+
+// CalculateEpochNumber calculates the epoch number of a block height
+func (p ChainParams) CalculateEpochNumber(blockHeight uint64) uint64 {
+	return blockHeight / p.EpochDuration
+}
+
+// IsEpochBoundary checks if a block is at an epoch boundary
+func (p ChainParams) IsEpochBoundary(blockHeight uint64) bool {
+	return blockHeight%p.EpochDuration == 0
+}
+
+// CalculateNextEpochHeight calculates the height of the next epoch
+func (p ChainParams) CalculateNextEpochHeight(currentHeight uint64) uint64 {
+	return (p.CalculateEpochNumber(currentHeight) + 1) * p.EpochDuration
+}
@@ -4,16 +4,16 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"strconv"
+	"regexp"
 	"strings"
 )
 
 // Address represents an Underground Empire address
 type Address [20]byte
 
-// CoinAmount represents an amount of UE coins
+// CoinAmount represents an amount of coins in a base denomination
 type CoinAmount struct {
-	Amount uint64
+	Amount Int
 	Denom  string
 }
 
@@ -27,6 +27,7 @@ type Transaction struct {
 	GasPrice  uint64
 	Data      []byte
 	Nonce     uint64
+	PubKey    []byte // Public key of the sender, whose address must be From
 	Signature []byte
 	Timestamp int64
 }
@@ -54,11 +55,35 @@ func NewAddress(hexStr string) (Address, error) {
 	return addr, nil
 }
 
+// ModuleAddress derives the deterministic address of a module account.
+// Module accounts have no private key and can only be moved by module logic.
+func ModuleAddress(name string) Address {
+	var addr Address
+	hash := sha256.Sum256([]byte("module/" + name))
+	copy(addr[:], hash[:AddressLength])
+	return addr
+}
+
 // String returns the hex string representation of the address
 func (a Address) String() string {
 	return "0x" + hex.EncodeToString(a[:])
 }
 
+// MarshalText encodes the address as a 0x-prefixed hex string
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText decodes a hex address
+func (a *Address) UnmarshalText(text []byte) error {
+	addr, err := NewAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = addr
+	return nil
+}
+
 // Bytes returns the byte representation of the address
 func (b Address) Bytes() []byte {
 	return b[:]
@@ -75,21 +100,26 @@ func (a Address) IsZero() bool {
 }
 
 // NewCoinAmount creates a new coin amount
-func NewCoinAmount(amount uint64, denom string) CoinAmount {
+func NewCoinAmount(amount Int, denom string) CoinAmount {
 	return CoinAmount{
 		Amount: amount,
 		Denom:  denom,
 	}
 }
 
-// NewUECoins creates a new UE coin amount
-func NewUECoins(amount uint64) CoinAmount {
-	return NewCoinAmount(amount, "ue")
+// NewUECoins creates a new coin amount in the native base denomination
+func NewUECoins(amount Int) CoinAmount {
+	return NewCoinAmount(amount, NativeDenom)
+}
+
+// NewUEAmount converts whole UE to base units
+func NewUEAmount(ue uint64) Int {
+	return NewIntFromUint64(ue).Mul(NewIntFromBigInt(decOne))
 }
 
 // String returns the string representation of the coin amount
 func (c CoinAmount) String() string {
-	return fmt.Sprintf("%d%s", c.Amount, c.Denom)
+	return c.Amount.String() + c.Denom
 }
 
 // Add adds two coin amounts (must be same denomination)
@@ -98,8 +128,13 @@ func (c CoinAmount) Add(other CoinAmount) (CoinAmount, error) {
 		return CoinAmount{}, fmt.Errorf("cannot add different denominations: %s and %s", c.Denom, other.Denom)
 	}
 
+	amount, err := c.Amount.SafeAdd(other.Amount)
+	if err != nil {
+		return CoinAmount{}, fmt.Errorf("amount overflow: %s + %s", c.String(), other.String())
+	}
+
 	return CoinAmount{
-		Amount: c.Amount + other.Amount,
+		Amount: amount,
 		Denom:  c.Denom,
 	}, nil
 }
@@ -110,43 +145,48 @@ func (c CoinAmount) Sub(other CoinAmount) (CoinAmount, error) {
 		return CoinAmount{}, fmt.Errorf("cannot subtract different denominations: %s and %s", c.Denom, other.Denom)
 	}
 
-	if c.Amount < other.Amount {
+	if c.Amount.LT(other.Amount) {
 		return CoinAmount{}, fmt.Errorf("insufficient balance: %s < %s", c.String(), other.String())
 	}
 
 	return CoinAmount{
-		Amount: c.Amount - other.Amount,
+		Amount: c.Amount.Sub(other.Amount),
 		Denom:  c.Denom,
 	}, nil
 }
 
 // Mul multiplies the coin amount by a factor
-func (c CoinAmount) Mul(factor uint64) CoinAmount {
+func (c CoinAmount) Mul(factor uint64) (CoinAmount, error) {
+	amount, err := c.Amount.SafeMul(NewIntFromUint64(factor))
+	if err != nil {
+		return CoinAmount{}, fmt.Errorf("amount overflow: %s * %d", c.String(), factor)
+	}
+
 	return CoinAmount{
-		Amount: c.Amount * factor,
+		Amount: amount,
 		Denom:  c.Denom,
-	}
+	}, nil
 }
 
 // Div divides the coin amount by a factor
 func (c CoinAmount) Div(factor uint64) CoinAmount {
 	if factor == 0 {
-		return CoinAmount{Amount: 0, Denom: c.Denom}
+		return CoinAmount{Amount: ZeroInt(), Denom: c.Denom}
 	}
 	return CoinAmount{
-		Amount: c.Amount / factor,
+		Amount: c.Amount.QuoRaw(factor),
 		Denom:  c.Denom,
 	}
 }
 
 // IsZero checks if the coin amount is zero
 func (c CoinAmount) IsZero() bool {
-	return c.Amount == 0
+	return c.Amount.IsZero()
 }
 
 // IsPositive checks if the coin amount is positive
 func (c CoinAmount) IsPositive() bool {
-	return c.Amount > 0
+	return c.Amount.IsPositive()
 }
 
 // NewTransaction creates a new transaction
@@ -184,6 +224,12 @@ func (tx Transaction) CalculateHash() string {
 	return "0x" + hex.EncodeToString(hash[:])
 }
 
+// SignBytes returns the bytes the sender signs: the transaction hash bound
+// to a chain ID, so a signed transaction cannot be replayed on another chain
+func (tx Transaction) SignBytes(chainID string) []byte {
+	return []byte(chainID + "/" + tx.CalculateHash())
+}
+
 // Validate validates the transaction
 func (tx Transaction) Validate() error {
 	// Check addresses
@@ -195,9 +241,10 @@ func (tx Transaction) Validate() error {
 		return fmt.Errorf("to address cannot be zero")
 	}
 
-	// Check amount
-	if tx.Amount.IsZero() {
-		return fmt.Errorf("amount cannot be zero")
+	// Check amount. Transactions carrying a module message in Data may
+	// move nothing.
+	if tx.Amount.Amount.IsNegative() || (len(tx.Data) == 0 && !tx.Amount.IsPositive()) {
+		return fmt.Errorf("amount must be positive")
 	}
 
 	// Check gas
@@ -213,32 +260,45 @@ func (tx Transaction) Validate() error {
 	return nil
 }
 
-// CalculateGasCost calculates the total gas cost
-func (tx Transaction) CalculateGasCost() uint64 {
-	return tx.Gas * tx.GasPrice
+// Fee returns the fee the transaction pays for its gas
+func (tx Transaction) Fee() Coins {
+	cost := NewIntFromUint64(tx.Gas).Mul(NewIntFromUint64(tx.GasPrice))
+	return Coins{NewUECoins(cost)}.normalize()
 }
 
-// ParseCoinAmount parses a coin amount from string
+// legacyNativeDenom was the native denomination before amounts moved to
+// 18 decimals. Amounts in it are rejected rather than silently scaled to
+// the UE display unit.
+const legacyNativeDenom = "ue"
+
+// coinRegex splits a coin into its amount, which may be a decimal in a
+// display unit, and its denomination
+var coinRegex = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z][a-zA-Z0-9/:._-]{1,127})$`)
+
+// ParseCoinAmount parses a coin amount such as "100aue" or "1.5UE".
+// Amounts in a display unit with registered metadata are converted to the
+// base denomination; base amounts must be whole numbers.
 func ParseCoinAmount(s string) (CoinAmount, error) {
-	// Find the denomination (last non-digit character)
-	var denom string
-	var amountStr string
-
-	for i := len(s) - 1; i >= 0; i-- {
-		if s[i] >= '0' && s[i] <= '9' {
-			amountStr = s[:i+1]
-			denom = s[i+1:]
-			break
-		}
+	matches := coinRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if matches == nil {
+		return CoinAmount{}, fmt.Errorf("invalid coin amount format: %s", s)
+	}
+	amountStr, denom := matches[1], matches[2]
+	if denom == legacyNativeDenom {
+		return CoinAmount{}, fmt.Errorf("the %s denomination was replaced: write base amounts in %s (10^-%d %s) or whole tokens in %s", legacyNativeDenom, NativeDenom, NativeExponent, DisplayDenom, DisplayDenom)
 	}
 
-	if amountStr == "" {
-		return CoinAmount{}, fmt.Errorf("invalid coin amount format: %s", s)
+	if metadata, exists := GetDenomMetadata(denom); exists {
+		amount, err := ParseDec(amountStr)
+		if err != nil {
+			return CoinAmount{}, fmt.Errorf("invalid amount: %v", err)
+		}
+		return metadata.ToBaseCoin(amount, denom)
 	}
 
-	amount, err := strconv.ParseUint(amountStr, 10, 64)
+	amount, err := ParseInt(amountStr)
 	if err != nil {
-		return CoinAmount{}, fmt.Errorf("invalid amount: %v", err)
+		return CoinAmount{}, fmt.Errorf("invalid amount: %s is not a whole number of %s", amountStr, denom)
 	}
 
 	return CoinAmount{
@@ -2,9 +2,17 @@ module undergroundempire
 
 go 1.20
 
-require github.com/spf13/cobra v1.8.0
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/gorilla/websocket v1.5.3
+	github.com/spf13/cobra v1.8.0
+	github.com/tyler-smith/go-bip39 v1.1.0
+	golang.org/x/crypto v0.17.0
+	golang.org/x/term v0.15.0
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.15.0 // indirect
 )
@@ -0,0 +1,122 @@
+package circuit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"undergroundempire/core/types"
+)
+
+// CircuitBreakerProposal is the content of a circuit breaker proposal
+type CircuitBreakerProposal struct {
+	Pause  []string // Routes to pause
+	Resume []string // Routes to resume
+}
+
+// CircuitBreaker pauses and resumes module routes, such as transfers, so
+// governance can contain an exploit without halting the chain. Modules
+// check their route before handling user transactions.
+type CircuitBreaker struct {
+	routes map[string]bool
+	paused map[string]uint64 // Paused routes and the height they were paused at
+	logger types.Logger
+}
+
+// NewCircuitBreaker creates a circuit breaker for the given routes
+func NewCircuitBreaker(routes ...string) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		routes: make(map[string]bool),
+		paused: make(map[string]uint64),
+	}
+	for _, route := range routes {
+		cb.RegisterRoute(route)
+	}
+	return cb
+}
+
+// SetLogger sets the logger route changes are reported to
+func (cb *CircuitBreaker) SetLogger(logger types.Logger) {
+	cb.logger = logger
+}
+
+// RegisterRoute makes a route pausable
+func (cb *CircuitBreaker) RegisterRoute(route string) {
+	cb.routes[route] = true
+}
+
+// IsPaused reports whether a route is paused
+func (cb *CircuitBreaker) IsPaused(route string) bool {
+	_, paused := cb.paused[route]
+	return paused
+}
+
+// Pause stops a route from accepting transactions
+func (cb *CircuitBreaker) Pause(ctx types.Context, route string) error {
+	if !cb.routes[route] {
+		return fmt.Errorf("unknown route: %s", route)
+	}
+	if cb.IsPaused(route) {
+		return fmt.Errorf("route %s is already paused", route)
+	}
+
+	cb.paused[route] = ctx.Height
+	return nil
+}
+
+// Resume lets a paused route accept transactions again
+func (cb *CircuitBreaker) Resume(ctx types.Context, route string) error {
+	if !cb.IsPaused(route) {
+		return fmt.Errorf("route %s is not paused", route)
+	}
+
+	delete(cb.paused, route)
+	return nil
+}
+
+// GetPausedRoutes returns the paused routes in order
+func (cb *CircuitBreaker) GetPausedRoutes() []string {
+	routes := make([]string, 0, len(cb.paused))
+	for route := range cb.paused {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// HandleCircuitBreakerProposal applies a passed circuit breaker proposal.
+// It is registered as the governance proposal handler.
+func (cb *CircuitBreaker) HandleCircuitBreakerProposal(ctx types.Context, proposal types.GovernanceProposal) error {
+	var content CircuitBreakerProposal
+	if err := json.Unmarshal(proposal.Content, &content); err != nil {
+		return fmt.Errorf("invalid circuit breaker proposal: %v", err)
+	}
+
+	if len(content.Pause) == 0 && len(content.Resume) == 0 {
+		return fmt.Errorf("circuit breaker proposal has no routes")
+	}
+
+	// Check every route first so the proposal applies all or nothing
+	for _, route := range content.Pause {
+		if !cb.routes[route] {
+			return fmt.Errorf("unknown route: %s", route)
+		}
+	}
+	for _, route := range content.Resume {
+		if !cb.IsPaused(route) {
+			return fmt.Errorf("route %s is not paused", route)
+		}
+	}
+
+	for _, route := range content.Resume {
+		cb.Resume(ctx, route)
+	}
+	for _, route := range content.Pause {
+		if !cb.IsPaused(route) {
+			cb.paused[route] = ctx.Height
+		}
+	}
+
+	cb.logger.Infof("Proposal %d paused %v and resumed %v", proposal.ID, content.Pause, content.Resume)
+	return nil
+}
@@ -0,0 +1,61 @@
+package circuit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/treasury"
+)
+
+func TestCircuitBreakerPausesTransfers(t *testing.T) {
+	ctx := types.Context{Height: 3}
+	cb := NewCircuitBreaker(treasury.TransfersRoute)
+	tm := treasury.NewTreasuryManager()
+	tm.SetCircuitKeeper(cb)
+
+	from := types.Address{1}
+	to := types.Address{2}
+	tm.MintTokens(ctx, from, types.NewUECoins(types.NewInt(100000)))
+	tx := types.NewTransaction(from, to, types.NewUECoins(types.NewInt(1000)), 21000, 1, nil, 1)
+
+	content, _ := json.Marshal(CircuitBreakerProposal{Pause: []string{treasury.TransfersRoute}})
+	proposal := types.GovernanceProposal{ID: 1, Type: types.ProposalTypeCircuitBreaker, Content: content}
+	if err := cb.HandleCircuitBreakerProposal(ctx, proposal); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+
+	if err := tm.ExecuteTransaction(ctx, tx); err == nil {
+		t.Fatal("expected transfer to be rejected while paused")
+	}
+	if got := tm.GetBalance(ctx, from).Amount; !got.Equal(types.NewInt(100000)) {
+		t.Fatalf("paused transfer must not charge a fee, balance %s", got)
+	}
+
+	content, _ = json.Marshal(CircuitBreakerProposal{Resume: []string{treasury.TransfersRoute}})
+	proposal = types.GovernanceProposal{ID: 2, Type: types.ProposalTypeCircuitBreaker, Content: content}
+	if err := cb.HandleCircuitBreakerProposal(ctx, proposal); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if err := tm.ExecuteTransaction(ctx, tx); err != nil {
+		t.Fatalf("transfer after resume: %v", err)
+	}
+}
+
+func TestCircuitBreakerRejectsUnknownRoutes(t *testing.T) {
+	ctx := types.Context{}
+	cb := NewCircuitBreaker("transfers", "staking")
+
+	content, _ := json.Marshal(CircuitBreakerProposal{Pause: []string{"staking", "governance"}})
+	proposal := types.GovernanceProposal{ID: 1, Type: types.ProposalTypeCircuitBreaker, Content: content}
+	if err := cb.HandleCircuitBreakerProposal(ctx, proposal); err == nil {
+		t.Fatal("expected unknown route to be rejected")
+	}
+	if len(cb.GetPausedRoutes()) != 0 {
+		t.Fatal("a rejected proposal must not pause any route")
+	}
+
+	if err := cb.Resume(ctx, "transfers"); err == nil {
+		t.Fatal("expected resuming an active route to fail")
+	}
+}
@@ -18,11 +18,20 @@ type ConsensusEngine interface {
 	GetState() *ConsensusState
 }
 
+// Mempool supplies the pending transactions included in proposed blocks
+type Mempool interface {
+	ReapTxs(max int) []types.Transaction
+}
+
+// MaxBlockTxs is the maximum number of transactions in a block
+const MaxBlockTxs = 1000
+
 // ConsensusState holds the current state of consensus
 // (wraps types.ConsensusState for in-memory tracking)
 type ConsensusState struct {
 	CurrentHeight   uint64
 	CurrentRound    uint64
+	LastBlockHash   string
 	Validators      []validator.ValidatorNode
 	ProposerIndex   int
 	Votes           []types.Vote
@@ -33,8 +42,12 @@ type ConsensusState struct {
 // InMemoryConsensusEngine is a simple, single-node consensus engine for demo/testing
 // (no networking, no persistence)
 type InMemoryConsensusEngine struct {
-	state      *ConsensusState
-	valManager *validator.ValidatorManager
+	state       *ConsensusState
+	valManager  *validator.ValidatorManager
+	chainParams types.ChainParams
+	mempool     Mempool
+	retain      int
+	logger      types.Logger
 }
 
 // NewInMemoryConsensusEngine creates a new consensus engine
@@ -48,7 +61,62 @@ func NewInMemoryConsensusEngine(valManager *validator.ValidatorManager, initialV
 			Votes:           []types.Vote{},
 			FinalizedBlocks: []*types.BlockData{},
 		},
-		valManager: valManager,
+		valManager:  valManager,
+		chainParams: types.DefaultChainParams(),
+	}
+}
+
+// SetChainParams updates the chain parameters used for finality, such as
+// the consensus threshold
+func (ce *InMemoryConsensusEngine) SetChainParams(params types.ChainParams) {
+	ce.state.Mutex.Lock()
+	defer ce.state.Mutex.Unlock()
+	ce.chainParams = params
+}
+
+// SetLogger sets the logger the engine reports its rounds to
+func (ce *InMemoryConsensusEngine) SetLogger(logger types.Logger) {
+	ce.state.Mutex.Lock()
+	defer ce.state.Mutex.Unlock()
+	ce.logger = logger
+}
+
+// SetMempool sets the source of the transactions of proposed blocks.
+// Without a mempool blocks are empty.
+func (ce *InMemoryConsensusEngine) SetMempool(mempool Mempool) {
+	ce.state.Mutex.Lock()
+	defer ce.state.Mutex.Unlock()
+	ce.mempool = mempool
+}
+
+// SetRetainBlocks limits FinalizedBlocks to the most recent blocks.
+// Zero keeps every block.
+func (ce *InMemoryConsensusEngine) SetRetainBlocks(retain int) {
+	ce.state.Mutex.Lock()
+	defer ce.state.Mutex.Unlock()
+	ce.retain = retain
+}
+
+// SetValidators replaces the validator set, for example after the active
+// set rotates at an epoch boundary
+func (ce *InMemoryConsensusEngine) SetValidators(validators []validator.ValidatorNode) {
+	ce.state.Mutex.Lock()
+	defer ce.state.Mutex.Unlock()
+	ce.state.Validators = validators
+	if len(validators) > 0 {
+		ce.state.ProposerIndex = int((ce.state.CurrentHeight - 1) % uint64(len(validators)))
+	}
+}
+
+// SetLastBlock resumes consensus after a stored block, so the next
+// proposal extends it
+func (ce *InMemoryConsensusEngine) SetLastBlock(block types.BlockData) {
+	ce.state.Mutex.Lock()
+	defer ce.state.Mutex.Unlock()
+	ce.state.CurrentHeight = block.Height + 1
+	ce.state.LastBlockHash = block.Hash
+	if len(ce.state.Validators) > 0 {
+		ce.state.ProposerIndex = int((ce.state.CurrentHeight - 1) % uint64(len(ce.state.Validators)))
 	}
 }
 
@@ -61,27 +129,20 @@ func (ce *InMemoryConsensusEngine) ProposeBlock() (*types.BlockData, error) {
 		return nil, fmt.Errorf("no validators available")
 	}
 	proposer := ce.state.Validators[ce.state.ProposerIndex]
-	defaultBlockTx := types.Transaction{
-		Hash:      "tx1",
-		From:      [20]byte{},
-		To:        [20]byte{},
-		Amount:    types.CoinAmount{Amount: 1, Denom: "ue"},
-		Gas:       21000,
-		GasPrice:  1,
-		Data:      nil,
-		Nonce:     1,
-		Signature: nil,
-		Timestamp: time.Now().Unix(),
+	txs := []types.Transaction{}
+	if ce.mempool != nil {
+		txs = ce.mempool.ReapTxs(MaxBlockTxs)
 	}
 	block := &types.BlockData{
 		Height:       ce.state.CurrentHeight,
-		Hash:         fmt.Sprintf("block_%d", ce.state.CurrentHeight),
-		Timestamp:    time.Now(),
+		ParentHash:   ce.state.LastBlockHash,
+		Timestamp:    time.Now().UTC(),
 		Proposer:     proposer.ID,
-		Transactions: []types.Transaction{defaultBlockTx},
+		Transactions: txs,
 		Consensus:    types.ConsensusData{},
 	}
-	fmt.Printf("[Consensus] Proposer for block %d: %s\n", block.Height, proposer.ID)
+	block.Hash = block.CalculateHash()
+	ce.logger.Debugf("Proposer for block %d: %s", block.Height, proposer.ID)
 	return block, nil
 }
 
@@ -98,7 +159,7 @@ func (ce *InMemoryConsensusEngine) PreVote(block *types.BlockData) error {
 			Type:        types.VoteTypePreVote,
 		}
 		ce.state.Votes = append(ce.state.Votes, vote)
-		fmt.Printf("[Consensus] PreVote by %s for block %s\n", v.ID, block.Hash)
+		ce.logger.Debugf("PreVote by %s for block %s", v.ID, block.Hash)
 	}
 	return nil
 }
@@ -116,12 +177,12 @@ func (ce *InMemoryConsensusEngine) PreCommit(block *types.BlockData) error {
 			Type:        types.VoteTypePreCommit,
 		}
 		ce.state.Votes = append(ce.state.Votes, vote)
-		fmt.Printf("[Consensus] PreCommit by %s for block %s\n", v.ID, block.Hash)
+		ce.logger.Debugf("PreCommit by %s for block %s", v.ID, block.Hash)
 	}
 	return nil
 }
 
-// FinalizeBlock finalizes the block if >=67% pre-commits
+// FinalizeBlock finalizes the block if the consensus threshold of pre-commits is reached
 func (ce *InMemoryConsensusEngine) FinalizeBlock(block *types.BlockData) error {
 	ce.state.Mutex.Lock()
 	defer ce.state.Mutex.Unlock()
@@ -136,14 +197,18 @@ func (ce *InMemoryConsensusEngine) FinalizeBlock(block *types.BlockData) error {
 	if totalValidators == 0 {
 		return fmt.Errorf("no validators available")
 	}
-	percentage := (preCommits * 100) / totalValidators
-	if percentage >= int(types.ConsensusThreshold) {
+	threshold := ce.chainParams.ConsensusThreshold
+	if ce.chainParams.IsConsensusReached(uint64(preCommits), uint64(totalValidators)) {
 		block.Consensus.Finalized = true
 		block.Consensus.FinalityTime = time.Now()
 		ce.state.FinalizedBlocks = append(ce.state.FinalizedBlocks, block)
-		fmt.Printf("[Consensus] Block %d finalized with %d/%d pre-commits (>=67%%)\n", block.Height, preCommits, totalValidators)
+		if ce.retain > 0 && len(ce.state.FinalizedBlocks) > ce.retain {
+			ce.state.FinalizedBlocks = append([]*types.BlockData{}, ce.state.FinalizedBlocks[len(ce.state.FinalizedBlocks)-ce.retain:]...)
+		}
+		ce.logger.Debugf("Block %d finalized with %d/%d pre-commits (>=%d%%)", block.Height, preCommits, totalValidators, threshold)
 		// Move to next height and proposer
 		ce.state.CurrentHeight++
+		ce.state.LastBlockHash = block.Hash
 		ce.state.ProposerIndex = (ce.state.ProposerIndex + 1) % totalValidators
 		ce.state.Votes = []types.Vote{}
 		return nil
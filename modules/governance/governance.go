@@ -0,0 +1,496 @@
+package governance
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"undergroundempire/core/types"
+)
+
+// Params holds the governance parameters
+type Params struct {
+	MinDeposit       types.Int // aue required to enter the voting period
+	MaxDepositPeriod uint64    // Blocks a proposal may wait for its minimum deposit
+	VotingPeriod     uint64    // Blocks a proposal is open for voting
+	Quorum           types.Dec // Share of bonded stake that must vote
+	Threshold        types.Dec // Share of non-abstaining votes that must be yes
+	VetoThreshold    types.Dec // Share of votes that vetoes the proposal
+
+	// Expedited proposals, for emergencies
+	ExpeditedMinDeposit   types.Int // aue required to enter the expedited voting period
+	ExpeditedVotingPeriod uint64    // Blocks an expedited proposal is open for voting
+	ExpeditedThreshold    types.Dec // Share of non-abstaining votes that must be yes
+}
+
+// blocksPerDay is the number of blocks produced in a day at the target block time
+const blocksPerDay = 24 * 60 * 60 / types.BlockTime
+
+// DefaultParams returns the default governance parameters
+func DefaultParams() Params {
+	return Params{
+		MinDeposit:       types.NewUEAmount(10000),
+		MaxDepositPeriod: 2 * blocksPerDay,
+		VotingPeriod:     3 * blocksPerDay,
+		Quorum:           types.NewDecWithPrec(334, 3), // 33.4%
+		Threshold:        types.NewDecWithPrec(5, 1),   // 50%
+		VetoThreshold:    types.NewDecWithPrec(334, 3), // 33.4%
+
+		ExpeditedMinDeposit:   types.NewUEAmount(50000),
+		ExpeditedVotingPeriod: blocksPerDay,
+		ExpeditedThreshold:    types.NewDecWithPrec(667, 3), // 66.7%
+	}
+}
+
+// Validate checks that the parameters are well formed
+func (p Params) Validate() error {
+	if !p.MinDeposit.IsPositive() {
+		return fmt.Errorf("min deposit must be positive")
+	}
+	if p.MaxDepositPeriod == 0 || p.VotingPeriod == 0 {
+		return fmt.Errorf("deposit and voting periods must be positive")
+	}
+	for _, rate := range []types.Dec{p.Quorum, p.Threshold, p.VetoThreshold, p.ExpeditedThreshold} {
+		if rate.IsNegative() || rate.GT(types.OneDec()) {
+			return fmt.Errorf("governance thresholds must be between 0 and 1, got %s", rate)
+		}
+	}
+	if p.ExpeditedMinDeposit.LT(p.MinDeposit) {
+		return fmt.Errorf("expedited min deposit %s cannot be below min deposit %s", p.ExpeditedMinDeposit, p.MinDeposit)
+	}
+	if p.ExpeditedVotingPeriod == 0 || p.ExpeditedVotingPeriod >= p.VotingPeriod {
+		return fmt.Errorf("expedited voting period must be positive and shorter than the voting period")
+	}
+	if p.ExpeditedThreshold.LTE(p.Threshold) {
+		return fmt.Errorf("expedited threshold %s must exceed threshold %s", p.ExpeditedThreshold, p.Threshold)
+	}
+	return nil
+}
+
+// TallyResult holds the stake-weighted votes of a proposal
+type TallyResult struct {
+	Yes         types.Int
+	No          types.Int
+	Abstain     types.Int
+	NoWithVeto  types.Int
+	TotalBonded types.Int
+}
+
+// TotalVoted returns the stake that voted on the proposal
+func (t TallyResult) TotalVoted() types.Int {
+	return t.Yes.Add(t.No).Add(t.Abstain).Add(t.NoWithVeto)
+}
+
+// ProposalHandler executes a passed proposal
+type ProposalHandler func(ctx types.Context, proposal types.GovernanceProposal) error
+
+// BankKeeper moves proposal deposits
+type BankKeeper interface {
+	Transfer(ctx types.Context, from, to types.Address, amount types.CoinAmount) error
+	BurnTokens(ctx types.Context, from types.Address, amount types.CoinAmount) error
+}
+
+// StakingKeeper reports the stake used as voting power
+type StakingKeeper interface {
+	GetBondedStake(ctx types.Context, address types.Address) types.Int
+	GetTotalStake(ctx types.Context) types.Int
+}
+
+// GovernanceManager implements proposal submission, voting and execution
+type GovernanceManager struct {
+	params         Params
+	proposals      map[uint64]types.GovernanceProposal
+	deposits       map[uint64]map[types.Address]types.Int
+	votes          map[uint64]map[types.Address]types.VoteOption
+	handlers       map[types.ProposalType]ProposalHandler
+	nextProposalID uint64
+	bank           BankKeeper
+	staking        StakingKeeper
+	logger         types.Logger
+}
+
+// NewGovernanceManager creates a new governance manager
+func NewGovernanceManager(bank BankKeeper, staking StakingKeeper) *GovernanceManager {
+	gm := &GovernanceManager{
+		params:         DefaultParams(),
+		proposals:      make(map[uint64]types.GovernanceProposal),
+		deposits:       make(map[uint64]map[types.Address]types.Int),
+		votes:          make(map[uint64]map[types.Address]types.VoteOption),
+		handlers:       make(map[types.ProposalType]ProposalHandler),
+		nextProposalID: 1,
+		bank:           bank,
+		staking:        staking,
+	}
+
+	// Text proposals only record the community's opinion
+	gm.RegisterProposalHandler(types.ProposalTypeText, func(ctx types.Context, proposal types.GovernanceProposal) error {
+		return nil
+	})
+
+	return gm
+}
+
+// SetLogger sets the logger governance reports to
+func (gm *GovernanceManager) SetLogger(logger types.Logger) {
+	gm.logger = logger
+}
+
+// RegisterProposalHandler sets the handler that executes proposals of a type
+func (gm *GovernanceManager) RegisterProposalHandler(proposalType types.ProposalType, handler ProposalHandler) {
+	gm.handlers[proposalType] = handler
+}
+
+// GetParams returns the current governance parameters
+func (gm *GovernanceManager) GetParams() Params {
+	return gm.params
+}
+
+// SetParams updates the governance parameters
+func (gm *GovernanceManager) SetParams(params Params) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	gm.params = params
+	return nil
+}
+
+// SubmitProposal submits a proposal. The proposal's TotalDeposit is taken
+// from the proposer as the initial deposit.
+func (gm *GovernanceManager) SubmitProposal(ctx types.Context, proposal types.GovernanceProposal) error {
+	_, err := gm.Submit(ctx, proposal, proposal.TotalDeposit)
+	return err
+}
+
+// Submit submits a proposal with an initial deposit and returns its ID
+func (gm *GovernanceManager) Submit(ctx types.Context, proposal types.GovernanceProposal, initialDeposit types.Int) (uint64, error) {
+	if proposal.Title == "" || proposal.Description == "" {
+		return 0, fmt.Errorf("proposal title and description cannot be empty")
+	}
+
+	if proposal.Proposer.IsZero() {
+		return 0, fmt.Errorf("proposer address cannot be zero")
+	}
+
+	if proposal.Type == "" {
+		proposal.Type = types.ProposalTypeText
+	}
+	if _, exists := gm.handlers[proposal.Type]; !exists {
+		return 0, fmt.Errorf("unknown proposal type: %s", proposal.Type)
+	}
+
+	proposal.ID = gm.nextProposalID
+	proposal.Status = types.ProposalStatusDeposit
+	proposal.Votes = make(map[types.VoteOption]types.Int)
+	proposal.CreatedAt = ctx.Timestamp
+	proposal.SubmitHeight = ctx.Height
+	proposal.DepositEndHeight = ctx.Height + gm.params.MaxDepositPeriod
+	proposal.VotingStartHeight = 0
+	proposal.VotingEndHeight = 0
+	proposal.TotalDeposit = types.ZeroInt()
+
+	gm.proposals[proposal.ID] = proposal
+	gm.nextProposalID++
+
+	if initialDeposit.IsPositive() {
+		if err := gm.Deposit(ctx, proposal.ID, proposal.Proposer, initialDeposit); err != nil {
+			delete(gm.proposals, proposal.ID)
+			gm.nextProposalID--
+			return 0, err
+		}
+	}
+
+	return proposal.ID, nil
+}
+
+// Deposit adds to a proposal's deposit. Reaching the minimum deposit opens
+// the voting period.
+func (gm *GovernanceManager) Deposit(ctx types.Context, proposalID uint64, depositor types.Address, amount types.Int) error {
+	proposal, err := gm.GetProposal(ctx, proposalID)
+	if err != nil {
+		return err
+	}
+
+	if proposal.Status != types.ProposalStatusDeposit && proposal.Status != types.ProposalStatusActive {
+		return fmt.Errorf("proposal %d is not accepting deposits (status: %s)", proposalID, proposal.Status)
+	}
+
+	if !amount.IsPositive() {
+		return fmt.Errorf("deposit amount must be positive")
+	}
+
+	err = gm.bank.Transfer(ctx, depositor, types.ModuleAddress(types.GovernanceModuleName), types.NewUECoins(amount))
+	if err != nil {
+		return fmt.Errorf("failed to deposit: %v", err)
+	}
+
+	if gm.deposits[proposalID] == nil {
+		gm.deposits[proposalID] = make(map[types.Address]types.Int)
+	}
+	gm.deposits[proposalID][depositor] = gm.deposits[proposalID][depositor].Add(amount)
+	proposal.TotalDeposit = proposal.TotalDeposit.Add(amount)
+
+	if proposal.Status == types.ProposalStatusDeposit && proposal.TotalDeposit.GTE(gm.minDeposit(proposal)) {
+		gm.activateVotingPeriod(ctx, &proposal)
+	}
+
+	gm.proposals[proposalID] = proposal
+	return nil
+}
+
+// activateVotingPeriod opens a proposal for voting
+func (gm *GovernanceManager) activateVotingPeriod(ctx types.Context, proposal *types.GovernanceProposal) {
+	proposal.Status = types.ProposalStatusActive
+	proposal.VotingStartHeight = ctx.Height
+	gm.setVotingEnd(ctx, proposal)
+}
+
+// setVotingEnd sets the end of a proposal's voting period from its start
+func (gm *GovernanceManager) setVotingEnd(ctx types.Context, proposal *types.GovernanceProposal) {
+	period := gm.params.VotingPeriod
+	if proposal.Expedited {
+		period = gm.params.ExpeditedVotingPeriod
+	}
+
+	proposal.VotingEndHeight = proposal.VotingStartHeight + period
+	remaining := time.Duration(0)
+	if proposal.VotingEndHeight > ctx.Height {
+		remaining = time.Duration((proposal.VotingEndHeight-ctx.Height)*ctx.Params.BlockTime) * time.Second
+	}
+	proposal.EndTime = ctx.Timestamp.Add(remaining)
+}
+
+// minDeposit returns the deposit a proposal needs to enter voting
+func (gm *GovernanceManager) minDeposit(proposal types.GovernanceProposal) types.Int {
+	if proposal.Expedited {
+		return gm.params.ExpeditedMinDeposit
+	}
+	return gm.params.MinDeposit
+}
+
+// Vote records a vote on a proposal in its voting period. Votes are weighted
+// by the voter's bonded stake when the voting period ends, and a later vote
+// replaces an earlier one.
+func (gm *GovernanceManager) Vote(ctx types.Context, proposalID uint64, voter types.Address, option types.VoteOption) error {
+	proposal, err := gm.GetProposal(ctx, proposalID)
+	if err != nil {
+		return err
+	}
+
+	if proposal.Status != types.ProposalStatusActive {
+		return fmt.Errorf("proposal %d is not in its voting period (status: %s)", proposalID, proposal.Status)
+	}
+
+	if _, err := types.ParseVoteOption(string(option)); err != nil {
+		return err
+	}
+
+	if gm.staking.GetBondedStake(ctx, voter).IsZero() {
+		return fmt.Errorf("voter %s has no bonded stake", voter)
+	}
+
+	if gm.votes[proposalID] == nil {
+		gm.votes[proposalID] = make(map[types.Address]types.VoteOption)
+	}
+	gm.votes[proposalID][voter] = option
+
+	return nil
+}
+
+// GetProposal returns a proposal by ID
+func (gm *GovernanceManager) GetProposal(ctx types.Context, proposalID uint64) (types.GovernanceProposal, error) {
+	proposal, exists := gm.proposals[proposalID]
+	if !exists {
+		return types.GovernanceProposal{}, fmt.Errorf("proposal %d not found", proposalID)
+	}
+
+	return proposal, nil
+}
+
+// GetProposals returns the proposals with a status ordered by ID, or all
+// proposals if status is empty
+func (gm *GovernanceManager) GetProposals(ctx types.Context, status types.ProposalStatus) []types.GovernanceProposal {
+	var proposals []types.GovernanceProposal
+
+	for _, proposal := range gm.proposals {
+		if status == "" || proposal.Status == status {
+			proposals = append(proposals, proposal)
+		}
+	}
+
+	sort.Slice(proposals, func(i, j int) bool {
+		return proposals[i].ID < proposals[j].ID
+	})
+	return proposals
+}
+
+// Tally computes the current stake-weighted tally of a proposal
+func (gm *GovernanceManager) Tally(ctx types.Context, proposalID uint64) (TallyResult, error) {
+	if _, err := gm.GetProposal(ctx, proposalID); err != nil {
+		return TallyResult{}, err
+	}
+
+	result := TallyResult{TotalBonded: gm.staking.GetTotalStake(ctx)}
+	for voter, option := range gm.votes[proposalID] {
+		power := gm.staking.GetBondedStake(ctx, voter)
+		switch option {
+		case types.VoteOptionYes:
+			result.Yes = result.Yes.Add(power)
+		case types.VoteOptionNo:
+			result.No = result.No.Add(power)
+		case types.VoteOptionAbstain:
+			result.Abstain = result.Abstain.Add(power)
+		case types.VoteOptionNoWithVeto:
+			result.NoWithVeto = result.NoWithVeto.Add(power)
+		}
+	}
+
+	return result, nil
+}
+
+// ExecuteProposal runs the handler of a passed proposal
+func (gm *GovernanceManager) ExecuteProposal(ctx types.Context, proposalID uint64) error {
+	proposal, err := gm.GetProposal(ctx, proposalID)
+	if err != nil {
+		return err
+	}
+
+	if proposal.Status != types.ProposalStatusPassed {
+		return fmt.Errorf("proposal %d has not passed (status: %s)", proposalID, proposal.Status)
+	}
+
+	if err := gm.handlers[proposal.Type](ctx, proposal); err != nil {
+		proposal.Status = types.ProposalStatusFailed
+		gm.proposals[proposalID] = proposal
+		return fmt.Errorf("failed to execute proposal %d: %v", proposalID, err)
+	}
+
+	proposal.Status = types.ProposalStatusExecuted
+	gm.proposals[proposalID] = proposal
+	return nil
+}
+
+// ProcessBlockEnd closes expired deposit periods and finished voting
+// periods. Passed proposals are executed immediately; an execution failure
+// marks the proposal failed without halting the block.
+func (gm *GovernanceManager) ProcessBlockEnd(ctx types.Context) error {
+	for _, proposal := range gm.GetProposals(ctx, "") {
+		switch {
+		case proposal.Status == types.ProposalStatusDeposit && ctx.Height >= proposal.DepositEndHeight:
+			proposal.Status = types.ProposalStatusRejected
+			gm.proposals[proposal.ID] = proposal
+			if err := gm.refundDeposits(ctx, proposal.ID); err != nil {
+				return err
+			}
+
+		case proposal.Status == types.ProposalStatusActive && ctx.Height >= proposal.VotingEndHeight:
+			if err := gm.closeVotingPeriod(ctx, proposal); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// closeVotingPeriod tallies a proposal, settles its deposits and executes
+// it if it passed. An expedited proposal that fails without being vetoed
+// becomes a normal proposal; its votes carry over and its voting period is
+// extended to the normal length.
+func (gm *GovernanceManager) closeVotingPeriod(ctx types.Context, proposal types.GovernanceProposal) error {
+	result, _ := gm.Tally(ctx, proposal.ID)
+	threshold := gm.params.Threshold
+	if proposal.Expedited {
+		threshold = gm.params.ExpeditedThreshold
+	}
+	passed, vetoed := gm.evaluate(result, threshold)
+
+	if proposal.Expedited && !passed && !vetoed {
+		proposal.Expedited = false
+		gm.setVotingEnd(ctx, &proposal)
+		gm.proposals[proposal.ID] = proposal
+		gm.logger.Infof("Expedited proposal %d failed, converted to a normal proposal ending at height %d",
+			proposal.ID, proposal.VotingEndHeight)
+		return nil
+	}
+
+	proposal.Votes = map[types.VoteOption]types.Int{
+		types.VoteOptionYes:        result.Yes,
+		types.VoteOptionNo:         result.No,
+		types.VoteOptionAbstain:    result.Abstain,
+		types.VoteOptionNoWithVeto: result.NoWithVeto,
+	}
+	proposal.Status = types.ProposalStatusRejected
+	if passed {
+		proposal.Status = types.ProposalStatusPassed
+	}
+	gm.proposals[proposal.ID] = proposal
+
+	var err error
+	if vetoed {
+		err = gm.burnDeposits(ctx, proposal.ID)
+	} else {
+		err = gm.refundDeposits(ctx, proposal.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if passed {
+		if err := gm.ExecuteProposal(ctx, proposal.ID); err != nil {
+			gm.logger.Errorf("%v", err)
+		}
+	}
+
+	return nil
+}
+
+// evaluate applies quorum, veto and threshold rules to a tally
+func (gm *GovernanceManager) evaluate(result TallyResult, threshold types.Dec) (passed bool, vetoed bool) {
+	totalVoted := result.TotalVoted()
+	if totalVoted.IsZero() || result.TotalBonded.IsZero() ||
+		types.NewDecFromInt(totalVoted).QuoInt(result.TotalBonded).LT(gm.params.Quorum) {
+		return false, false
+	}
+
+	if types.NewDecFromInt(result.NoWithVeto).QuoInt(totalVoted).GTE(gm.params.VetoThreshold) {
+		return false, true
+	}
+
+	nonAbstaining := totalVoted.Sub(result.Abstain)
+	if nonAbstaining.IsZero() {
+		return false, false
+	}
+
+	return types.NewDecFromInt(result.Yes).QuoInt(nonAbstaining).GT(threshold), false
+}
+
+// refundDeposits returns a proposal's deposits to their depositors
+func (gm *GovernanceManager) refundDeposits(ctx types.Context, proposalID uint64) error {
+	for depositor, amount := range gm.deposits[proposalID] {
+		err := gm.bank.Transfer(ctx, types.ModuleAddress(types.GovernanceModuleName), depositor, types.NewUECoins(amount))
+		if err != nil {
+			return fmt.Errorf("failed to refund deposit of proposal %d: %v", proposalID, err)
+		}
+	}
+
+	delete(gm.deposits, proposalID)
+	return nil
+}
+
+// burnDeposits destroys a vetoed proposal's deposits
+func (gm *GovernanceManager) burnDeposits(ctx types.Context, proposalID uint64) error {
+	total := types.ZeroInt()
+	for _, amount := range gm.deposits[proposalID] {
+		total = total.Add(amount)
+	}
+
+	if total.IsPositive() {
+		err := gm.bank.BurnTokens(ctx, types.ModuleAddress(types.GovernanceModuleName), types.NewUECoins(total))
+		if err != nil {
+			return fmt.Errorf("failed to burn deposit of proposal %d: %v", proposalID, err)
+		}
+	}
+
+	delete(gm.deposits, proposalID)
+	return nil
+}
@@ -0,0 +1,229 @@
+package governance
+
+import (
+	"fmt"
+	"testing"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/treasury"
+)
+
+// fixedStaking assigns a fixed bonded stake to each voter
+type fixedStaking map[types.Address]int64
+
+func (s fixedStaking) GetBondedStake(ctx types.Context, address types.Address) types.Int {
+	return types.NewInt(s[address])
+}
+
+func (s fixedStaking) GetTotalStake(ctx types.Context) types.Int {
+	total := types.ZeroInt()
+	for _, stake := range s {
+		total = total.AddRaw(uint64(stake))
+	}
+	return total
+}
+
+var (
+	alice = types.Address{1}
+	bob   = types.Address{2}
+	carol = types.Address{3}
+)
+
+func setup(t *testing.T) (*GovernanceManager, *treasury.TreasuryManager) {
+	tm := treasury.NewTreasuryManager()
+	for _, address := range []types.Address{alice, bob, carol} {
+		tm.MintTokens(types.Context{}, address, types.NewUECoins(types.NewUEAmount(100000)))
+	}
+
+	gm := NewGovernanceManager(tm, fixedStaking{alice: 50000, bob: 30000, carol: 20000})
+	return gm, tm
+}
+
+func TestProposalLifecycle_PassAndExecute(t *testing.T) {
+	gm, tm := setup(t)
+	ctx := types.Context{Height: 10, Params: types.DefaultChainParams()}
+
+	executed := false
+	gm.RegisterProposalHandler("signal", func(ctx types.Context, proposal types.GovernanceProposal) error {
+		executed = true
+		return nil
+	})
+
+	id, err := gm.Submit(ctx, types.GovernanceProposal{
+		Title: "Signal", Description: "Test", Proposer: alice, Type: "signal",
+	}, types.NewUEAmount(4000))
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	proposal, _ := gm.GetProposal(ctx, id)
+	if proposal.Status != types.ProposalStatusDeposit {
+		t.Fatalf("expected deposit period, got %s", proposal.Status)
+	}
+
+	if err := gm.Deposit(ctx, id, bob, types.NewUEAmount(6000)); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+	proposal, _ = gm.GetProposal(ctx, id)
+	if proposal.Status != types.ProposalStatusActive {
+		t.Fatalf("expected voting period, got %s", proposal.Status)
+	}
+
+	gm.Vote(ctx, id, alice, types.VoteOptionYes)
+	gm.Vote(ctx, id, bob, types.VoteOptionNo)
+	gm.Vote(ctx, id, carol, types.VoteOptionAbstain)
+
+	if err := gm.ProcessBlockEnd(ctx.WithHeight(proposal.VotingEndHeight)); err != nil {
+		t.Fatalf("end block: %v", err)
+	}
+
+	proposal, _ = gm.GetProposal(ctx, id)
+	if proposal.Status != types.ProposalStatusExecuted || !executed {
+		t.Fatalf("expected executed proposal, got %s", proposal.Status)
+	}
+	if !proposal.Votes[types.VoteOptionYes].Equal(types.NewInt(50000)) {
+		t.Fatalf("unexpected tally: %+v", proposal.Votes)
+	}
+
+	// Deposits are refunded
+	if got := tm.GetBalance(ctx, bob).Amount; !got.Equal(types.NewUEAmount(100000)) {
+		t.Fatalf("bob balance = %s, want 100000UE", got)
+	}
+}
+
+func TestProposalLifecycle_VetoBurnsDeposit(t *testing.T) {
+	gm, tm := setup(t)
+	ctx := types.Context{Height: 1, Params: types.DefaultChainParams()}
+
+	id, _ := gm.Submit(ctx, types.GovernanceProposal{Title: "Bad", Description: "Spam", Proposer: alice}, types.NewUEAmount(10000))
+	gm.Vote(ctx, id, alice, types.VoteOptionYes)
+	gm.Vote(ctx, id, bob, types.VoteOptionNoWithVeto)
+	gm.Vote(ctx, id, carol, types.VoteOptionNoWithVeto)
+
+	proposal, _ := gm.GetProposal(ctx, id)
+	gm.ProcessBlockEnd(ctx.WithHeight(proposal.VotingEndHeight))
+
+	proposal, _ = gm.GetProposal(ctx, id)
+	if proposal.Status != types.ProposalStatusRejected {
+		t.Fatalf("expected rejected proposal, got %s", proposal.Status)
+	}
+	if got := tm.GetBurned(ctx, types.NativeDenom).Amount; !got.Equal(types.NewUEAmount(10000)) {
+		t.Fatalf("burned = %s, want 10000UE", got)
+	}
+}
+
+func TestProposalLifecycle_QuorumAndFailure(t *testing.T) {
+	gm, _ := setup(t)
+	ctx := types.Context{Height: 1, Params: types.DefaultChainParams()}
+
+	gm.RegisterProposalHandler("broken", func(ctx types.Context, proposal types.GovernanceProposal) error {
+		return fmt.Errorf("boom")
+	})
+	params := gm.GetParams()
+
+	// Carol alone does not reach quorum
+	lowTurnout, _ := gm.Submit(ctx, types.GovernanceProposal{Title: "A", Description: "A", Proposer: alice}, params.MinDeposit)
+	gm.Vote(ctx, lowTurnout, carol, types.VoteOptionYes)
+
+	broken, _ := gm.Submit(ctx, types.GovernanceProposal{Title: "B", Description: "B", Proposer: alice, Type: "broken"}, params.MinDeposit)
+	gm.Vote(ctx, broken, alice, types.VoteOptionYes)
+
+	gm.ProcessBlockEnd(ctx.WithHeight(ctx.Height + params.VotingPeriod))
+
+	if proposal, _ := gm.GetProposal(ctx, lowTurnout); proposal.Status != types.ProposalStatusRejected {
+		t.Fatalf("expected rejected proposal without quorum, got %s", proposal.Status)
+	}
+	if proposal, _ := gm.GetProposal(ctx, broken); proposal.Status != types.ProposalStatusFailed {
+		t.Fatalf("expected failed proposal, got %s", proposal.Status)
+	}
+}
+
+func TestExpeditedProposal_PassesEarlyOrConverts(t *testing.T) {
+	gm, _ := setup(t)
+	ctx := types.Context{Height: 1, Params: types.DefaultChainParams()}
+	params := gm.GetParams()
+
+	// The normal minimum deposit does not open an expedited vote
+	underfunded, _ := gm.Submit(ctx, types.GovernanceProposal{Title: "U", Description: "U", Proposer: carol, Expedited: true}, params.MinDeposit)
+	if proposal, _ := gm.GetProposal(ctx, underfunded); proposal.Status != types.ProposalStatusDeposit {
+		t.Fatalf("expected deposit period, got %s", proposal.Status)
+	}
+
+	// 70% yes clears the expedited threshold
+	urgent, _ := gm.Submit(ctx, types.GovernanceProposal{Title: "A", Description: "A", Proposer: alice, Expedited: true}, params.ExpeditedMinDeposit)
+	gm.Vote(ctx, urgent, alice, types.VoteOptionYes)
+	gm.Vote(ctx, urgent, carol, types.VoteOptionYes)
+
+	// 62.5% yes passes normally but not expedited
+	contested, _ := gm.Submit(ctx, types.GovernanceProposal{Title: "B", Description: "B", Proposer: alice, Expedited: true}, params.ExpeditedMinDeposit)
+	gm.Vote(ctx, contested, alice, types.VoteOptionYes)
+	gm.Vote(ctx, contested, bob, types.VoteOptionNo)
+
+	proposal, _ := gm.GetProposal(ctx, urgent)
+	if proposal.VotingEndHeight != ctx.Height+params.ExpeditedVotingPeriod {
+		t.Fatalf("expected expedited voting end %d, got %d", ctx.Height+params.ExpeditedVotingPeriod, proposal.VotingEndHeight)
+	}
+	gm.ProcessBlockEnd(ctx.WithHeight(proposal.VotingEndHeight))
+
+	if proposal, _ := gm.GetProposal(ctx, urgent); proposal.Status != types.ProposalStatusExecuted {
+		t.Fatalf("expected expedited proposal to be executed, got %s", proposal.Status)
+	}
+
+	proposal, _ = gm.GetProposal(ctx, contested)
+	if proposal.Status != types.ProposalStatusActive || proposal.Expedited {
+		t.Fatalf("expected conversion to a normal proposal, got status %s expedited %v", proposal.Status, proposal.Expedited)
+	}
+	if proposal.VotingEndHeight != ctx.Height+params.VotingPeriod {
+		t.Fatalf("expected normal voting end %d, got %d", ctx.Height+params.VotingPeriod, proposal.VotingEndHeight)
+	}
+
+	// Votes carry over and the normal threshold applies
+	gm.ProcessBlockEnd(ctx.WithHeight(proposal.VotingEndHeight))
+	if proposal, _ := gm.GetProposal(ctx, contested); proposal.Status != types.ProposalStatusExecuted {
+		t.Fatalf("expected converted proposal to pass, got %s", proposal.Status)
+	}
+}
+
+func TestExecuteTransaction_GovernanceMessages(t *testing.T) {
+	gm, tm := setup(t)
+	ctx := types.Context{Height: 10, Params: types.DefaultChainParams()}
+
+	send := func(from types.Address, msgType string, content interface{}) error {
+		data, err := types.NewModuleMsg(msgType, content)
+		if err != nil {
+			t.Fatalf("encode %s: %v", msgType, err)
+		}
+		tx := types.NewTransaction(from, types.ModuleAddress(types.GovernanceModuleName), types.NewUECoins(types.ZeroInt()), 1, 1, data, 0)
+		return types.ExecuteModuleTx(ctx, tx, types.GovernanceModuleName, tm, gm.HandleMsg)
+	}
+
+	err := send(alice, MsgTypeSubmitProposal, MsgSubmitProposal{
+		Title: "Signal", Description: "Test", Type: types.ProposalTypeText, InitialDeposit: types.NewUEAmount(4000),
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if err := send(bob, MsgTypeDeposit, MsgDeposit{ProposalID: 1, Amount: types.NewUEAmount(6000)}); err != nil {
+		t.Fatalf("deposit: %v", err)
+	}
+
+	proposal, _ := gm.GetProposal(ctx, 1)
+	if proposal.Proposer != alice || proposal.Status != types.ProposalStatusActive {
+		t.Fatalf("unexpected proposal after deposits: %+v", proposal)
+	}
+	if got := tm.GetBalance(ctx, bob).Amount; !got.Equal(types.NewUEAmount(100000 - 6000).Sub(types.NewInt(1))) {
+		t.Fatalf("bob balance = %s, want the deposit and fee deducted", got)
+	}
+
+	if err := send(carol, MsgTypeVote, MsgVote{ProposalID: 1, Option: types.VoteOptionNo}); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if err := send(carol, MsgTypeVote, MsgVote{ProposalID: 1, Option: "maybe"}); err == nil {
+		t.Fatal("expected an invalid vote option to be rejected")
+	}
+
+	tally, _ := gm.Tally(ctx, 1)
+	if !tally.No.Equal(types.NewInt(20000)) || !tally.Yes.IsZero() {
+		t.Fatalf("unexpected tally: %+v", tally)
+	}
+}
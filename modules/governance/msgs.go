@@ -0,0 +1,79 @@
+package governance
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"undergroundempire/core/types"
+)
+
+// Message types accepted by the governance module
+const (
+	MsgTypeSubmitProposal = "submit_proposal"
+	MsgTypeDeposit        = "deposit"
+	MsgTypeVote           = "vote"
+)
+
+// MsgSubmitProposal submits a proposal by the sender with an initial
+// deposit taken from the sender's balance
+type MsgSubmitProposal struct {
+	Title          string
+	Description    string
+	Type           types.ProposalType
+	Content        json.RawMessage
+	Expedited      bool
+	InitialDeposit types.Int
+}
+
+// MsgDeposit adds to the deposit of a proposal
+type MsgDeposit struct {
+	ProposalID uint64
+	Amount     types.Int
+}
+
+// MsgVote votes on a proposal in its voting period
+type MsgVote struct {
+	ProposalID uint64
+	Option     types.VoteOption
+}
+
+// HandleMsg executes a governance message sent by an account
+func (gm *GovernanceManager) HandleMsg(ctx types.Context, sender types.Address, msg types.ModuleMsg) error {
+	switch msg.Type {
+	case MsgTypeSubmitProposal:
+		var content MsgSubmitProposal
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		id, err := gm.Submit(ctx, types.GovernanceProposal{
+			Title:       content.Title,
+			Description: content.Description,
+			Proposer:    sender,
+			Type:        content.Type,
+			Content:     content.Content,
+			Expedited:   content.Expedited,
+		}, content.InitialDeposit)
+		if err != nil {
+			return err
+		}
+		gm.logger.Infof("Proposal %d submitted by %s", id, sender)
+		return nil
+
+	case MsgTypeDeposit:
+		var content MsgDeposit
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		return gm.Deposit(ctx, content.ProposalID, sender, content.Amount)
+
+	case MsgTypeVote:
+		var content MsgVote
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		return gm.Vote(ctx, content.ProposalID, sender, content.Option)
+
+	default:
+		return fmt.Errorf("unknown governance message type: %s", msg.Type)
+	}
+}
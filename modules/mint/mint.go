@@ -0,0 +1,148 @@
+package mint
+
+import (
+	"fmt"
+
+	"undergroundempire/core/types"
+)
+
+// Params holds the inflation parameters of the minting module
+type Params struct {
+	MintDenom     string
+	InflationMax  types.Dec // Annual inflation with nothing bonded
+	InflationMin  types.Dec // Annual inflation at or above the bonded goal
+	GoalBonded    types.Dec // Target bonded ratio
+	BlocksPerYear uint64
+}
+
+// DefaultParams returns the default inflation parameters
+func DefaultParams() Params {
+	return Params{
+		MintDenom:     types.NativeDenom,
+		InflationMax:  types.NewDecWithPrec(20, 2), // 20%
+		InflationMin:  types.NewDecWithPrec(7, 2),  // 7%
+		GoalBonded:    types.NewDecWithPrec(67, 2), // 67%
+		BlocksPerYear: 365 * 24 * 60 * 60 / types.BlockTime,
+	}
+}
+
+// Validate checks that the parameters are well formed
+func (p Params) Validate() error {
+	if err := types.ValidateDenom(p.MintDenom); err != nil {
+		return fmt.Errorf("invalid mint denom: %v", err)
+	}
+	// Stake is bonded and rewards are distributed in the native denom only
+	if p.MintDenom != types.NativeDenom {
+		return fmt.Errorf("mint denom must be %s, got %s", types.NativeDenom, p.MintDenom)
+	}
+	if p.InflationMax.GT(types.OneDec()) {
+		return fmt.Errorf("max inflation cannot exceed 1, got %s", p.InflationMax)
+	}
+	if p.InflationMin.IsNegative() || p.InflationMin.GT(p.InflationMax) {
+		return fmt.Errorf("min inflation %s must be between 0 and max inflation %s", p.InflationMin, p.InflationMax)
+	}
+	if !p.GoalBonded.IsPositive() || p.GoalBonded.GT(types.OneDec()) {
+		return fmt.Errorf("bonded goal must be above 0 and at most 1, got %s", p.GoalBonded)
+	}
+	if p.BlocksPerYear == 0 {
+		return fmt.Errorf("blocks per year must be positive")
+	}
+	return nil
+}
+
+// InflationRate returns the annual inflation for a bonded ratio. Inflation
+// falls linearly from InflationMax with nothing bonded to InflationMin once
+// the bonded goal is reached, rewarding stakers most when security is low.
+func (p Params) InflationRate(bondedRatio types.Dec) types.Dec {
+	if bondedRatio.GTE(p.GoalBonded) {
+		return p.InflationMin
+	}
+	return p.InflationMax.Sub(p.InflationMax.Sub(p.InflationMin).Mul(bondedRatio).Quo(p.GoalBonded))
+}
+
+// Minter holds the inflation state derived for the latest block
+type Minter struct {
+	Inflation        types.Dec // Current annual inflation
+	AnnualProvisions types.Int // Tokens minted per year at the current rate
+}
+
+// SupplyKeeper mints tokens and reports the total supply
+type SupplyKeeper interface {
+	GetSupply(ctx types.Context, denom string) types.CoinAmount
+	MintTokens(ctx types.Context, to types.Address, amount types.CoinAmount) error
+}
+
+// StakingKeeper reports the bonded stake
+type StakingKeeper interface {
+	GetTotalStake(ctx types.Context) types.Int
+}
+
+// MintManager mints block rewards according to the inflation curve
+type MintManager struct {
+	params  Params
+	minter  Minter
+	supply  SupplyKeeper
+	staking StakingKeeper
+}
+
+// NewMintManager creates a new mint manager
+func NewMintManager(supply SupplyKeeper, staking StakingKeeper) *MintManager {
+	return &MintManager{
+		params:  DefaultParams(),
+		supply:  supply,
+		staking: staking,
+	}
+}
+
+// GetParams returns the current inflation parameters
+func (mm *MintManager) GetParams() Params {
+	return mm.params
+}
+
+// SetParams updates the inflation parameters
+func (mm *MintManager) SetParams(params Params) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	mm.params = params
+	return nil
+}
+
+// GetMinter returns the inflation state of the latest block
+func (mm *MintManager) GetMinter() Minter {
+	return mm.minter
+}
+
+// BondedRatio returns the share of the supply that is bonded
+func (mm *MintManager) BondedRatio(ctx types.Context) types.Dec {
+	supply := mm.supply.GetSupply(ctx, mm.params.MintDenom).Amount
+	if supply.IsZero() {
+		return types.ZeroDec()
+	}
+
+	bonded := mm.staking.GetTotalStake(ctx)
+	if bonded.GTE(supply) {
+		return types.OneDec()
+	}
+	return types.NewDecFromInt(bonded).QuoInt(supply)
+}
+
+// ProcessBlockStart recomputes inflation and mints the block provision into
+// the fee collector, where it waits to be distributed to validators
+func (mm *MintManager) ProcessBlockStart(ctx types.Context) (types.CoinAmount, error) {
+	supply := mm.supply.GetSupply(ctx, mm.params.MintDenom).Amount
+
+	mm.minter.Inflation = mm.params.InflationRate(mm.BondedRatio(ctx))
+	mm.minter.AnnualProvisions = mm.minter.Inflation.MulInt(supply).TruncateInt()
+
+	provision := types.NewCoinAmount(mm.minter.AnnualProvisions.QuoRaw(mm.params.BlocksPerYear), mm.params.MintDenom)
+	if provision.IsZero() {
+		return provision, nil
+	}
+
+	if err := mm.supply.MintTokens(ctx, types.ModuleAddress(types.FeeCollectorName), provision); err != nil {
+		return types.CoinAmount{}, fmt.Errorf("failed to mint block provision: %v", err)
+	}
+
+	return provision, nil
+}
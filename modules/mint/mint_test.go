@@ -0,0 +1,81 @@
+package mint
+
+import (
+	"testing"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/treasury"
+)
+
+type fixedStake struct{ amount types.Int }
+
+func (s fixedStake) GetTotalStake(ctx types.Context) types.Int {
+	return s.amount
+}
+
+func TestInflationRate_Curve(t *testing.T) {
+	params := DefaultParams()
+
+	cases := []struct {
+		bondedRatio types.Dec
+		want        types.Dec
+	}{
+		{types.ZeroDec(), params.InflationMax},
+		{params.GoalBonded.QuoInt(types.NewInt(2)), params.InflationMax.Add(params.InflationMin).QuoInt(types.NewInt(2))},
+		{params.GoalBonded, params.InflationMin},
+		{types.OneDec(), params.InflationMin},
+	}
+
+	for _, tc := range cases {
+		if got := params.InflationRate(tc.bondedRatio); !got.Equal(tc.want) {
+			t.Errorf("InflationRate(%s) = %s, want %s", tc.bondedRatio, got, tc.want)
+		}
+	}
+}
+
+func TestProcessBlockStart_MintsToFeeCollector(t *testing.T) {
+	ctx := types.Context{}
+	tm := treasury.NewTreasuryManager()
+	supply := types.NewUEAmount(1_000_000)
+	tm.MintTokens(ctx, types.Address{1}, types.NewUECoins(supply))
+
+	mm := NewMintManager(tm, fixedStake{types.NewUEAmount(670_000)})
+	provision, err := mm.ProcessBlockStart(ctx)
+	if err != nil {
+		t.Fatalf("process block start: %v", err)
+	}
+
+	if !mm.GetMinter().Inflation.Equal(mm.GetParams().InflationMin) {
+		t.Fatalf("expected min inflation at bonded goal, got %s", mm.GetMinter().Inflation)
+	}
+
+	want := supply.MulRaw(7).QuoRaw(100).QuoRaw(mm.GetParams().BlocksPerYear)
+	if !provision.Amount.Equal(want) {
+		t.Fatalf("expected provision %s, got %s", want, provision.Amount)
+	}
+
+	collected := tm.GetBalance(ctx, types.ModuleAddress(types.FeeCollectorName))
+	if !collected.Amount.Equal(want) {
+		t.Fatalf("expected fee collector balance %s, got %s", want, collected.Amount)
+	}
+}
+
+func TestParamsValidate_MintDenom(t *testing.T) {
+	cases := []struct {
+		denom string
+		ok    bool
+	}{
+		{types.NativeDenom, true},
+		{"", false},
+		{"Not A Denom", false},
+		{"factory/gold", false},
+	}
+
+	for _, c := range cases {
+		params := DefaultParams()
+		params.MintDenom = c.denom
+		if err := params.Validate(); (err == nil) != c.ok {
+			t.Errorf("Validate with mint denom %q: err = %v, want ok = %v", c.denom, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package params
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"undergroundempire/core/types"
+)
+
+// ChainSubspace is the subspace holding the chain-wide parameters
+const ChainSubspace = "chain"
+
+// ParamSet is a module's parameter struct
+type ParamSet interface {
+	Validate() error
+}
+
+// Subspace exposes a module's parameters to the parameter store. Values are
+// addressed by field name and encoded as JSON.
+type Subspace interface {
+	Name() string
+	Params() (json.RawMessage, error)
+	Validate(values map[string]json.RawMessage) error
+	Update(values map[string]json.RawMessage) error
+}
+
+// subspace adapts a module's GetParams/SetParams pair to a Subspace
+type subspace[P ParamSet] struct {
+	name string
+	get  func() P
+	set  func(P) error
+}
+
+// NewSubspace creates a subspace backed by a module's parameter getter and
+// setter. The setter is expected to validate the parameters it is given.
+func NewSubspace[P ParamSet](name string, get func() P, set func(P) error) Subspace {
+	return &subspace[P]{name: name, get: get, set: set}
+}
+
+// Name returns the subspace name
+func (s *subspace[P]) Name() string {
+	return s.name
+}
+
+// Params returns the current parameters as JSON
+func (s *subspace[P]) Params() (json.RawMessage, error) {
+	return json.Marshal(s.get())
+}
+
+// Validate checks that the values can be applied to the current parameters
+func (s *subspace[P]) Validate(values map[string]json.RawMessage) error {
+	_, err := s.merge(values)
+	return err
+}
+
+// Update applies the values to the current parameters
+func (s *subspace[P]) Update(values map[string]json.RawMessage) error {
+	next, err := s.merge(values)
+	if err != nil {
+		return err
+	}
+	return s.set(next)
+}
+
+// merge overlays the values on the current parameters and validates the result
+func (s *subspace[P]) merge(values map[string]json.RawMessage) (P, error) {
+	var next P
+
+	current, err := s.Params()
+	if err != nil {
+		return next, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(current, &fields); err != nil {
+		return next, err
+	}
+
+	for key, value := range values {
+		if _, exists := fields[key]; !exists {
+			return next, fmt.Errorf("unknown parameter %s/%s", s.name, key)
+		}
+		fields[key] = value
+	}
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return next, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&next); err != nil {
+		return next, fmt.Errorf("invalid value for %s parameters: %v", s.name, err)
+	}
+
+	if err := next.Validate(); err != nil {
+		return next, fmt.Errorf("invalid %s parameters: %v", s.name, err)
+	}
+
+	return next, nil
+}
+
+// ParamChange sets a single parameter
+type ParamChange struct {
+	Subspace string
+	Key      string
+	Value    json.RawMessage
+}
+
+// ParameterChangeProposal is the content of a parameter change proposal
+type ParameterChangeProposal struct {
+	Changes         []ParamChange
+	EffectiveHeight uint64 // Zero applies the changes at the next epoch
+}
+
+// ScheduledChange is a set of parameter changes waiting for its height
+type ScheduledChange struct {
+	ProposalID uint64
+	Height     uint64
+	Changes    []ParamChange
+}
+
+// ParamsManager is the on-chain parameter store. It owns the chain
+// parameters and routes changes to the subspaces registered by modules.
+type ParamsManager struct {
+	chainParams types.ChainParams
+	subspaces   map[string]Subspace
+	pending     []ScheduledChange
+	logger      types.Logger
+}
+
+// NewParamsManager creates a parameter store holding the given chain parameters
+func NewParamsManager(chainParams types.ChainParams) *ParamsManager {
+	pm := &ParamsManager{
+		chainParams: chainParams,
+		subspaces:   make(map[string]Subspace),
+	}
+	pm.RegisterSubspace(NewSubspace(ChainSubspace, pm.GetChainParams, pm.SetChainParams))
+	return pm
+}
+
+// SetLogger sets the logger the store reports parameter changes to
+func (pm *ParamsManager) SetLogger(logger types.Logger) {
+	pm.logger = logger
+}
+
+// RegisterSubspace makes a module's parameters changeable through the store
+func (pm *ParamsManager) RegisterSubspace(subspace Subspace) {
+	pm.subspaces[subspace.Name()] = subspace
+}
+
+// GetChainParams returns the current chain parameters
+func (pm *ParamsManager) GetChainParams() types.ChainParams {
+	return pm.chainParams
+}
+
+// SetChainParams updates the chain parameters
+func (pm *ParamsManager) SetChainParams(params types.ChainParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	pm.chainParams = params
+	return nil
+}
+
+// GetSubspaces returns the names of the registered subspaces in order
+func (pm *ParamsManager) GetSubspaces() []string {
+	names := make([]string, 0, len(pm.subspaces))
+	for name := range pm.subspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetSubspaceParams returns a subspace's current parameters as JSON
+func (pm *ParamsManager) GetSubspaceParams(name string) (json.RawMessage, error) {
+	subspace, exists := pm.subspaces[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown parameter subspace: %s", name)
+	}
+	return subspace.Params()
+}
+
+// ValidateChanges checks that changes refer to known parameters and that
+// the resulting parameters are valid
+func (pm *ParamsManager) ValidateChanges(changes []ParamChange) error {
+	if len(changes) == 0 {
+		return fmt.Errorf("no parameter changes")
+	}
+
+	grouped, err := pm.groupChanges(changes)
+	if err != nil {
+		return err
+	}
+
+	for name, values := range grouped {
+		if err := pm.subspaces[name].Validate(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyChanges validates and applies changes. Changes to the same subspace
+// are applied together so related values can be changed in one step.
+func (pm *ParamsManager) ApplyChanges(changes []ParamChange) error {
+	if err := pm.ValidateChanges(changes); err != nil {
+		return err
+	}
+
+	grouped, _ := pm.groupChanges(changes)
+	for _, name := range pm.GetSubspaces() {
+		values, exists := grouped[name]
+		if !exists {
+			continue
+		}
+		if err := pm.subspaces[name].Update(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScheduleChanges validates changes and queues them to be applied at the
+// start of a block. A zero height schedules them for the next epoch.
+func (pm *ParamsManager) ScheduleChanges(ctx types.Context, proposalID uint64, height uint64, changes []ParamChange) (uint64, error) {
+	if err := pm.ValidateChanges(changes); err != nil {
+		return 0, err
+	}
+
+	if height == 0 {
+		height = ctx.Params.CalculateNextEpochHeight(ctx.Height)
+	} else if height <= ctx.Height {
+		return 0, fmt.Errorf("effective height %d must be after the current height %d", height, ctx.Height)
+	}
+
+	pm.pending = append(pm.pending, ScheduledChange{
+		ProposalID: proposalID,
+		Height:     height,
+		Changes:    changes,
+	})
+	sort.SliceStable(pm.pending, func(i, j int) bool {
+		return pm.pending[i].Height < pm.pending[j].Height
+	})
+
+	return height, nil
+}
+
+// GetPendingChanges returns the scheduled changes ordered by height
+func (pm *ParamsManager) GetPendingChanges() []ScheduledChange {
+	pending := make([]ScheduledChange, len(pm.pending))
+	copy(pending, pm.pending)
+	return pending
+}
+
+// HandleParameterChangeProposal schedules the changes of a passed parameter
+// change proposal. It is registered as the governance proposal handler.
+func (pm *ParamsManager) HandleParameterChangeProposal(ctx types.Context, proposal types.GovernanceProposal) error {
+	var content ParameterChangeProposal
+	if err := json.Unmarshal(proposal.Content, &content); err != nil {
+		return fmt.Errorf("invalid parameter change proposal: %v", err)
+	}
+
+	height, err := pm.ScheduleChanges(ctx, proposal.ID, content.EffectiveHeight, content.Changes)
+	if err != nil {
+		return err
+	}
+
+	pm.logger.Infof("Proposal %d scheduled %d parameter change(s) at height %d", proposal.ID, len(content.Changes), height)
+	return nil
+}
+
+// ProcessBlockStart applies the changes scheduled up to the current height.
+// Changes that are no longer valid, for example because another change
+// landed first, are dropped without halting the block.
+func (pm *ParamsManager) ProcessBlockStart(ctx types.Context) error {
+	remaining := pm.pending[:0]
+
+	for _, scheduled := range pm.pending {
+		if scheduled.Height > ctx.Height {
+			remaining = append(remaining, scheduled)
+			continue
+		}
+
+		if err := pm.ApplyChanges(scheduled.Changes); err != nil {
+			pm.logger.Errorf("Dropped parameter changes of proposal %d: %v", scheduled.ProposalID, err)
+			continue
+		}
+		pm.logger.Infof("Applied parameter changes of proposal %d at height %d", scheduled.ProposalID, ctx.Height)
+	}
+
+	pm.pending = remaining
+	return nil
+}
+
+// groupChanges collects the values of each subspace, rejecting unknown subspaces
+func (pm *ParamsManager) groupChanges(changes []ParamChange) (map[string]map[string]json.RawMessage, error) {
+	grouped := make(map[string]map[string]json.RawMessage)
+
+	for _, change := range changes {
+		if _, exists := pm.subspaces[change.Subspace]; !exists {
+			return nil, fmt.Errorf("unknown parameter subspace: %s", change.Subspace)
+		}
+		if grouped[change.Subspace] == nil {
+			grouped[change.Subspace] = make(map[string]json.RawMessage)
+		}
+		grouped[change.Subspace][change.Key] = change.Value
+	}
+
+	return grouped, nil
+}
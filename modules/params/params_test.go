@@ -0,0 +1,139 @@
+package params
+
+import (
+	"encoding/json"
+	"testing"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/mint"
+	"undergroundempire/modules/validator"
+)
+
+func newTestStore(t *testing.T) (*ParamsManager, *validator.ValidatorManager, *mint.MintManager) {
+	t.Helper()
+
+	pm := NewParamsManager(types.DefaultChainParams())
+	vm := validator.NewValidatorManager()
+	mm := mint.NewMintManager(nil, nil)
+	pm.RegisterSubspace(NewSubspace("validator", vm.GetParams, vm.SetParams))
+	pm.RegisterSubspace(NewSubspace("mint", mm.GetParams, mm.SetParams))
+	return pm, vm, mm
+}
+
+func TestChainParamsValidation(t *testing.T) {
+	if err := types.DefaultChainParams().Validate(); err != nil {
+		t.Fatalf("default chain params are invalid: %v", err)
+	}
+
+	pm, _, _ := newTestStore(t)
+	unsafe := types.DefaultChainParams()
+	unsafe.ConsensusThreshold = 50
+	if err := pm.SetChainParams(unsafe); err == nil {
+		t.Fatal("expected a threshold below two thirds to be rejected")
+	}
+
+	err := pm.ApplyChanges([]ParamChange{{Subspace: ChainSubspace, Key: "EpochDuration", Value: json.RawMessage("0")}})
+	if err == nil {
+		t.Fatal("expected a zero epoch duration to be rejected")
+	}
+	if pm.GetChainParams().EpochDuration != types.EpochDuration {
+		t.Fatal("rejected change must not be applied")
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	pm, vm, mm := newTestStore(t)
+
+	err := pm.ApplyChanges([]ParamChange{
+		{Subspace: ChainSubspace, Key: "MinValidatorStake", Value: json.RawMessage("50000")},
+		{Subspace: "validator", Key: "MaxValidators", Value: json.RawMessage("21")},
+	})
+	if err != nil {
+		t.Fatalf("failed to apply changes: %v", err)
+	}
+	if !pm.GetChainParams().MinValidatorStake.Equal(types.NewInt(50000)) {
+		t.Errorf("expected min validator stake 50000, got %s", pm.GetChainParams().MinValidatorStake)
+	}
+	if vm.GetParams().MaxValidators != 21 {
+		t.Errorf("expected 21 max validators, got %d", vm.GetParams().MaxValidators)
+	}
+
+	// Related values are validated together
+	err = pm.ApplyChanges([]ParamChange{
+		{Subspace: "mint", Key: "InflationMax", Value: json.RawMessage(`"0.05"`)},
+		{Subspace: "mint", Key: "InflationMin", Value: json.RawMessage(`"0.03"`)},
+	})
+	if err != nil {
+		t.Fatalf("failed to apply related changes: %v", err)
+	}
+	if !mm.GetParams().InflationMax.Equal(types.MustParseDec("0.05")) || !mm.GetParams().InflationMin.Equal(types.MustParseDec("0.03")) {
+		t.Errorf("unexpected mint params: %+v", mm.GetParams())
+	}
+
+	invalid := [][]ParamChange{
+		{{Subspace: "mint", Key: "InflationMin", Value: json.RawMessage(`"0.09"`)}},
+		{{Subspace: "mint", Key: "NoSuchKey", Value: json.RawMessage("1")}},
+		{{Subspace: "validator", Key: "MaxValidators", Value: json.RawMessage(`"many"`)}},
+		{{Subspace: "nosuchmodule", Key: "Key", Value: json.RawMessage("1")}},
+	}
+	for _, changes := range invalid {
+		if err := pm.ApplyChanges(changes); err == nil {
+			t.Errorf("expected changes %+v to be rejected", changes)
+		}
+	}
+}
+
+func TestParameterChangeProposal(t *testing.T) {
+	pm, _, _ := newTestStore(t)
+	ctx := types.Context{Height: 10, Params: types.DefaultChainParams()}
+
+	content, _ := json.Marshal(ParameterChangeProposal{
+		Changes: []ParamChange{{Subspace: ChainSubspace, Key: "BlockTime", Value: json.RawMessage("3")}},
+	})
+	proposal := types.GovernanceProposal{ID: 1, Type: types.ProposalTypeParameterChange, Content: content}
+	if err := pm.HandleParameterChangeProposal(ctx, proposal); err != nil {
+		t.Fatalf("failed to handle proposal: %v", err)
+	}
+
+	pending := pm.GetPendingChanges()
+	if len(pending) != 1 || pending[0].Height != types.EpochDuration {
+		t.Fatalf("expected the change to be scheduled at the next epoch, got %+v", pending)
+	}
+
+	pm.ProcessBlockStart(ctx.WithHeight(types.EpochDuration - 1))
+	if pm.GetChainParams().BlockTime != types.BlockTime {
+		t.Fatal("change applied before its height")
+	}
+
+	pm.ProcessBlockStart(ctx.WithHeight(types.EpochDuration))
+	if pm.GetChainParams().BlockTime != 3 {
+		t.Fatalf("expected block time 3, got %d", pm.GetChainParams().BlockTime)
+	}
+	if len(pm.GetPendingChanges()) != 0 {
+		t.Fatal("applied change still pending")
+	}
+
+	// An explicit height must be in the future
+	content, _ = json.Marshal(ParameterChangeProposal{
+		Changes:         []ParamChange{{Subspace: ChainSubspace, Key: "BlockTime", Value: json.RawMessage("4")}},
+		EffectiveHeight: 5,
+	})
+	proposal = types.GovernanceProposal{ID: 2, Type: types.ProposalTypeParameterChange, Content: content}
+	if err := pm.HandleParameterChangeProposal(ctx, proposal); err == nil {
+		t.Fatal("expected a past effective height to be rejected")
+	}
+}
+
+func TestContextChainParams(t *testing.T) {
+	ctx := types.Context{Height: 150, Params: types.DefaultChainParams()}
+	if ctx.Params.EpochDuration != types.EpochDuration || !ctx.Params.MinValidatorStake.Equal(types.DefaultChainParams().MinValidatorStake) {
+		t.Fatal("expected default chain params on an empty context")
+	}
+
+	params := types.DefaultChainParams()
+	params.EpochDuration = 50
+	ctx = ctx.WithChainParams(params)
+	if !ctx.Params.IsEpochBoundary(ctx.Height) {
+		t.Fatal("expected height 150 to be an epoch boundary with 50-block epochs")
+	}
+}
@@ -0,0 +1,326 @@
+package tokenfactory
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"undergroundempire/core/types"
+)
+
+// DenomPrefix is the first component of every token factory denom. Denoms
+// are namespaced by their creator: factory/<creator>/<subdenom>.
+const DenomPrefix = "factory"
+
+// Message types accepted by the token factory
+const (
+	MsgTypeCreateDenom = "create_denom"
+	MsgTypeMint        = "mint"
+	MsgTypeBurn        = "burn"
+	MsgTypeChangeAdmin = "change_admin"
+	MsgTypeSetMetadata = "set_metadata"
+)
+
+// subdenomRegex matches the creator-chosen part of a denom
+var subdenomRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,44}$`)
+
+// MsgCreateDenom creates factory/<sender>/<subdenom> with the sender as admin
+type MsgCreateDenom struct {
+	Subdenom string
+}
+
+// MsgMint mints tokens of a denom to a recipient, or to the admin if empty
+type MsgMint struct {
+	Amount    types.CoinAmount
+	Recipient types.Address
+}
+
+// MsgBurn burns tokens of a denom held by the admin
+type MsgBurn struct {
+	Amount types.CoinAmount
+}
+
+// MsgChangeAdmin hands a denom to a new admin. A zero admin renounces
+// control, fixing the supply and metadata for good.
+type MsgChangeAdmin struct {
+	Denom    string
+	NewAdmin types.Address
+}
+
+// MsgSetMetadata replaces the metadata of a denom
+type MsgSetMetadata struct {
+	Metadata types.Metadata
+}
+
+// Params holds the token factory parameters
+type Params struct {
+	DenomCreationFee types.CoinAmount // Paid to the community pool for each new denom
+}
+
+// DefaultParams returns the default token factory parameters
+func DefaultParams() Params {
+	return Params{
+		DenomCreationFee: types.NewUECoins(types.NewUEAmount(100)),
+	}
+}
+
+// Validate checks that the parameters are well formed
+func (p Params) Validate() error {
+	if err := types.ValidateDenom(p.DenomCreationFee.Denom); err != nil {
+		return fmt.Errorf("invalid denom creation fee: %v", err)
+	}
+	if p.DenomCreationFee.Amount.IsNegative() {
+		return fmt.Errorf("denom creation fee cannot be negative")
+	}
+	return nil
+}
+
+// DenomInfo describes a denom created through the token factory
+type DenomInfo struct {
+	Denom    string
+	Creator  types.Address
+	Admin    types.Address // Zero once control is renounced
+	Metadata types.Metadata
+}
+
+// BankKeeper mints, burns and moves factory tokens
+type BankKeeper interface {
+	Transfer(ctx types.Context, from, to types.Address, amount types.CoinAmount) error
+	MintTokens(ctx types.Context, to types.Address, amount types.CoinAmount) error
+	BurnTokens(ctx types.Context, from types.Address, amount types.CoinAmount) error
+}
+
+// TokenFactoryManager lets accounts create and administer their own tokens.
+// Balances live in the treasury, so factory tokens use the same transfer
+// path and balance queries as native UE.
+type TokenFactoryManager struct {
+	params Params
+	denoms map[string]DenomInfo
+	bank   BankKeeper
+	logger types.Logger
+}
+
+// NewTokenFactoryManager creates a new token factory manager
+func NewTokenFactoryManager(bank BankKeeper) *TokenFactoryManager {
+	return &TokenFactoryManager{
+		params: DefaultParams(),
+		denoms: make(map[string]DenomInfo),
+		bank:   bank,
+	}
+}
+
+// SetLogger sets the logger the token factory reports to
+func (tf *TokenFactoryManager) SetLogger(logger types.Logger) {
+	tf.logger = logger
+}
+
+// GetParams returns the current token factory parameters
+func (tf *TokenFactoryManager) GetParams() Params {
+	return tf.params
+}
+
+// SetParams updates the token factory parameters
+func (tf *TokenFactoryManager) SetParams(params Params) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	tf.params = params
+	return nil
+}
+
+// BuildDenom returns the denom a creator gets for a subdenom
+func BuildDenom(creator types.Address, subdenom string) (string, error) {
+	if creator.IsZero() {
+		return "", fmt.Errorf("creator address cannot be zero")
+	}
+	if !subdenomRegex.MatchString(subdenom) {
+		return "", fmt.Errorf("invalid subdenom: %q", subdenom)
+	}
+
+	denom := strings.Join([]string{DenomPrefix, creator.String(), subdenom}, "/")
+	if err := types.ValidateDenom(denom); err != nil {
+		return "", err
+	}
+	return denom, nil
+}
+
+// ParseDenom splits a factory denom into its creator and subdenom
+func ParseDenom(denom string) (types.Address, string, error) {
+	parts := strings.Split(denom, "/")
+	if len(parts) != 3 || parts[0] != DenomPrefix {
+		return types.Address{}, "", fmt.Errorf("%s is not a token factory denom", denom)
+	}
+
+	creator, err := types.NewAddress(parts[1])
+	if err != nil {
+		return types.Address{}, "", fmt.Errorf("invalid creator in denom %s: %v", denom, err)
+	}
+	if !subdenomRegex.MatchString(parts[2]) {
+		return types.Address{}, "", fmt.Errorf("invalid subdenom in denom %s", denom)
+	}
+	return creator, parts[2], nil
+}
+
+// CreateDenom creates a new denom owned by the creator and charges the
+// denom creation fee to the community pool
+func (tf *TokenFactoryManager) CreateDenom(ctx types.Context, creator types.Address, subdenom string) (string, error) {
+	denom, err := BuildDenom(creator, subdenom)
+	if err != nil {
+		return "", err
+	}
+
+	if _, exists := tf.denoms[denom]; exists {
+		return "", fmt.Errorf("denom %s already exists", denom)
+	}
+
+	if fee := tf.params.DenomCreationFee; fee.IsPositive() {
+		if err := tf.bank.Transfer(ctx, creator, types.ModuleAddress(types.CommunityPoolName), fee); err != nil {
+			return "", fmt.Errorf("failed to pay denom creation fee: %v", err)
+		}
+	}
+
+	tf.denoms[denom] = DenomInfo{
+		Denom:   denom,
+		Creator: creator,
+		Admin:   creator,
+		Metadata: types.Metadata{
+			Base:       denom,
+			Display:    denom,
+			Name:       subdenom,
+			Symbol:     subdenom,
+			DenomUnits: []types.DenomUnit{{Denom: denom, Exponent: 0}},
+		},
+	}
+
+	tf.logger.Infof("%s created %s", creator, denom)
+	return denom, nil
+}
+
+// Mint creates tokens of a denom and credits them to a recipient, or to the
+// admin if the recipient is empty. Only the admin can mint.
+func (tf *TokenFactoryManager) Mint(ctx types.Context, sender types.Address, amount types.CoinAmount, recipient types.Address) error {
+	if _, err := tf.requireAdmin(amount.Denom, sender); err != nil {
+		return err
+	}
+
+	if recipient.IsZero() {
+		recipient = sender
+	}
+	return tf.bank.MintTokens(ctx, recipient, amount)
+}
+
+// Burn destroys tokens of a denom held by the admin. Only the admin can burn.
+func (tf *TokenFactoryManager) Burn(ctx types.Context, sender types.Address, amount types.CoinAmount) error {
+	if _, err := tf.requireAdmin(amount.Denom, sender); err != nil {
+		return err
+	}
+	return tf.bank.BurnTokens(ctx, sender, amount)
+}
+
+// ChangeAdmin hands control of a denom to a new admin
+func (tf *TokenFactoryManager) ChangeAdmin(ctx types.Context, sender types.Address, denom string, newAdmin types.Address) error {
+	info, err := tf.requireAdmin(denom, sender)
+	if err != nil {
+		return err
+	}
+
+	info.Admin = newAdmin
+	tf.denoms[denom] = info
+	return nil
+}
+
+// SetMetadata replaces the metadata of a denom. The metadata must describe
+// the denom as its base unit.
+func (tf *TokenFactoryManager) SetMetadata(ctx types.Context, sender types.Address, metadata types.Metadata) error {
+	info, err := tf.requireAdmin(metadata.Base, sender)
+	if err != nil {
+		return err
+	}
+
+	if err := metadata.Validate(); err != nil {
+		return fmt.Errorf("invalid metadata: %v", err)
+	}
+
+	info.Metadata = metadata
+	tf.denoms[metadata.Base] = info
+	return nil
+}
+
+// GetDenom returns a factory denom
+func (tf *TokenFactoryManager) GetDenom(ctx types.Context, denom string) (DenomInfo, error) {
+	info, exists := tf.denoms[denom]
+	if !exists {
+		return DenomInfo{}, fmt.Errorf("denom %s not found", denom)
+	}
+	return info, nil
+}
+
+// GetDenomsFromCreator returns the denoms an account created, in order
+func (tf *TokenFactoryManager) GetDenomsFromCreator(ctx types.Context, creator types.Address) []string {
+	var denoms []string
+	for denom, info := range tf.denoms {
+		if info.Creator == creator {
+			denoms = append(denoms, denom)
+		}
+	}
+
+	sort.Strings(denoms)
+	return denoms
+}
+
+// requireAdmin returns a denom if the sender administers it
+func (tf *TokenFactoryManager) requireAdmin(denom string, sender types.Address) (DenomInfo, error) {
+	info, exists := tf.denoms[denom]
+	if !exists {
+		return DenomInfo{}, fmt.Errorf("denom %s not found", denom)
+	}
+	if info.Admin.IsZero() || info.Admin != sender {
+		return DenomInfo{}, fmt.Errorf("%s is not the admin of %s", sender, denom)
+	}
+	return info, nil
+}
+
+// HandleMsg executes a token factory message sent by an account
+func (tf *TokenFactoryManager) HandleMsg(ctx types.Context, sender types.Address, msg types.ModuleMsg) error {
+	switch msg.Type {
+	case MsgTypeCreateDenom:
+		var content MsgCreateDenom
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		_, err := tf.CreateDenom(ctx, sender, content.Subdenom)
+		return err
+
+	case MsgTypeMint:
+		var content MsgMint
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		return tf.Mint(ctx, sender, content.Amount, content.Recipient)
+
+	case MsgTypeBurn:
+		var content MsgBurn
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		return tf.Burn(ctx, sender, content.Amount)
+
+	case MsgTypeChangeAdmin:
+		var content MsgChangeAdmin
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		return tf.ChangeAdmin(ctx, sender, content.Denom, content.NewAdmin)
+
+	case MsgTypeSetMetadata:
+		var content MsgSetMetadata
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		return tf.SetMetadata(ctx, sender, content.Metadata)
+
+	default:
+		return fmt.Errorf("unknown token factory message type: %s", msg.Type)
+	}
+}
@@ -0,0 +1,131 @@
+package tokenfactory
+
+import (
+	"testing"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/treasury"
+)
+
+var (
+	creator = types.Address{1}
+	holder  = types.Address{2}
+)
+
+func setup(t *testing.T) (*TokenFactoryManager, *treasury.TreasuryManager) {
+	t.Helper()
+
+	tm := treasury.NewTreasuryManager()
+	for _, address := range []types.Address{creator, holder} {
+		tm.MintTokens(types.Context{}, address, types.NewUECoins(types.NewUEAmount(1000)))
+	}
+	return NewTokenFactoryManager(tm), tm
+}
+
+// sendMsg executes a token factory message through a transaction
+func sendMsg(tf *TokenFactoryManager, from types.Address, msgType string, content interface{}) error {
+	data, err := types.NewModuleMsg(msgType, content)
+	if err != nil {
+		return err
+	}
+	tx := types.NewTransaction(from, types.ModuleAddress(types.TokenFactoryModuleName), types.CoinAmount{}, 21000, 1, data, 1)
+	// The test bank is the treasury, which also collects the fee
+	return types.ExecuteModuleTx(types.Context{}, tx, types.TokenFactoryModuleName, tf.bank.(types.FeeKeeper), tf.HandleMsg)
+}
+
+func TestCreateMintAndTransfer(t *testing.T) {
+	tf, tm := setup(t)
+	ctx := types.Context{}
+
+	if err := sendMsg(tf, creator, MsgTypeCreateDenom, MsgCreateDenom{Subdenom: "gold"}); err != nil {
+		t.Fatalf("create denom: %v", err)
+	}
+	denoms := tf.GetDenomsFromCreator(ctx, creator)
+	if len(denoms) != 1 || denoms[0] != "factory/"+creator.String()+"/gold" {
+		t.Fatalf("unexpected denoms: %v", denoms)
+	}
+	gold := denoms[0]
+
+	// The creation fee goes to the community pool
+	if got := tm.GetCommunityPool(ctx).Amount; !got.Equal(tf.GetParams().DenomCreationFee.Amount) {
+		t.Fatalf("community pool = %s, want the creation fee", got)
+	}
+	if err := sendMsg(tf, creator, MsgTypeCreateDenom, MsgCreateDenom{Subdenom: "gold"}); err == nil {
+		t.Fatal("expected a duplicate denom to be rejected")
+	}
+
+	// Only the admin mints
+	coins := types.NewCoinAmount(types.NewInt(500), gold)
+	if err := sendMsg(tf, holder, MsgTypeMint, MsgMint{Amount: coins}); err == nil {
+		t.Fatal("expected mint by a non-admin to be rejected")
+	}
+	if err := sendMsg(tf, creator, MsgTypeMint, MsgMint{Amount: coins}); err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+
+	// Factory tokens use the native transfer path
+	tx := types.NewTransaction(creator, holder, types.NewCoinAmount(types.NewInt(200), gold), 21000, 1, nil, 2)
+	if err := tm.ExecuteTransaction(ctx, tx); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if got := tm.GetDenomBalance(ctx, holder, gold).Amount; !got.Equal(types.NewInt(200)) {
+		t.Fatalf("holder balance = %s, want 200", got)
+	}
+
+	if err := sendMsg(tf, creator, MsgTypeBurn, MsgBurn{Amount: types.NewCoinAmount(types.NewInt(100), gold)}); err != nil {
+		t.Fatalf("burn: %v", err)
+	}
+	if got := tm.GetSupply(ctx, gold).Amount; !got.Equal(types.NewInt(400)) {
+		t.Fatalf("supply = %s, want 400", got)
+	}
+}
+
+func TestAdminAndMetadata(t *testing.T) {
+	tf, _ := setup(t)
+	ctx := types.Context{}
+
+	gold, err := tf.CreateDenom(ctx, creator, "gold")
+	if err != nil {
+		t.Fatalf("create denom: %v", err)
+	}
+
+	metadata := types.Metadata{
+		Base:    gold,
+		Display: "GOLD",
+		Name:    "Gold",
+		Symbol:  "GOLD",
+		DenomUnits: []types.DenomUnit{
+			{Denom: gold, Exponent: 0},
+			{Denom: "GOLD", Exponent: 6},
+		},
+	}
+	if err := sendMsg(tf, creator, MsgTypeSetMetadata, MsgSetMetadata{Metadata: metadata}); err != nil {
+		t.Fatalf("set metadata: %v", err)
+	}
+	info, _ := tf.GetDenom(ctx, gold)
+	if got := info.Metadata.FormatDisplay(types.NewCoinAmount(types.NewInt(1_500_000), gold)); got != "1.5GOLD" {
+		t.Fatalf("display = %s, want 1.5GOLD", got)
+	}
+
+	if err := tf.ChangeAdmin(ctx, creator, gold, holder); err != nil {
+		t.Fatalf("change admin: %v", err)
+	}
+	if err := tf.Mint(ctx, creator, types.NewCoinAmount(types.NewInt(1), gold), types.Address{}); err == nil {
+		t.Fatal("expected the previous admin to lose control")
+	}
+
+	// Renouncing fixes the supply for good
+	if err := tf.ChangeAdmin(ctx, holder, gold, types.Address{}); err != nil {
+		t.Fatalf("renounce: %v", err)
+	}
+	if err := tf.Mint(ctx, holder, types.NewCoinAmount(types.NewInt(1), gold), types.Address{}); err == nil {
+		t.Fatal("expected mint after renouncing to be rejected")
+	}
+
+	if _, _, err := ParseDenom("factory/" + creator.String() + "/gold"); err != nil {
+		t.Fatalf("parse denom: %v", err)
+	}
+	if _, err := BuildDenom(creator, "no spaces"); err == nil {
+		t.Fatal("expected an invalid subdenom to be rejected")
+	}
+}
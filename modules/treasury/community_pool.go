@@ -0,0 +1,79 @@
+package treasury
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"undergroundempire/core/types"
+)
+
+// CommunityPoolSpendProposal is the content of a community pool spend proposal
+type CommunityPoolSpendProposal struct {
+	Recipient types.Address
+	Amount    types.CoinAmount
+}
+
+// CommunityPoolSpend records a payout from the community pool
+type CommunityPoolSpend struct {
+	ProposalID uint64
+	Recipient  types.Address
+	Amount     types.CoinAmount
+	Height     uint64
+	Timestamp  time.Time
+}
+
+// GetCommunityPool returns the balance of the community pool. The pool is
+// funded by the community tax on fees and inflation and by donations.
+func (tm *TreasuryManager) GetCommunityPool(ctx types.Context) types.CoinAmount {
+	return tm.GetBalance(ctx, types.ModuleAddress(types.CommunityPoolName))
+}
+
+// FundCommunityPool donates tokens from an account to the community pool
+func (tm *TreasuryManager) FundCommunityPool(ctx types.Context, depositor types.Address, amount types.CoinAmount) error {
+	return tm.Transfer(ctx, depositor, types.ModuleAddress(types.CommunityPoolName), amount)
+}
+
+// SpendCommunityPool pays tokens from the community pool to a recipient and
+// records the spend
+func (tm *TreasuryManager) SpendCommunityPool(ctx types.Context, proposalID uint64, recipient types.Address, amount types.CoinAmount) error {
+	if recipient.IsZero() {
+		return fmt.Errorf("community pool spend recipient cannot be empty")
+	}
+
+	if err := tm.Transfer(ctx, types.ModuleAddress(types.CommunityPoolName), recipient, amount); err != nil {
+		return fmt.Errorf("insufficient community pool funds: %v", err)
+	}
+
+	tm.spends = append(tm.spends, CommunityPoolSpend{
+		ProposalID: proposalID,
+		Recipient:  recipient,
+		Amount:     amount,
+		Height:     ctx.Height,
+		Timestamp:  ctx.Timestamp,
+	})
+	return nil
+}
+
+// GetCommunityPoolSpends returns the community pool spends in the order they were paid
+func (tm *TreasuryManager) GetCommunityPoolSpends(ctx types.Context) []CommunityPoolSpend {
+	spends := make([]CommunityPoolSpend, len(tm.spends))
+	copy(spends, tm.spends)
+	return spends
+}
+
+// HandleCommunityPoolSpendProposal pays out a passed community pool spend
+// proposal. It is registered as the governance proposal handler.
+func (tm *TreasuryManager) HandleCommunityPoolSpendProposal(ctx types.Context, proposal types.GovernanceProposal) error {
+	var content CommunityPoolSpendProposal
+	if err := json.Unmarshal(proposal.Content, &content); err != nil {
+		return fmt.Errorf("invalid community pool spend proposal: %v", err)
+	}
+
+	if err := tm.SpendCommunityPool(ctx, proposal.ID, content.Recipient, content.Amount); err != nil {
+		return err
+	}
+
+	tm.logger.Infof("Proposal %d paid %s from the community pool to %s", proposal.ID, content.Amount, content.Recipient)
+	return nil
+}
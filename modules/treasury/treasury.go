@@ -0,0 +1,181 @@
+package treasury
+
+import (
+	"fmt"
+
+	"undergroundempire/core/types"
+)
+
+// TransfersRoute is the circuit breaker route guarding user transfers
+const TransfersRoute = "transfers"
+
+// CircuitKeeper reports routes paused by governance
+type CircuitKeeper interface {
+	IsPaused(route string) bool
+}
+
+// TreasuryManager tracks account balances and the total token supply
+type TreasuryManager struct {
+	balances map[types.Address]types.Coins
+	supply   types.Coins
+	burned   types.Coins
+	spends   []CommunityPoolSpend
+	circuit  CircuitKeeper
+	logger   types.Logger
+}
+
+// NewTreasuryManager creates a new treasury manager
+func NewTreasuryManager() *TreasuryManager {
+	return &TreasuryManager{
+		balances: make(map[types.Address]types.Coins),
+		supply:   types.Coins{},
+		burned:   types.Coins{},
+	}
+}
+
+// SetCircuitKeeper sets the circuit breaker consulted before user transfers
+func (tm *TreasuryManager) SetCircuitKeeper(circuit CircuitKeeper) {
+	tm.circuit = circuit
+}
+
+// SetLogger sets the logger the treasury reports to
+func (tm *TreasuryManager) SetLogger(logger types.Logger) {
+	tm.logger = logger
+}
+
+// GetBalance returns the balance of an address in the native denomination
+func (tm *TreasuryManager) GetBalance(ctx types.Context, address types.Address) types.CoinAmount {
+	return tm.GetDenomBalance(ctx, address, types.NativeDenom)
+}
+
+// GetDenomBalance returns the balance of an address in the given denomination
+func (tm *TreasuryManager) GetDenomBalance(ctx types.Context, address types.Address, denom string) types.CoinAmount {
+	return types.NewCoinAmount(tm.balances[address].AmountOf(denom), denom)
+}
+
+// GetAllBalances returns the balances of an address in every denomination
+func (tm *TreasuryManager) GetAllBalances(ctx types.Context, address types.Address) types.Coins {
+	return append(types.Coins{}, tm.balances[address]...)
+}
+
+// GetSupply returns the total supply of a denomination
+func (tm *TreasuryManager) GetSupply(ctx types.Context, denom string) types.CoinAmount {
+	return types.NewCoinAmount(tm.supply.AmountOf(denom), denom)
+}
+
+// GetTotalSupply returns the total supply of every denomination
+func (tm *TreasuryManager) GetTotalSupply(ctx types.Context) types.Coins {
+	return append(types.Coins{}, tm.supply...)
+}
+
+// GetBurned returns the total amount of a denomination burned so far
+func (tm *TreasuryManager) GetBurned(ctx types.Context, denom string) types.CoinAmount {
+	return types.NewCoinAmount(tm.burned.AmountOf(denom), denom)
+}
+
+// Transfer moves tokens between two accounts
+func (tm *TreasuryManager) Transfer(ctx types.Context, from, to types.Address, amount types.CoinAmount) error {
+	if !amount.IsPositive() {
+		return fmt.Errorf("transfer amount must be positive")
+	}
+
+	return tm.SendCoins(ctx, from, to, types.Coins{amount})
+}
+
+// SendCoins moves tokens of one or more denominations between two accounts
+func (tm *TreasuryManager) SendCoins(ctx types.Context, from, to types.Address, coins types.Coins) error {
+	if err := coins.Validate(); err != nil {
+		return err
+	}
+
+	balance, err := tm.balances[from].Sub(coins)
+	if err != nil {
+		return err
+	}
+	received, err := tm.balances[to].SafeAdd(coins)
+	if err != nil {
+		return err
+	}
+
+	tm.balances[from] = balance
+	tm.balances[to] = received
+	return nil
+}
+
+// MintTokens creates new tokens and credits them to an account
+func (tm *TreasuryManager) MintTokens(ctx types.Context, to types.Address, amount types.CoinAmount) error {
+	if !amount.IsPositive() {
+		return fmt.Errorf("mint amount must be positive")
+	}
+
+	coins := types.Coins{amount}
+	if err := coins.Validate(); err != nil {
+		return err
+	}
+
+	// Balances never exceed the supply, so checking the supply suffices
+	supply, err := tm.supply.SafeAdd(coins)
+	if err != nil {
+		return fmt.Errorf("cannot mint %s: %v", amount, err)
+	}
+
+	tm.supply = supply
+	tm.balances[to] = tm.balances[to].Add(coins)
+	return nil
+}
+
+// BurnTokens destroys tokens held by an account
+func (tm *TreasuryManager) BurnTokens(ctx types.Context, from types.Address, amount types.CoinAmount) error {
+	if !amount.IsPositive() {
+		return fmt.Errorf("burn amount must be positive")
+	}
+
+	coins := types.Coins{amount}
+	balance, err := tm.balances[from].Sub(coins)
+	if err != nil {
+		return err
+	}
+	supply, err := tm.supply.Sub(coins)
+	if err != nil {
+		return fmt.Errorf("cannot burn %s: %v", amount, err)
+	}
+
+	tm.balances[from] = balance
+	tm.supply = supply
+	tm.burned = tm.burned.Add(coins)
+	return nil
+}
+
+// DeductFees charges the transaction's gas cost to the sender and moves it
+// to the fee collector, where it is distributed at the end of the block
+func (tm *TreasuryManager) DeductFees(ctx types.Context, tx types.Transaction) (types.Coins, error) {
+	fee := tx.Fee()
+	if fee.IsZero() {
+		return fee, nil
+	}
+
+	if err := tm.SendCoins(ctx, tx.From, types.ModuleAddress(types.FeeCollectorName), fee); err != nil {
+		return nil, fmt.Errorf("failed to pay fee of %s: %v", fee, err)
+	}
+
+	return fee, nil
+}
+
+// ExecuteTransaction validates a transfer, collects its fee and moves the
+// amount. The fee is kept even if the transfer itself fails. Transactions
+// are rejected without a fee while transfers are paused.
+func (tm *TreasuryManager) ExecuteTransaction(ctx types.Context, tx types.Transaction) error {
+	if err := tx.Validate(); err != nil {
+		return err
+	}
+
+	if tm.circuit != nil && tm.circuit.IsPaused(TransfersRoute) {
+		return fmt.Errorf("transfers are paused by governance")
+	}
+
+	if _, err := tm.DeductFees(ctx, tx); err != nil {
+		return err
+	}
+
+	return tm.Transfer(ctx, tx.From, tx.To, tx.Amount)
+}
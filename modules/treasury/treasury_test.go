@@ -0,0 +1,107 @@
+package treasury
+
+import (
+	"encoding/json"
+	"testing"
+
+	"undergroundempire/core/types"
+)
+
+func TestExecuteTransaction_CollectsFees(t *testing.T) {
+	ctx := types.Context{}
+	tm := NewTreasuryManager()
+
+	from := types.Address{1}
+	to := types.Address{2}
+	tm.MintTokens(ctx, from, types.NewUECoins(types.NewInt(1_000_000)))
+
+	tx := types.NewTransaction(from, to, types.NewUECoins(types.NewInt(1000)), 21000, 10, nil, 1)
+	if err := tm.ExecuteTransaction(ctx, tx); err != nil {
+		t.Fatalf("execute transaction: %v", err)
+	}
+
+	if got := tm.GetBalance(ctx, types.ModuleAddress(types.FeeCollectorName)).Amount; !got.Equal(types.NewInt(210000)) {
+		t.Errorf("fee collector balance = %s, want 210000", got)
+	}
+	if got := tm.GetBalance(ctx, from).Amount; !got.Equal(types.NewInt(1_000_000 - 210000 - 1000)) {
+		t.Errorf("sender balance = %s, want %d", got, 1_000_000-210000-1000)
+	}
+	if got := tm.GetBalance(ctx, to).Amount; !got.Equal(types.NewInt(1000)) {
+		t.Errorf("recipient balance = %s, want 1000", got)
+	}
+}
+
+func TestDeductFees_InsufficientBalance(t *testing.T) {
+	ctx := types.Context{}
+	tm := NewTreasuryManager()
+
+	from := types.Address{1}
+	tm.MintTokens(ctx, from, types.NewUECoins(types.NewInt(100)))
+
+	tx := types.NewTransaction(from, types.Address{2}, types.NewUECoins(types.NewInt(1)), 21000, 1, nil, 1)
+	if _, err := tm.DeductFees(ctx, tx); err == nil {
+		t.Fatal("expected fee deduction to fail")
+	}
+	if got := tm.GetBalance(ctx, from).Amount; !got.Equal(types.NewInt(100)) {
+		t.Errorf("sender balance = %s, want 100", got)
+	}
+}
+
+func TestBurnTokens_SupplyMismatch(t *testing.T) {
+	ctx := types.Context{}
+	tm := NewTreasuryManager()
+
+	from := types.Address{1}
+	tm.MintTokens(ctx, from, types.NewUECoins(types.NewInt(100)))
+	tm.supply = types.Coins{types.NewUECoins(types.NewInt(10))}
+
+	if err := tm.BurnTokens(ctx, from, types.NewUECoins(types.NewInt(50))); err == nil {
+		t.Fatal("expected burning more than the supply to fail")
+	}
+	if got := tm.GetBalance(ctx, from).Amount; !got.Equal(types.NewInt(100)) {
+		t.Errorf("balance = %s, want 100", got)
+	}
+	if got := tm.supply.AmountOf(types.NativeDenom); !got.Equal(types.NewInt(10)) {
+		t.Errorf("supply = %s, want 10", got)
+	}
+}
+
+func TestCommunityPoolSpendProposal(t *testing.T) {
+	ctx := types.Context{Height: 42}
+	tm := NewTreasuryManager()
+
+	donor := types.Address{1}
+	recipient := types.Address{9}
+	tm.MintTokens(ctx, donor, types.NewUECoins(types.NewInt(10000)))
+	if err := tm.FundCommunityPool(ctx, donor, types.NewUECoins(types.NewInt(6000))); err != nil {
+		t.Fatalf("fund community pool: %v", err)
+	}
+
+	content, _ := json.Marshal(CommunityPoolSpendProposal{Recipient: recipient, Amount: types.NewUECoins(types.NewInt(2500))})
+	proposal := types.GovernanceProposal{ID: 7, Type: types.ProposalTypeCommunitySpend, Content: content}
+	if err := tm.HandleCommunityPoolSpendProposal(ctx, proposal); err != nil {
+		t.Fatalf("spend: %v", err)
+	}
+
+	if got := tm.GetCommunityPool(ctx).Amount; !got.Equal(types.NewInt(3500)) {
+		t.Errorf("community pool = %s, want 3500", got)
+	}
+	if got := tm.GetBalance(ctx, recipient).Amount; !got.Equal(types.NewInt(2500)) {
+		t.Errorf("recipient balance = %s, want 2500", got)
+	}
+
+	spends := tm.GetCommunityPoolSpends(ctx)
+	if len(spends) != 1 || spends[0].ProposalID != 7 || spends[0].Recipient != recipient || spends[0].Height != 42 {
+		t.Fatalf("unexpected spend history: %+v", spends)
+	}
+
+	// Spending more than the pool holds fails without recording a spend
+	content, _ = json.Marshal(CommunityPoolSpendProposal{Recipient: recipient, Amount: types.NewUECoins(types.NewInt(5000))})
+	proposal = types.GovernanceProposal{ID: 8, Type: types.ProposalTypeCommunitySpend, Content: content}
+	if err := tm.HandleCommunityPoolSpendProposal(ctx, proposal); err == nil {
+		t.Fatal("expected overspend to fail")
+	}
+	if len(tm.GetCommunityPoolSpends(ctx)) != 1 {
+		t.Fatal("failed spend must not be recorded")
+	}
+}
@@ -0,0 +1,208 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"undergroundempire/core/types"
+)
+
+// UpgradeInfoFile is the file, inside the node's data directory, where a
+// halted node records the upgrade it is waiting for
+const UpgradeInfoFile = "upgrade-info.json"
+
+// Plan describes a coordinated software upgrade
+type Plan struct {
+	Name   string // Name of the upgrade handler the new binary registers
+	Height uint64 // Height at which the chain halts for the upgrade
+	Info   string // Free-form details, such as where to download the binary
+}
+
+// Validate checks that the plan is well formed
+func (p Plan) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("upgrade name cannot be empty")
+	}
+	if p.Height == 0 {
+		return fmt.Errorf("upgrade height must be positive")
+	}
+	return nil
+}
+
+// UpgradeHandler runs the state migrations of a named upgrade
+type UpgradeHandler func(ctx types.Context, plan Plan) error
+
+// UpgradeNeededError is returned when the chain reaches the height of an
+// upgrade the running binary does not know. The node must halt and be
+// restarted with a binary that registers the upgrade.
+type UpgradeNeededError struct {
+	Plan Plan
+}
+
+func (e UpgradeNeededError) Error() string {
+	return fmt.Sprintf("UPGRADE %q NEEDED at height %d: %s", e.Plan.Name, e.Plan.Height, e.Plan.Info)
+}
+
+// UpgradeManager schedules software upgrades and runs their migrations
+type UpgradeManager struct {
+	plan      *Plan
+	handlers  map[string]UpgradeHandler
+	done      map[string]uint64 // Applied upgrades and the height they ran at
+	infoPath  string
+	replaying bool
+	logger    types.Logger
+}
+
+// NewUpgradeManager creates a new upgrade manager
+func NewUpgradeManager() *UpgradeManager {
+	return &UpgradeManager{
+		handlers: make(map[string]UpgradeHandler),
+		done:     make(map[string]uint64),
+	}
+}
+
+// SetUpgradeInfoPath sets the file the upgrade plan is written to when the
+// node halts, so the next binary can pick the plan up
+func (um *UpgradeManager) SetUpgradeInfoPath(path string) {
+	um.infoPath = path
+}
+
+// SetLogger sets the logger upgrades are reported to
+func (um *UpgradeManager) SetLogger(logger types.Logger) {
+	um.logger = logger
+}
+
+// SetReplaying marks the blocks being executed as replayed from storage.
+// The old binary already executed the blocks before an upgrade height, so
+// a binary registering the upgrade may replay them.
+func (um *UpgradeManager) SetReplaying(replaying bool) {
+	um.replaying = replaying
+}
+
+// SetUpgradeHandler registers the migrations of a named upgrade. Binaries
+// register the handlers of the upgrades they implement.
+func (um *UpgradeManager) SetUpgradeHandler(name string, handler UpgradeHandler) {
+	um.handlers[name] = handler
+}
+
+// ScheduleUpgrade schedules a plan, replacing any plan already scheduled
+func (um *UpgradeManager) ScheduleUpgrade(ctx types.Context, plan Plan) error {
+	if err := plan.Validate(); err != nil {
+		return err
+	}
+	if plan.Height <= ctx.Height {
+		return fmt.Errorf("upgrade height %d must be after the current height %d", plan.Height, ctx.Height)
+	}
+	if height, exists := um.done[plan.Name]; exists {
+		return fmt.Errorf("upgrade %s was already applied at height %d", plan.Name, height)
+	}
+
+	um.plan = &plan
+	return nil
+}
+
+// ClearUpgradePlan cancels the scheduled plan
+func (um *UpgradeManager) ClearUpgradePlan() {
+	um.plan = nil
+}
+
+// GetUpgradePlan returns the scheduled plan, if any
+func (um *UpgradeManager) GetUpgradePlan() (Plan, bool) {
+	if um.plan == nil {
+		return Plan{}, false
+	}
+	return *um.plan, true
+}
+
+// GetDoneHeight returns the height an upgrade was applied at, or zero
+func (um *UpgradeManager) GetDoneHeight(name string) uint64 {
+	return um.done[name]
+}
+
+// HandleSoftwareUpgradeProposal schedules the plan of a passed software
+// upgrade proposal. It is registered as the governance proposal handler.
+func (um *UpgradeManager) HandleSoftwareUpgradeProposal(ctx types.Context, proposal types.GovernanceProposal) error {
+	var plan Plan
+	if err := json.Unmarshal(proposal.Content, &plan); err != nil {
+		return fmt.Errorf("invalid software upgrade proposal: %v", err)
+	}
+
+	if err := um.ScheduleUpgrade(ctx, plan); err != nil {
+		return err
+	}
+
+	um.logger.Infof("Proposal %d scheduled upgrade %s at height %d", proposal.ID, plan.Name, plan.Height)
+	return nil
+}
+
+// ProcessBlockStart halts the chain at the height of the scheduled upgrade
+// unless the running binary registers its handler, in which case the
+// migrations run and the chain continues. A binary that registers the
+// handler before the upgrade height refuses to produce blocks, since it
+// would apply new rules to old blocks.
+func (um *UpgradeManager) ProcessBlockStart(ctx types.Context) error {
+	if um.plan == nil {
+		return nil
+	}
+	plan := *um.plan
+
+	handler, exists := um.handlers[plan.Name]
+	if ctx.Height < plan.Height {
+		if exists && !um.replaying {
+			return fmt.Errorf("binary contains upgrade %s scheduled for height %d; run the old binary until then", plan.Name, plan.Height)
+		}
+		return nil
+	}
+
+	if !exists {
+		if err := um.writeUpgradeInfo(plan); err != nil {
+			um.logger.Errorf("Failed to write upgrade info: %v", err)
+		}
+		return UpgradeNeededError{Plan: plan}
+	}
+
+	if err := handler(ctx, plan); err != nil {
+		return fmt.Errorf("upgrade %s failed: %v", plan.Name, err)
+	}
+
+	um.done[plan.Name] = ctx.Height
+	um.plan = nil
+	um.logger.Infof("Applied upgrade %s at height %d", plan.Name, ctx.Height)
+	return nil
+}
+
+// writeUpgradeInfo records the plan the node halted for
+func (um *UpgradeManager) writeUpgradeInfo(plan Plan) error {
+	if um.infoPath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(um.infoPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(um.infoPath, data, 0o644)
+}
+
+// ReadUpgradeInfo loads the plan a halted node recorded. It reports false
+// if the node did not halt for an upgrade.
+func ReadUpgradeInfo(path string) (Plan, bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Plan{}, false, nil
+	}
+	if err != nil {
+		return Plan{}, false, fmt.Errorf("failed to read upgrade info: %v", err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return Plan{}, false, fmt.Errorf("corrupt upgrade info %s: %v", path, err)
+	}
+	return plan, true, nil
+}
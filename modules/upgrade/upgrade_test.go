@@ -0,0 +1,210 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/governance"
+	"undergroundempire/modules/params"
+	"undergroundempire/modules/treasury"
+	"undergroundempire/modules/validator"
+)
+
+// testChain wires the modules an upgrade touches and produces blocks
+type testChain struct {
+	t          *testing.T
+	height     uint64
+	treasury   *treasury.TreasuryManager
+	validators *validator.ValidatorManager
+	params     *params.ParamsManager
+	governance *governance.GovernanceManager
+	upgrade    *UpgradeManager
+}
+
+var (
+	operatorA = types.Address{1}
+	operatorB = types.Address{2}
+)
+
+func newTestChain(t *testing.T) *testChain {
+	t.Helper()
+
+	chain := &testChain{
+		t:          t,
+		treasury:   treasury.NewTreasuryManager(),
+		validators: validator.NewValidatorManager(),
+		params:     params.NewParamsManager(types.DefaultChainParams()),
+		upgrade:    NewUpgradeManager(),
+	}
+	chain.validators.SetBankKeeper(chain.treasury)
+	chain.governance = governance.NewGovernanceManager(chain.treasury, chain.validators)
+	chain.governance.RegisterProposalHandler(types.ProposalTypeSoftwareUpgrade, chain.upgrade.HandleSoftwareUpgradeProposal)
+	chain.params.RegisterSubspace(params.NewSubspace("validator", chain.validators.GetParams, chain.validators.SetParams))
+
+	govParams := governance.DefaultParams()
+	govParams.VotingPeriod = 10
+	govParams.ExpeditedVotingPeriod = 5
+	if err := chain.governance.SetParams(govParams); err != nil {
+		t.Fatalf("set governance params: %v", err)
+	}
+
+	ctx := chain.ctx()
+	for i, operator := range []types.Address{operatorA, operatorB} {
+		chain.treasury.MintTokens(ctx, operator, types.NewUECoins(types.NewUEAmount(100000)))
+		err := chain.validators.RegisterNode(ctx, validator.ValidatorNode{
+			ID:          string(rune('a' + i)),
+			Address:     operator,
+			StakeAmount: ctx.Params.MinValidatorStake,
+		})
+		if err != nil {
+			t.Fatalf("register validator: %v", err)
+		}
+	}
+
+	return chain
+}
+
+func (c *testChain) ctx() types.Context {
+	return types.Context{Height: c.height, Params: c.params.GetChainParams()}
+}
+
+// nextBlock runs the block hooks of the next height
+func (c *testChain) nextBlock() error {
+	ctx := c.ctx().WithHeight(c.height + 1)
+
+	if err := c.upgrade.ProcessBlockStart(ctx); err != nil {
+		return err
+	}
+	if err := c.params.ProcessBlockStart(ctx); err != nil {
+		return err
+	}
+
+	ctx = ctx.WithChainParams(c.params.GetChainParams())
+	c.validators.ProcessBlockEnd(ctx)
+	if err := c.governance.ProcessBlockEnd(ctx); err != nil {
+		return err
+	}
+
+	c.height++
+	return nil
+}
+
+func (c *testChain) passUpgradeProposal(plan Plan) {
+	c.t.Helper()
+
+	content, _ := json.Marshal(plan)
+	ctx := c.ctx()
+	id, err := c.governance.Submit(ctx, types.GovernanceProposal{
+		Title:       "Upgrade " + plan.Name,
+		Description: plan.Info,
+		Proposer:    operatorA,
+		Type:        types.ProposalTypeSoftwareUpgrade,
+		Content:     content,
+	}, c.governance.GetParams().MinDeposit)
+	if err != nil {
+		c.t.Fatalf("submit: %v", err)
+	}
+
+	for _, voter := range []types.Address{operatorA, operatorB} {
+		if err := c.governance.Vote(ctx, id, voter, types.VoteOptionYes); err != nil {
+			c.t.Fatalf("vote: %v", err)
+		}
+	}
+
+	proposal, _ := c.governance.GetProposal(ctx, id)
+	for c.height < proposal.VotingEndHeight {
+		if err := c.nextBlock(); err != nil {
+			c.t.Fatalf("block %d: %v", c.height+1, err)
+		}
+	}
+
+	proposal, _ = c.governance.GetProposal(ctx, id)
+	if proposal.Status != types.ProposalStatusExecuted {
+		c.t.Fatalf("expected upgrade proposal to be executed, got %s", proposal.Status)
+	}
+}
+
+func TestUpgradeHaltsAndMigrates(t *testing.T) {
+	chain := newTestChain(t)
+	infoPath := filepath.Join(t.TempDir(), "data", UpgradeInfoFile)
+	chain.upgrade.SetUpgradeInfoPath(infoPath)
+
+	chain.passUpgradeProposal(Plan{Name: "v2", Height: 30, Info: "raise the validator cap"})
+
+	// The old binary runs up to the upgrade height and halts there
+	var needed UpgradeNeededError
+	for {
+		err := chain.nextBlock()
+		if err == nil {
+			continue
+		}
+		if !errors.As(err, &needed) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		break
+	}
+	if chain.height != 29 || needed.Plan.Name != "v2" {
+		t.Fatalf("expected to halt before height 30 for v2, halted after %d for %q", chain.height, needed.Plan.Name)
+	}
+
+	plan, exists, err := ReadUpgradeInfo(infoPath)
+	if err != nil || !exists || plan.Name != "v2" {
+		t.Fatalf("expected upgrade info for v2, got %+v (exists %v, err %v)", plan, exists, err)
+	}
+
+	// The new binary registers the upgrade and migrates state
+	chain.upgrade.SetUpgradeHandler("v2", func(ctx types.Context, plan Plan) error {
+		return chain.params.ApplyChanges([]params.ParamChange{
+			{Subspace: "validator", Key: "MaxValidators", Value: json.RawMessage("150")},
+		})
+	})
+
+	if err := chain.nextBlock(); err != nil {
+		t.Fatalf("upgraded block: %v", err)
+	}
+	if chain.height != 30 || chain.upgrade.GetDoneHeight("v2") != 30 {
+		t.Fatalf("expected v2 applied at height 30, got %d", chain.upgrade.GetDoneHeight("v2"))
+	}
+	if chain.validators.GetParams().MaxValidators != 150 {
+		t.Fatalf("migration not applied: max validators %d", chain.validators.GetParams().MaxValidators)
+	}
+	if _, exists := chain.upgrade.GetUpgradePlan(); exists {
+		t.Fatal("plan should be cleared after the upgrade")
+	}
+
+	if err := chain.nextBlock(); err != nil {
+		t.Fatalf("block after upgrade: %v", err)
+	}
+}
+
+func TestUpgradeRejectsEarlyBinary(t *testing.T) {
+	um := NewUpgradeManager()
+	ctx := types.Context{Height: 5}
+
+	if err := um.ScheduleUpgrade(ctx, Plan{Name: "v2", Height: 5}); err == nil {
+		t.Fatal("expected a past upgrade height to be rejected")
+	}
+	if err := um.ScheduleUpgrade(ctx, Plan{Name: "v2", Height: 10}); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	um.SetUpgradeHandler("v2", func(ctx types.Context, plan Plan) error { return nil })
+	if err := um.ProcessBlockStart(ctx.WithHeight(6)); err == nil {
+		t.Fatal("expected a binary with the handler to refuse blocks before the upgrade height")
+	}
+	um.SetReplaying(true)
+	if err := um.ProcessBlockStart(ctx.WithHeight(6)); err != nil {
+		t.Fatalf("expected blocks before the upgrade height to replay: %v", err)
+	}
+	um.SetReplaying(false)
+
+	if err := um.ProcessBlockStart(ctx.WithHeight(10)); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	if err := um.ScheduleUpgrade(ctx.WithHeight(10), Plan{Name: "v2", Height: 20}); err == nil {
+		t.Fatal("expected an applied upgrade to be rejected")
+	}
+}
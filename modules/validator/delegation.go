@@ -0,0 +1,166 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"undergroundempire/core/types"
+)
+
+// Delegation represents stake bonded to a validator by another account
+type Delegation struct {
+	DelegatorAddress types.Address
+	ValidatorID      string
+	Amount           types.Int
+}
+
+// Delegate bonds stake from a delegator to a validator. Delegating from the
+// validator's own address increases its self-bond. Pending rewards of an
+// existing delegation are withdrawn first.
+func (vm *ValidatorManager) Delegate(ctx types.Context, delegator types.Address, validatorID string, amount types.Int) error {
+	if !amount.IsPositive() {
+		return fmt.Errorf("delegation amount must be positive")
+	}
+
+	if err := vm.checkStakingRoute(); err != nil {
+		return err
+	}
+
+	validator, err := vm.GetValidator(ctx, validatorID)
+	if err != nil {
+		return err
+	}
+
+	if validator.Status != ValidatorStatusActive && validator.Status != ValidatorStatusCandidate {
+		return fmt.Errorf("validator %s is not accepting delegations (status: %s)", validatorID, validator.Status)
+	}
+
+	if err := vm.bondTokens(ctx, delegator, amount); err != nil {
+		return err
+	}
+
+	stake, _, err := vm.settleDelegation(ctx, validator, delegator)
+	if err != nil {
+		return err
+	}
+
+	if delegator == validator.Address {
+		validator.StakeAmount = validator.StakeAmount.Add(amount)
+	} else {
+		validator.DelegatedAmount = validator.DelegatedAmount.Add(amount)
+	}
+	validator.UpdatedAt = time.Now()
+	vm.validators[validatorID] = validator
+
+	vm.initializeDelegation(ctx, validatorID, delegator, stake.Add(amount))
+	return nil
+}
+
+// Undelegate unbonds stake previously delegated to a validator. Pending
+// rewards are withdrawn first. The operator of a bonded validator cannot
+// unbond its self-bond below the minimum validator stake.
+func (vm *ValidatorManager) Undelegate(ctx types.Context, delegator types.Address, validatorID string, amount types.Int) error {
+	if err := vm.checkStakingRoute(); err != nil {
+		return err
+	}
+
+	validator, err := vm.GetValidator(ctx, validatorID)
+	if err != nil {
+		return err
+	}
+
+	state := vm.distribution[validatorID]
+	info, exists := state.delegators[delegator]
+	if !exists {
+		return fmt.Errorf("delegation from %s to %s not found", delegator, validatorID)
+	}
+
+	delegated := state.currentStake(info)
+	if !amount.IsPositive() || amount.GT(delegated) {
+		return fmt.Errorf("invalid undelegation amount: %s (delegated: %s)", amount, delegated)
+	}
+
+	isOperator := delegator == validator.Address
+	isBonded := validator.Status == ValidatorStatusActive || validator.Status == ValidatorStatusCandidate
+	chainParams := ctx.Params
+	if isOperator && isBonded && !chainParams.IsValidatorEligible(delegated.Sub(amount)) {
+		return fmt.Errorf("self-bond cannot drop below %s", types.FormatCoin(types.NewUECoins(chainParams.MinValidatorStake)))
+	}
+
+	if _, _, err := vm.settleDelegation(ctx, validator, delegator); err != nil {
+		return err
+	}
+
+	if isOperator {
+		validator.StakeAmount = validator.StakeAmount.Sub(amount)
+	} else {
+		validator.DelegatedAmount = validator.DelegatedAmount.Sub(amount)
+	}
+	validator.UpdatedAt = time.Now()
+	vm.validators[validatorID] = validator
+
+	if delegated.GT(amount) {
+		vm.initializeDelegation(ctx, validatorID, delegator, delegated.Sub(amount))
+	}
+	return vm.unbondTokens(ctx, delegator, amount)
+}
+
+// GetDelegation returns a single delegation
+func (vm *ValidatorManager) GetDelegation(ctx types.Context, delegator types.Address, validatorID string) (Delegation, error) {
+	state, exists := vm.distribution[validatorID]
+	if !exists {
+		return Delegation{}, fmt.Errorf("validator with ID %s not found", validatorID)
+	}
+
+	info, exists := state.delegators[delegator]
+	if !exists {
+		return Delegation{}, fmt.Errorf("delegation from %s to %s not found", delegator, validatorID)
+	}
+
+	return Delegation{DelegatorAddress: delegator, ValidatorID: validatorID, Amount: state.currentStake(info)}, nil
+}
+
+// GetDelegations returns all delegations to a validator ordered by delegator
+// address. The operator's self-bond is not included.
+func (vm *ValidatorManager) GetDelegations(ctx types.Context, validatorID string) []Delegation {
+	validator, exists := vm.validators[validatorID]
+	if !exists {
+		return nil
+	}
+
+	state := vm.distribution[validatorID]
+	var delegations []Delegation
+
+	for delegator, info := range state.delegators {
+		if delegator == validator.Address {
+			continue
+		}
+		delegations = append(delegations, Delegation{
+			DelegatorAddress: delegator,
+			ValidatorID:      validatorID,
+			Amount:           state.currentStake(info),
+		})
+	}
+
+	sort.Slice(delegations, func(i, j int) bool {
+		return bytes.Compare(delegations[i].DelegatorAddress[:], delegations[j].DelegatorAddress[:]) < 0
+	})
+	return delegations
+}
+
+// GetBondedStake returns the stake an account has bonded to active
+// validators, including its self-bond if it operates one
+func (vm *ValidatorManager) GetBondedStake(ctx types.Context, address types.Address) types.Int {
+	total := types.ZeroInt()
+
+	for _, validator := range vm.GetActiveValidators(ctx) {
+		state := vm.distribution[validator.ID]
+		if info, exists := state.delegators[address]; exists {
+			total = total.Add(state.currentStake(info))
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,202 @@
+package validator
+
+import (
+	"undergroundempire/core/types"
+)
+
+// Rewards are accounted lazily using F1 fee distribution. Each validator
+// accrues rewards into its current period. Whenever its stake changes the
+// period is closed and the rewards per unit of stake are added to a
+// cumulative ratio. A delegator's rewards are then its stake multiplied by
+// the difference between the ratio now and the ratio when it last changed,
+// so withdrawing costs O(1) per validator regardless of how many blocks or
+// delegators there are. Slashes close a period too and are replayed when
+// computing a delegator's stake.
+
+// ValidatorCurrentRewards tracks rewards accrued in a validator's open period
+type ValidatorCurrentRewards struct {
+	Rewards types.Int
+	Period  uint64
+}
+
+// ValidatorHistoricalRewards holds the cumulative reward ratio at the end of a period
+type ValidatorHistoricalRewards struct {
+	CumulativeRewardRatio types.Dec // Rewards per unit of stake
+	ReferenceCount        uint32    // Delegations, slash events and the open period referencing it
+}
+
+// DelegatorStartingInfo records where a delegator's rewards are measured from
+type DelegatorStartingInfo struct {
+	PreviousPeriod uint64
+	Stake          types.Int // Stake at PreviousPeriod, before any later slashes
+	Height         uint64
+}
+
+// ValidatorSlashEvent records a slash so delegator stakes can be reduced lazily
+type ValidatorSlashEvent struct {
+	Height   uint64
+	Period   uint64
+	Fraction types.Dec // Share of stake slashed
+}
+
+// distributionState holds the reward accounting of a single validator
+type distributionState struct {
+	current     ValidatorCurrentRewards
+	historical  map[uint64]ValidatorHistoricalRewards
+	commission  types.Int // Accumulated, unwithdrawn commission
+	outstanding types.Int // Tokens held by the distribution module for this validator
+	slashEvents []ValidatorSlashEvent
+	delegators  map[types.Address]DelegatorStartingInfo
+}
+
+// initializeValidatorRewards sets up reward accounting for a new validator
+func (vm *ValidatorManager) initializeValidatorRewards(validatorID string) {
+	state := &distributionState{
+		current:     ValidatorCurrentRewards{Rewards: types.ZeroInt(), Period: 1},
+		historical:  make(map[uint64]ValidatorHistoricalRewards),
+		commission:  types.ZeroInt(),
+		outstanding: types.ZeroInt(),
+		delegators:  make(map[types.Address]DelegatorStartingInfo),
+	}
+	state.historical[0] = ValidatorHistoricalRewards{
+		CumulativeRewardRatio: types.ZeroDec(),
+		ReferenceCount:        1,
+	}
+	vm.distribution[validatorID] = state
+}
+
+// incrementValidatorPeriod closes the validator's open period and returns it.
+// It must be called before any change to the validator's stake.
+func (vm *ValidatorManager) incrementValidatorPeriod(validator ValidatorNode) uint64 {
+	state := vm.distribution[validator.ID]
+
+	ratio := types.ZeroDec()
+	if stake := validator.TotalStake(); stake.IsZero() {
+		// Nobody to attribute the rewards to, keep them for the operator
+		state.commission = state.commission.Add(state.current.Rewards)
+	} else {
+		ratio = types.NewDecFromInt(state.current.Rewards).QuoInt(stake)
+	}
+
+	period := state.current.Period
+	previous := state.historical[period-1].CumulativeRewardRatio
+	state.historical[period] = ValidatorHistoricalRewards{
+		CumulativeRewardRatio: previous.Add(ratio),
+		ReferenceCount:        1,
+	}
+	vm.decrementReferenceCount(state, period-1)
+
+	state.current = ValidatorCurrentRewards{Rewards: types.ZeroInt(), Period: period + 1}
+	return period
+}
+
+// incrementReferenceCount marks a historical period as still needed
+func (vm *ValidatorManager) incrementReferenceCount(state *distributionState, period uint64) {
+	historical := state.historical[period]
+	historical.ReferenceCount++
+	state.historical[period] = historical
+}
+
+// decrementReferenceCount releases a historical period, pruning it once unused
+func (vm *ValidatorManager) decrementReferenceCount(state *distributionState, period uint64) {
+	historical := state.historical[period]
+	if historical.ReferenceCount <= 1 {
+		delete(state.historical, period)
+		return
+	}
+	historical.ReferenceCount--
+	state.historical[period] = historical
+}
+
+// initializeDelegation starts measuring a delegator's rewards from the last closed period
+func (vm *ValidatorManager) initializeDelegation(ctx types.Context, validatorID string, delegator types.Address, stake types.Int) {
+	state := vm.distribution[validatorID]
+	previousPeriod := state.current.Period - 1
+	vm.incrementReferenceCount(state, previousPeriod)
+
+	state.delegators[delegator] = DelegatorStartingInfo{
+		PreviousPeriod: previousPeriod,
+		Stake:          stake,
+		Height:         ctx.Height,
+	}
+}
+
+// settleDelegation closes the validator's period ahead of a stake change.
+// Pending rewards of an existing delegation are paid out and its starting
+// info removed. It returns the delegation's current stake.
+func (vm *ValidatorManager) settleDelegation(ctx types.Context, validator ValidatorNode, delegator types.Address) (stake types.Int, rewards types.Int, err error) {
+	state := vm.distribution[validator.ID]
+	endingPeriod := vm.incrementValidatorPeriod(validator)
+
+	info, exists := state.delegators[delegator]
+	if !exists {
+		return types.ZeroInt(), types.ZeroInt(), nil
+	}
+
+	rewards = types.MinInt(state.calculateDelegationRewards(info, endingPeriod), state.outstanding)
+	if err := vm.payReward(ctx, types.DistributionModuleName, delegator, rewards); err != nil {
+		return types.ZeroInt(), types.ZeroInt(), err
+	}
+	state.outstanding = state.outstanding.Sub(rewards)
+
+	vm.decrementReferenceCount(state, info.PreviousPeriod)
+	delete(state.delegators, delegator)
+
+	return state.currentStake(info), rewards, nil
+}
+
+// calculateDelegationRewards computes rewards earned between the delegation's
+// starting period and endingPeriod, replaying slashes along the way
+func (s *distributionState) calculateDelegationRewards(info DelegatorStartingInfo, endingPeriod uint64) types.Int {
+	rewards := types.ZeroDec()
+	stake := info.Stake
+	startingPeriod := info.PreviousPeriod
+
+	for _, event := range s.slashEvents {
+		if event.Period <= info.PreviousPeriod || event.Period > endingPeriod {
+			continue
+		}
+		rewards = rewards.Add(s.rewardsBetween(startingPeriod, event.Period, stake))
+		stake = applySlashFraction(stake, event.Fraction)
+		startingPeriod = event.Period
+	}
+	rewards = rewards.Add(s.rewardsBetween(startingPeriod, endingPeriod, stake))
+
+	return rewards.TruncateInt()
+}
+
+// rewardsBetween returns stake times the ratio growth between two periods
+func (s *distributionState) rewardsBetween(startingPeriod, endingPeriod uint64, stake types.Int) types.Dec {
+	difference := s.historical[endingPeriod].CumulativeRewardRatio.Sub(s.historical[startingPeriod].CumulativeRewardRatio)
+	return difference.MulInt(stake)
+}
+
+// currentStake applies every slash since the delegation started to its stake
+func (s *distributionState) currentStake(info DelegatorStartingInfo) types.Int {
+	stake := info.Stake
+	for _, event := range s.slashEvents {
+		if event.Period > info.PreviousPeriod {
+			stake = applySlashFraction(stake, event.Fraction)
+		}
+	}
+	return stake
+}
+
+// pendingRewards returns the rewards a delegator could withdraw right now
+// without modifying any state
+func (s *distributionState) pendingRewards(validator ValidatorNode, delegator types.Address) types.Int {
+	info, exists := s.delegators[delegator]
+	if !exists {
+		return types.ZeroInt()
+	}
+
+	rewards := s.calculateDelegationRewards(info, s.current.Period-1)
+	rewards = rewards.Add(s.currentStake(info).MulDiv(s.current.Rewards, validator.TotalStake()))
+
+	return types.MinInt(rewards, s.outstanding)
+}
+
+// applySlashFraction reduces a stake by a fraction
+func applySlashFraction(stake types.Int, fraction types.Dec) types.Int {
+	return stake.Sub(fraction.MulInt(stake).TruncateInt())
+}
@@ -0,0 +1,106 @@
+package validator
+
+import (
+	"fmt"
+
+	"undergroundempire/core/types"
+)
+
+// Message types accepted by the validator module
+const (
+	MsgTypeCreateValidator = "create_validator"
+	MsgTypeEditValidator   = "edit_validator"
+	MsgTypeUnjail          = "unjail"
+)
+
+// MsgCreateValidator registers a validator operated by the sender, bonding
+// its self-stake from the sender's balance
+type MsgCreateValidator struct {
+	ID          string
+	Stake       types.Int
+	Commission  types.Dec
+	Description string
+	Website     string
+}
+
+// MsgEditValidator changes the commission and description of a validator.
+// Nil fields are left unchanged.
+type MsgEditValidator struct {
+	ID          string
+	Commission  *types.Dec
+	Description *string
+	Website     *string
+}
+
+// MsgUnjail returns a jailed validator to the bonded set
+type MsgUnjail struct {
+	ID string
+}
+
+// HandleMsg executes a validator message sent by an account. Only the
+// operator of a validator may edit or unjail it.
+func (vm *ValidatorManager) HandleMsg(ctx types.Context, sender types.Address, msg types.ModuleMsg) error {
+	switch msg.Type {
+	case MsgTypeCreateValidator:
+		var content MsgCreateValidator
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		if err := vm.checkStakingRoute(); err != nil {
+			return err
+		}
+		return vm.RegisterNode(ctx, ValidatorNode{
+			ID:          content.ID,
+			Address:     sender,
+			StakeAmount: content.Stake,
+			Commission:  content.Commission,
+			Description: content.Description,
+			Website:     content.Website,
+		})
+
+	case MsgTypeEditValidator:
+		var content MsgEditValidator
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		validator, err := vm.operatedValidator(ctx, sender, content.ID)
+		if err != nil {
+			return err
+		}
+		if content.Commission != nil {
+			validator.Commission = *content.Commission
+		}
+		if content.Description != nil {
+			validator.Description = *content.Description
+		}
+		if content.Website != nil {
+			validator.Website = *content.Website
+		}
+		return vm.UpdateValidator(ctx, validator)
+
+	case MsgTypeUnjail:
+		var content MsgUnjail
+		if err := msg.Decode(&content); err != nil {
+			return err
+		}
+		if _, err := vm.operatedValidator(ctx, sender, content.ID); err != nil {
+			return err
+		}
+		return vm.Unjail(ctx, content.ID)
+
+	default:
+		return fmt.Errorf("unknown validator message type: %s", msg.Type)
+	}
+}
+
+// operatedValidator returns a validator if sender is its operator
+func (vm *ValidatorManager) operatedValidator(ctx types.Context, sender types.Address, nodeID string) (ValidatorNode, error) {
+	validator, err := vm.GetValidator(ctx, nodeID)
+	if err != nil {
+		return ValidatorNode{}, err
+	}
+	if validator.Address != sender {
+		return ValidatorNode{}, fmt.Errorf("%s is not the operator of validator %s", sender, nodeID)
+	}
+	return validator, nil
+}
@@ -0,0 +1,173 @@
+package validator
+
+import (
+	"fmt"
+
+	"undergroundempire/core/types"
+)
+
+// CalculateRewards returns the rewards the validator operator could withdraw
+// now: accumulated commission plus the rewards earned by its self-bond
+func (vm *ValidatorManager) CalculateRewards(ctx types.Context, nodeID string) types.Int {
+	validator, err := vm.GetValidator(ctx, nodeID)
+	if err != nil {
+		return types.ZeroInt()
+	}
+
+	state := vm.distribution[nodeID]
+	return state.commission.Add(state.pendingRewards(validator, validator.Address))
+}
+
+// GetPendingRewards returns the rewards a delegator could withdraw now from a validator
+func (vm *ValidatorManager) GetPendingRewards(ctx types.Context, delegator types.Address, validatorID string) (types.Int, error) {
+	validator, err := vm.GetValidator(ctx, validatorID)
+	if err != nil {
+		return types.ZeroInt(), err
+	}
+
+	return vm.distribution[validatorID].pendingRewards(validator, delegator), nil
+}
+
+// AllocateTokens distributes the fee collector balance, made up of the
+// block's minted provision and collected fees, at the end of a block. The
+// community tax goes to the community pool, the proposer receives the
+// proposer bonus and the remainder is split across the active set by voting
+// power. Rounding dust stays in the fee collector and is carried into the
+// next block.
+func (vm *ValidatorManager) AllocateTokens(ctx types.Context, proposerID string) error {
+	if vm.bank == nil {
+		return fmt.Errorf("no bank keeper configured")
+	}
+
+	feeCollector := types.ModuleAddress(types.FeeCollectorName)
+	pool := vm.bank.GetBalance(ctx, feeCollector).Amount
+	if pool.IsZero() {
+		return nil
+	}
+
+	// Fund the community pool
+	tax := vm.params.CommunityTax.MulInt(pool).TruncateInt()
+	if tax.IsPositive() {
+		err := vm.bank.Transfer(ctx, feeCollector, types.ModuleAddress(types.CommunityPoolName), types.NewUECoins(tax))
+		if err != nil {
+			return err
+		}
+	}
+	bonus := vm.params.ProposerBonus.MulInt(pool).TruncateInt()
+	pool = pool.Sub(tax)
+
+	// Pay the proposer bonus
+	if proposer, err := vm.GetValidator(ctx, proposerID); err == nil && proposer.Status == ValidatorStatusActive {
+		if err := vm.DistributeRewards(ctx, proposerID, bonus); err != nil {
+			return err
+		}
+		pool = pool.Sub(bonus)
+	}
+
+	// Split the rest by voting power
+	activeValidators := vm.GetActiveValidators(ctx)
+	totalStake := vm.GetTotalStake(ctx)
+	if totalStake.IsZero() {
+		return nil
+	}
+
+	for _, validator := range activeValidators {
+		reward := pool.MulDiv(validator.TotalStake(), totalStake)
+		if err := vm.DistributeRewards(ctx, validator.ID, reward); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DistributeRewards moves a validator's reward from the fee collector into
+// the distribution module. Commission is set aside for the operator and the
+// remainder accrues to the validator's current period, to be withdrawn by
+// the operator and delegators in proportion to their stake.
+func (vm *ValidatorManager) DistributeRewards(ctx types.Context, nodeID string, amount types.Int) error {
+	if vm.bank == nil {
+		return fmt.Errorf("no bank keeper configured")
+	}
+
+	validator, err := vm.GetValidator(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+
+	if amount.IsZero() {
+		return nil
+	}
+
+	err = vm.bank.Transfer(ctx,
+		types.ModuleAddress(types.FeeCollectorName),
+		types.ModuleAddress(types.DistributionModuleName),
+		types.NewUECoins(amount))
+	if err != nil {
+		return err
+	}
+
+	commission := validator.Commission.MulInt(amount).TruncateInt()
+
+	state := vm.distribution[nodeID]
+	state.commission = state.commission.Add(commission)
+	state.current.Rewards = state.current.Rewards.Add(amount.Sub(commission))
+	state.outstanding = state.outstanding.Add(amount)
+
+	return nil
+}
+
+// WithdrawRewards pays out the rewards a delegator has earned from a
+// validator. The validator operator withdraws its self-bond rewards the
+// same way using the validator address.
+func (vm *ValidatorManager) WithdrawRewards(ctx types.Context, delegator types.Address, validatorID string) (types.Int, error) {
+	validator, err := vm.GetValidator(ctx, validatorID)
+	if err != nil {
+		return types.ZeroInt(), err
+	}
+
+	if _, exists := vm.distribution[validatorID].delegators[delegator]; !exists {
+		return types.ZeroInt(), fmt.Errorf("delegation from %s to %s not found", delegator, validatorID)
+	}
+
+	stake, rewards, err := vm.settleDelegation(ctx, validator, delegator)
+	if err != nil {
+		return types.ZeroInt(), err
+	}
+	vm.initializeDelegation(ctx, validatorID, delegator, stake)
+
+	return rewards, nil
+}
+
+// WithdrawCommission pays the accumulated commission to the validator operator
+func (vm *ValidatorManager) WithdrawCommission(ctx types.Context, validatorID string) (types.Int, error) {
+	validator, err := vm.GetValidator(ctx, validatorID)
+	if err != nil {
+		return types.ZeroInt(), err
+	}
+
+	state := vm.distribution[validatorID]
+	commission := state.commission
+	if commission.IsZero() {
+		return types.ZeroInt(), fmt.Errorf("no commission to withdraw for validator %s", validatorID)
+	}
+
+	if err := vm.payReward(ctx, types.DistributionModuleName, validator.Address, commission); err != nil {
+		return types.ZeroInt(), err
+	}
+	state.commission = types.ZeroInt()
+	state.outstanding = state.outstanding.Sub(commission)
+
+	return commission, nil
+}
+
+// payReward transfers a reward from a module account to an account
+func (vm *ValidatorManager) payReward(ctx types.Context, module string, to types.Address, amount types.Int) error {
+	if amount.IsZero() {
+		return nil
+	}
+	if vm.bank == nil {
+		return fmt.Errorf("no bank keeper configured")
+	}
+	return vm.bank.Transfer(ctx, types.ModuleAddress(module), to, types.NewUECoins(amount))
+}
@@ -0,0 +1,287 @@
+package validator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"undergroundempire/core/types"
+)
+
+// SlashLedgerFile is the file name of the slash ledger inside the node's data directory
+const SlashLedgerFile = "slash_history.jsonl"
+
+// SlashRecord represents a slash record for a validator
+type SlashRecord struct {
+	ValidatorID      string
+	Reason           SlashReason
+	Amount           types.Int // Total stake slashed
+	SelfStakeAmount  types.Int // Slashed from the operator's self-bond
+	DelegatedAmount  types.Int // Slashed from delegations
+	Fraction         types.Dec // Share of stake slashed
+	InfractionHeight uint64    // Height at which the misbehavior occurred
+	EvidenceHash     string
+	Timestamp        time.Time
+	Height           uint64 // Height at which the slash was applied
+}
+
+// Evidence describes the infraction behind a slash
+type Evidence struct {
+	Height uint64 // Infraction height
+	Hash   string // Hash of the submitted evidence
+}
+
+// SlashLedger persists slash records
+type SlashLedger interface {
+	Append(record SlashRecord) error
+	Records(validatorID string) []SlashRecord
+}
+
+// MemorySlashLedger keeps slash records in memory
+type MemorySlashLedger struct {
+	mu      sync.RWMutex
+	records map[string][]SlashRecord
+}
+
+// NewMemorySlashLedger creates an empty in-memory slash ledger
+func NewMemorySlashLedger() *MemorySlashLedger {
+	return &MemorySlashLedger{
+		records: make(map[string][]SlashRecord),
+	}
+}
+
+// Append adds a record to the ledger
+func (l *MemorySlashLedger) Append(record SlashRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records[record.ValidatorID] = append(l.records[record.ValidatorID], record)
+	return nil
+}
+
+// Records returns a validator's slash records in the order they were applied
+func (l *MemorySlashLedger) Records(validatorID string) []SlashRecord {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	records := make([]SlashRecord, len(l.records[validatorID]))
+	copy(records, l.records[validatorID])
+	return records
+}
+
+// FileSlashLedger appends slash records to a JSON lines file so the history
+// survives restarts. Records are also kept in memory for queries.
+type FileSlashLedger struct {
+	*MemorySlashLedger
+	path string
+}
+
+// OpenFileSlashLedger opens the ledger at path, loading any existing records
+func OpenFileSlashLedger(path string) (*FileSlashLedger, error) {
+	ledger := &FileSlashLedger{
+		MemorySlashLedger: NewMemorySlashLedger(),
+		path:              path,
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open slash ledger: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record SlashRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("corrupt slash ledger %s: %v", path, err)
+		}
+		ledger.MemorySlashLedger.Append(record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read slash ledger: %v", err)
+	}
+
+	return ledger, nil
+}
+
+// Append writes a record to the ledger file and keeps it in memory
+func (l *FileSlashLedger) Append(record SlashRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create slash ledger directory: %v", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open slash ledger: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write slash record: %v", err)
+	}
+
+	return l.MemorySlashLedger.Append(record)
+}
+
+// SlashNode slashes a validator for misbehavior detected at the current height
+func (vm *ValidatorManager) SlashNode(ctx types.Context, nodeID string, reason SlashReason) error {
+	_, err := vm.SlashWithEvidence(ctx, nodeID, reason, Evidence{Height: ctx.Height})
+	return err
+}
+
+// SlashWithEvidence slashes a validator for an infraction and records the
+// slash in the ledger. The slash fraction applies to the self-bond and to
+// every delegation, and the slashed stake is burned from the bonded pool.
+// The validator is jailed; a double-sign also tombstones it so it can never
+// rejoin. A validator whose self-bond drops below the minimum stake is moved
+// to inactive instead. A slash whose burn fails leaves no trace. The burn
+// cannot be undone, so a slash the ledger fails to record stays applied and
+// the error is returned with its record.
+func (vm *ValidatorManager) SlashWithEvidence(ctx types.Context, nodeID string, reason SlashReason, evidence Evidence) (SlashRecord, error) {
+	validator, err := vm.GetValidator(ctx, nodeID)
+	if err != nil {
+		return SlashRecord{}, err
+	}
+
+	if validator.Tombstoned {
+		return SlashRecord{}, fmt.Errorf("validator %s is tombstoned and cannot be slashed again", nodeID)
+	}
+
+	// Compute the slash before changing any state. Each delegation loses
+	// the same fraction as the self-bond.
+	fraction := vm.slashFraction(reason)
+	remainingSelfStake := applySlashFraction(validator.StakeAmount, fraction)
+	remainingDelegations := types.ZeroInt()
+	for _, delegation := range vm.GetDelegations(ctx, nodeID) {
+		remainingDelegations = remainingDelegations.Add(applySlashFraction(delegation.Amount, fraction))
+	}
+
+	record := SlashRecord{
+		ValidatorID:      nodeID,
+		Reason:           reason,
+		SelfStakeAmount:  validator.StakeAmount.Sub(remainingSelfStake),
+		DelegatedAmount:  validator.DelegatedAmount.Sub(remainingDelegations),
+		Fraction:         fraction,
+		InfractionHeight: evidence.Height,
+		EvidenceHash:     evidence.Hash,
+		Timestamp:        ctx.Timestamp,
+		Height:           ctx.Height,
+	}
+	record.Amount = record.SelfStakeAmount.Add(record.DelegatedAmount)
+
+	// The burn is the only step that can fail, so it runs first
+	if err := vm.burnTokens(ctx, record.Amount); err != nil {
+		return SlashRecord{}, err
+	}
+
+	// Close the reward period at the pre-slash stake and record the slash
+	// so delegations are reduced when they are next touched
+	state := vm.distribution[nodeID]
+	period := vm.incrementValidatorPeriod(validator)
+	state.slashEvents = append(state.slashEvents, ValidatorSlashEvent{
+		Height:   ctx.Height,
+		Period:   period,
+		Fraction: fraction,
+	})
+	vm.incrementReferenceCount(state, period)
+
+	validator.StakeAmount = remainingSelfStake
+	validator.DelegatedAmount = remainingDelegations
+	validator.UpdatedAt = time.Now()
+
+	// Jail, tombstone or deactivate
+	switch {
+	case reason == SlashReasonDoubleSigning:
+		validator.Status = ValidatorStatusJailed
+		validator.Tombstoned = true
+	case !ctx.Params.IsValidatorEligible(validator.StakeAmount):
+		validator.Status = ValidatorStatusInactive
+	default:
+		validator.Status = ValidatorStatusJailed
+		validator.JailedUntil = ctx.Height + vm.params.DowntimeJailDuration
+	}
+	vm.validators[nodeID] = validator
+
+	if err := vm.slashLedger.Append(record); err != nil {
+		return record, fmt.Errorf("slash of %s was applied but not recorded: %v", nodeID, err)
+	}
+	return record, nil
+}
+
+// Unjail returns a jailed validator to the bonded set once its jail period
+// has passed. Tombstoned validators and validators below the minimum stake
+// cannot be unjailed.
+func (vm *ValidatorManager) Unjail(ctx types.Context, nodeID string) error {
+	validator, err := vm.GetValidator(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+
+	if validator.Status != ValidatorStatusJailed {
+		return fmt.Errorf("validator %s is not jailed", nodeID)
+	}
+	if validator.Tombstoned {
+		return fmt.Errorf("validator %s is tombstoned", nodeID)
+	}
+	if ctx.Height < validator.JailedUntil {
+		return fmt.Errorf("validator %s is jailed until height %d", nodeID, validator.JailedUntil)
+	}
+	if chainParams := ctx.Params; !chainParams.IsValidatorEligible(validator.StakeAmount) {
+		return fmt.Errorf("insufficient stake to unjail: minimum required is %s, got %s",
+			types.FormatCoin(types.NewUECoins(chainParams.MinValidatorStake)), types.FormatCoin(types.NewUECoins(validator.StakeAmount)))
+	}
+
+	// Take a free slot or wait to be ranked at the next epoch
+	if uint32(vm.GetValidatorCount(ctx)) < vm.params.MaxValidators {
+		validator.Status = ValidatorStatusActive
+	} else {
+		validator.Status = ValidatorStatusCandidate
+	}
+	validator.JailedUntil = 0
+	validator.UpdatedAt = time.Now()
+	vm.validators[nodeID] = validator
+
+	return nil
+}
+
+// burnTokens destroys slashed stake held in the bonded pool
+func (vm *ValidatorManager) burnTokens(ctx types.Context, amount types.Int) error {
+	if vm.bank == nil || amount.IsZero() {
+		return nil
+	}
+	if err := vm.bank.BurnTokens(ctx, types.ModuleAddress(types.BondedPoolName), types.NewUECoins(amount)); err != nil {
+		return fmt.Errorf("failed to burn slashed stake: %v", err)
+	}
+	return nil
+}
+
+// GetSlashHistory returns a validator's slash records in the order they were applied
+func (vm *ValidatorManager) GetSlashHistory(ctx types.Context, nodeID string) []SlashRecord {
+	return vm.slashLedger.Records(nodeID)
+}
+
+// slashFraction returns the share of stake to slash for a reason
+func (vm *ValidatorManager) slashFraction(reason SlashReason) types.Dec {
+	switch reason {
+	case SlashReasonDoubleSigning:
+		return vm.params.SlashFractionDoubleSign
+	case SlashReasonInvalidBlock:
+		return vm.params.SlashFractionInvalidBlock
+	case SlashReasonEquivocation:
+		return vm.params.SlashFractionEquivocation
+	default:
+		return vm.params.SlashFractionDowntime
+	}
+}
@@ -2,6 +2,7 @@ package validator
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"undergroundempire/core/types"
@@ -18,35 +19,97 @@ type ValidatorRegistry interface {
 
 // ValidatorRewardEngine manages validator rewards and penalties
 type ValidatorRewardEngine interface {
-	CalculateRewards(ctx types.Context, nodeID string) uint64
-	DistributeRewards(ctx types.Context, nodeID string, amount uint64) error
+	CalculateRewards(ctx types.Context, nodeID string) types.Int
+	DistributeRewards(ctx types.Context, nodeID string, amount types.Int) error
 	SlashNode(ctx types.Context, nodeID string, reason SlashReason) error
 	GetSlashHistory(ctx types.Context, nodeID string) []SlashRecord
 }
 
 // ValidatorNode represents a validator in the Underground Empire network
 type ValidatorNode struct {
-	ID          string
-	Address     types.Address
-	StakeAmount uint64
-	Status      ValidatorStatus
-	Commission  uint64 // Commission rate in basis points (0-10000)
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Description string
-	Website     string
+	ID              string
+	Address         types.Address
+	StakeAmount     types.Int // Self-bonded stake
+	DelegatedAmount types.Int // Stake bonded by delegators
+	Status          ValidatorStatus
+	Commission      types.Dec // Commission rate (0-1)
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Description     string
+	Website         string
+	JailedUntil     uint64 // Height from which a jailed validator may unjail
+	Tombstoned      bool   // Permanently jailed after a double-sign
+}
+
+// TotalStake returns the validator's voting power: self-bond plus delegations
+func (v ValidatorNode) TotalStake() types.Int {
+	return v.StakeAmount.Add(v.DelegatedAmount)
 }
 
 // ValidatorStatus represents the status of a validator
 type ValidatorStatus string
 
 const (
-	ValidatorStatusActive   ValidatorStatus = "active"
-	ValidatorStatusInactive ValidatorStatus = "inactive"
-	ValidatorStatusSlashed  ValidatorStatus = "slashed"
-	ValidatorStatusJailed   ValidatorStatus = "jailed"
+	ValidatorStatusActive    ValidatorStatus = "active"
+	ValidatorStatusCandidate ValidatorStatus = "candidate" // Bonded but outside the active set
+	ValidatorStatusInactive  ValidatorStatus = "inactive"
+	ValidatorStatusSlashed   ValidatorStatus = "slashed"
+	ValidatorStatusJailed    ValidatorStatus = "jailed"
 )
 
+// Params holds the governance-controlled validator set parameters
+type Params struct {
+	MaxValidators uint32    // Maximum number of active validators
+	ProposerBonus types.Dec // Share of each block reward paid to the proposer
+	CommunityTax  types.Dec // Share of each block reward sent to the community pool
+
+	// Slashing, fractions of stake
+	SlashFractionDoubleSign   types.Dec
+	SlashFractionDowntime     types.Dec
+	SlashFractionInvalidBlock types.Dec
+	SlashFractionEquivocation types.Dec
+	DowntimeJailDuration      uint64 // Blocks a validator stays jailed before it may unjail
+}
+
+// DefaultParams returns the default validator set parameters
+func DefaultParams() Params {
+	return Params{
+		MaxValidators: types.DefaultMaxValidators,
+		ProposerBonus: types.NewDecWithPrec(5, 2), // 5%
+		CommunityTax:  types.NewDecWithPrec(2, 2), // 2%
+
+		SlashFractionDoubleSign:   types.NewDecWithPrec(5, 1),  // 50%
+		SlashFractionDowntime:     types.NewDecWithPrec(1, 2),  // 1%
+		SlashFractionInvalidBlock: types.NewDecWithPrec(25, 2), // 25%
+		SlashFractionEquivocation: types.NewDecWithPrec(5, 1),  // 50%
+		DowntimeJailDuration:      types.EpochDuration,
+	}
+}
+
+// Validate checks that the parameters are well formed
+func (p Params) Validate() error {
+	if p.MaxValidators == 0 {
+		return fmt.Errorf("max validators must be positive")
+	}
+	if p.ProposerBonus.IsNegative() || p.CommunityTax.IsNegative() {
+		return fmt.Errorf("proposer bonus and community tax cannot be negative")
+	}
+	if total := p.ProposerBonus.Add(p.CommunityTax); total.GT(types.OneDec()) {
+		return fmt.Errorf("proposer bonus and community tax cannot exceed 1, got %s", total)
+	}
+	for _, fraction := range []types.Dec{
+		p.SlashFractionDoubleSign,
+		p.SlashFractionDowntime,
+		p.SlashFractionInvalidBlock,
+		p.SlashFractionEquivocation,
+	} {
+		if fraction.IsNegative() || fraction.GT(types.OneDec()) {
+			return fmt.Errorf("slash fraction must be between 0 and 1, got %s", fraction)
+		}
+	}
+	return nil
+}
+
 // SlashReason represents the reason for slashing a validator
 type SlashReason string
 
@@ -57,34 +120,114 @@ const (
 	SlashReasonEquivocation  SlashReason = "equivocation"
 )
 
-// SlashRecord represents a slash record for a validator
-type SlashRecord struct {
-	ValidatorID string
-	Reason      SlashReason
-	Amount      uint64
-	Timestamp   time.Time
-	Height      uint64
-}
-
 // ValidatorManager implements validator management operations
 type ValidatorManager struct {
-	// TODO: Add storage interface in future commits
-	validators map[string]ValidatorNode
+	validators   map[string]ValidatorNode
+	distribution map[string]*distributionState // Reward accounting and delegations by validator ID
+	params       Params
+	bank         BankKeeper
+	slashLedger  SlashLedger
+	circuit      CircuitKeeper
+}
+
+// StakingRoute is the circuit breaker route guarding delegations
+const StakingRoute = "staking"
+
+// CircuitKeeper reports routes paused by governance
+type CircuitKeeper interface {
+	IsPaused(route string) bool
+}
+
+// BankKeeper moves tokens on behalf of the validator module. When one is
+// configured staked tokens are held in the bonded pool and slashed stake is
+// burned from it.
+type BankKeeper interface {
+	GetBalance(ctx types.Context, address types.Address) types.CoinAmount
+	Transfer(ctx types.Context, from, to types.Address, amount types.CoinAmount) error
+	BurnTokens(ctx types.Context, from types.Address, amount types.CoinAmount) error
 }
 
 // NewValidatorManager creates a new validator manager
 func NewValidatorManager() *ValidatorManager {
 	return &ValidatorManager{
-		validators: make(map[string]ValidatorNode),
+		validators:   make(map[string]ValidatorNode),
+		distribution: make(map[string]*distributionState),
+		params:       DefaultParams(),
+		slashLedger:  NewMemorySlashLedger(),
 	}
 }
 
+// SetBankKeeper sets the keeper used to pay out rewards
+func (vm *ValidatorManager) SetBankKeeper(bank BankKeeper) {
+	vm.bank = bank
+}
+
+// SetSlashLedger sets the ledger slash records are persisted to
+func (vm *ValidatorManager) SetSlashLedger(ledger SlashLedger) {
+	vm.slashLedger = ledger
+}
+
+// SetCircuitKeeper sets the circuit breaker consulted before delegations
+func (vm *ValidatorManager) SetCircuitKeeper(circuit CircuitKeeper) {
+	vm.circuit = circuit
+}
+
+// checkStakingRoute fails while staking is paused by governance
+func (vm *ValidatorManager) checkStakingRoute() error {
+	if vm.circuit != nil && vm.circuit.IsPaused(StakingRoute) {
+		return fmt.Errorf("staking is paused by governance")
+	}
+	return nil
+}
+
+// bondTokens moves staked tokens from an account into the bonded pool
+func (vm *ValidatorManager) bondTokens(ctx types.Context, from types.Address, amount types.Int) error {
+	if vm.bank == nil {
+		return nil
+	}
+	if err := vm.bank.Transfer(ctx, from, types.ModuleAddress(types.BondedPoolName), types.NewUECoins(amount)); err != nil {
+		return fmt.Errorf("failed to bond stake: %v", err)
+	}
+	return nil
+}
+
+// unbondTokens returns staked tokens from the bonded pool to an account
+func (vm *ValidatorManager) unbondTokens(ctx types.Context, to types.Address, amount types.Int) error {
+	if vm.bank == nil {
+		return nil
+	}
+	if err := vm.bank.Transfer(ctx, types.ModuleAddress(types.BondedPoolName), to, types.NewUECoins(amount)); err != nil {
+		return fmt.Errorf("failed to unbond stake: %v", err)
+	}
+	return nil
+}
+
+// GetParams returns the current validator set parameters
+func (vm *ValidatorManager) GetParams() Params {
+	return vm.params
+}
+
+// SetParams updates the validator set parameters. A smaller MaxValidators
+// takes effect at the next epoch boundary.
+func (vm *ValidatorManager) SetParams(params Params) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	vm.params = params
+	return nil
+}
+
 // RegisterNode registers a new validator node
 func (vm *ValidatorManager) RegisterNode(ctx types.Context, node ValidatorNode) error {
 	// Validate minimum stake requirement
-	if !types.IsValidatorEligible(node.StakeAmount) {
-		return fmt.Errorf("insufficient stake: minimum required is %d UE, got %d",
-			types.MinValidatorStake, node.StakeAmount)
+	chainParams := ctx.Params
+	if !chainParams.IsValidatorEligible(node.StakeAmount) {
+		return fmt.Errorf("insufficient stake: minimum required is %s, got %s",
+			types.FormatCoin(types.NewUECoins(chainParams.MinValidatorStake)), types.FormatCoin(types.NewUECoins(node.StakeAmount)))
+	}
+
+	if node.Commission.IsNegative() || node.Commission.GT(types.OneDec()) {
+		return fmt.Errorf("commission must be between 0 and 1, got %s", node.Commission)
 	}
 
 	// Check if validator already exists
@@ -92,15 +235,27 @@ func (vm *ValidatorManager) RegisterNode(ctx types.Context, node ValidatorNode)
 		return fmt.Errorf("validator with ID %s already exists", node.ID)
 	}
 
+	// Bond the self-stake
+	if err := vm.bondTokens(ctx, node.Address, node.StakeAmount); err != nil {
+		return err
+	}
+
 	// Set timestamps
 	node.CreatedAt = time.Now()
 	node.UpdatedAt = time.Now()
 
-	// Set initial status
-	node.Status = ValidatorStatusActive
+	// Join the active set if there is a free slot, otherwise wait for
+	// the next epoch to be ranked against the other candidates
+	if uint32(vm.GetValidatorCount(ctx)) < vm.params.MaxValidators {
+		node.Status = ValidatorStatusActive
+	} else {
+		node.Status = ValidatorStatusCandidate
+	}
 
-	// Store validator
+	// Store validator and start tracking rewards for its self-bond
 	vm.validators[node.ID] = node
+	vm.initializeValidatorRewards(node.ID)
+	vm.initializeDelegation(ctx, node.ID, node.Address, node.StakeAmount)
 
 	return nil
 }
@@ -120,7 +275,19 @@ func (vm *ValidatorManager) DeregisterNode(ctx types.Context, nodeID string) err
 	return nil
 }
 
-// GetActiveValidators returns all active validators
+// GetValidators returns every registered validator, whatever its status,
+// ordered by stake
+func (vm *ValidatorManager) GetValidators(ctx types.Context) []ValidatorNode {
+	validators := make([]ValidatorNode, 0, len(vm.validators))
+	for _, validator := range vm.validators {
+		validators = append(validators, validator)
+	}
+
+	sortByStake(validators)
+	return validators
+}
+
+// GetActiveValidators returns all active validators ordered by stake
 func (vm *ValidatorManager) GetActiveValidators(ctx types.Context) []ValidatorNode {
 	var activeValidators []ValidatorNode
 
@@ -130,101 +297,116 @@ func (vm *ValidatorManager) GetActiveValidators(ctx types.Context) []ValidatorNo
 		}
 	}
 
+	sortByStake(activeValidators)
 	return activeValidators
 }
 
-// GetValidator returns a specific validator
-func (vm *ValidatorManager) GetValidator(ctx types.Context, nodeID string) (ValidatorNode, error) {
-	validator, exists := vm.validators[nodeID]
-	if !exists {
-		return ValidatorNode{}, fmt.Errorf("validator with ID %s not found", nodeID)
+// GetCandidateValidators returns bonded validators waiting outside the active set
+func (vm *ValidatorManager) GetCandidateValidators(ctx types.Context) []ValidatorNode {
+	var candidates []ValidatorNode
+
+	for _, validator := range vm.validators {
+		if validator.Status == ValidatorStatusCandidate {
+			candidates = append(candidates, validator)
+		}
 	}
 
-	return validator, nil
+	sortByStake(candidates)
+	return candidates
 }
 
-// UpdateValidator updates a validator's information
-func (vm *ValidatorManager) UpdateValidator(ctx types.Context, node ValidatorNode) error {
-	if _, exists := vm.validators[node.ID]; !exists {
-		return fmt.Errorf("validator with ID %s not found", node.ID)
+// UpdateValidatorSet ranks all bonded validators by stake and makes the top
+// MaxValidators active. The rest are moved to candidate status. It returns
+// the validators whose status changed.
+func (vm *ValidatorManager) UpdateValidatorSet(ctx types.Context) []ValidatorNode {
+	var bonded []ValidatorNode
+	for _, validator := range vm.validators {
+		if validator.Status == ValidatorStatusActive || validator.Status == ValidatorStatusCandidate {
+			bonded = append(bonded, validator)
+		}
 	}
 
-	node.UpdatedAt = time.Now()
-	vm.validators[node.ID] = node
+	sortByStake(bonded)
 
-	return nil
-}
+	var updates []ValidatorNode
+	for i, validator := range bonded {
+		status := ValidatorStatusCandidate
+		if uint32(i) < vm.params.MaxValidators {
+			status = ValidatorStatusActive
+		}
+		if validator.Status == status {
+			continue
+		}
 
-// CalculateRewards calculates rewards for a validator
-func (vm *ValidatorManager) CalculateRewards(ctx types.Context, nodeID string) uint64 {
-	validator, err := vm.GetValidator(ctx, nodeID)
-	if err != nil {
-		return 0
+		validator.Status = status
+		validator.UpdatedAt = time.Now()
+		vm.validators[validator.ID] = validator
+		updates = append(updates, validator)
 	}
 
-	// Get total stake from all active validators
-	activeValidators := vm.GetActiveValidators(ctx)
-	totalStake := uint64(0)
+	return updates
+}
 
-	for _, v := range activeValidators {
-		totalStake += v.StakeAmount
+// ProcessBlockEnd rotates the active validator set at epoch boundaries and
+// returns the validators whose status changed
+func (vm *ValidatorManager) ProcessBlockEnd(ctx types.Context) []ValidatorNode {
+	if !ctx.Params.IsEpochBoundary(ctx.Height) {
+		return nil
 	}
+	return vm.UpdateValidatorSet(ctx)
+}
 
-	// Calculate reward based on stake proportion
-	// TODO: Implement actual reward calculation logic in future commits
-	blockReward := uint64(1000) // Placeholder value
-
-	return types.CalculateValidatorReward(validator.StakeAmount, totalStake, blockReward)
+// sortByStake orders validators by stake descending, breaking ties by ID
+// so every node derives the same set
+func sortByStake(validators []ValidatorNode) {
+	sort.Slice(validators, func(i, j int) bool {
+		if !validators[i].TotalStake().Equal(validators[j].TotalStake()) {
+			return validators[i].TotalStake().GT(validators[j].TotalStake())
+		}
+		return validators[i].ID < validators[j].ID
+	})
 }
 
-// SlashNode slashes a validator for misbehavior
-func (vm *ValidatorManager) SlashNode(ctx types.Context, nodeID string, reason SlashReason) error {
-	validator, err := vm.GetValidator(ctx, nodeID)
-	if err != nil {
-		return err
+// GetValidator returns a specific validator
+func (vm *ValidatorManager) GetValidator(ctx types.Context, nodeID string) (ValidatorNode, error) {
+	validator, exists := vm.validators[nodeID]
+	if !exists {
+		return ValidatorNode{}, fmt.Errorf("validator with ID %s not found", nodeID)
 	}
 
-	// Calculate slash amount based on reason
-	slashAmount := vm.calculateSlashAmount(validator.StakeAmount, reason)
-
-	// Update validator status and stake
-	validator.Status = ValidatorStatusSlashed
-	validator.StakeAmount -= slashAmount
-	validator.UpdatedAt = time.Now()
+	return validator, nil
+}
 
-	// Ensure minimum stake is maintained
-	if validator.StakeAmount < types.MinValidatorStake {
-		validator.StakeAmount = 0
+// UpdateValidator updates a validator's commission, description and
+// website. Every other field is kept as it is: stake only changes through
+// Delegate, Undelegate and SlashNode so reward accounting stays exact, and
+// status and jailing only change through the validator set, slashing and
+// Unjail.
+func (vm *ValidatorManager) UpdateValidator(ctx types.Context, node ValidatorNode) error {
+	existing, exists := vm.validators[node.ID]
+	if !exists {
+		return fmt.Errorf("validator with ID %s not found", node.ID)
+	}
+	if node.Commission.IsNegative() || node.Commission.GT(types.OneDec()) {
+		return fmt.Errorf("commission must be between 0 and 1, got %s", node.Commission)
 	}
 
-	vm.validators[nodeID] = validator
+	existing.Commission = node.Commission
+	existing.Description = node.Description
+	existing.Website = node.Website
+	existing.UpdatedAt = time.Now()
+	vm.validators[node.ID] = existing
 
 	return nil
 }
 
-// calculateSlashAmount calculates the amount to slash based on the reason
-func (vm *ValidatorManager) calculateSlashAmount(stakeAmount uint64, reason SlashReason) uint64 {
-	switch reason {
-	case SlashReasonDoubleSigning:
-		return stakeAmount / 2 // 50% slash
-	case SlashReasonDowntime:
-		return stakeAmount / 10 // 10% slash
-	case SlashReasonInvalidBlock:
-		return stakeAmount / 4 // 25% slash
-	case SlashReasonEquivocation:
-		return stakeAmount / 2 // 50% slash
-	default:
-		return stakeAmount / 10 // Default 10% slash
-	}
-}
-
 // GetTotalStake returns the total stake of all active validators
-func (vm *ValidatorManager) GetTotalStake(ctx types.Context) uint64 {
+func (vm *ValidatorManager) GetTotalStake(ctx types.Context) types.Int {
 	activeValidators := vm.GetActiveValidators(ctx)
-	totalStake := uint64(0)
+	totalStake := types.ZeroInt()
 
 	for _, validator := range activeValidators {
-		totalStake += validator.StakeAmount
+		totalStake = totalStake.Add(validator.TotalStake())
 	}
 
 	return totalStake
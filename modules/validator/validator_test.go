@@ -0,0 +1,405 @@
+package validator
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/treasury"
+)
+
+func TestUpdateValidatorSet_RanksByStake(t *testing.T) {
+	ctx := testContext(0)
+	vm := NewValidatorManager()
+	if err := vm.SetParams(Params{MaxValidators: 2}); err != nil {
+		t.Fatalf("set params: %v", err)
+	}
+
+	for _, node := range []ValidatorNode{
+		{ID: "val-c", StakeAmount: types.NewInt(30000)},
+		{ID: "val-b", StakeAmount: types.NewInt(30000)},
+		{ID: "val-a", StakeAmount: types.NewInt(29000)},
+		{ID: "val-d", StakeAmount: types.NewInt(50000)},
+	} {
+		if err := vm.RegisterNode(ctx, node); err != nil {
+			t.Fatalf("register %s: %v", node.ID, err)
+		}
+	}
+
+	// The first two registrations fill the free slots
+	if got := vm.GetValidatorCount(ctx); got != 2 {
+		t.Fatalf("expected 2 active validators before epoch, got %d", got)
+	}
+
+	vm.ProcessBlockEnd(ctx.WithHeight(types.EpochDuration))
+
+	active := vm.GetActiveValidators(ctx)
+	if len(active) != 2 || active[0].ID != "val-d" || active[1].ID != "val-b" {
+		t.Fatalf("unexpected active set: %+v", active)
+	}
+
+	candidates := vm.GetCandidateValidators(ctx)
+	if len(candidates) != 2 || candidates[0].ID != "val-c" || candidates[1].ID != "val-a" {
+		t.Fatalf("unexpected candidates: %+v", candidates)
+	}
+}
+
+func TestProcessBlockEnd_OnlyAtEpochBoundary(t *testing.T) {
+	ctx := testContext(0)
+	vm := NewValidatorManager()
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val1", StakeAmount: types.NewInt(30000)})
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val2", StakeAmount: types.NewInt(40000)})
+	vm.SetParams(Params{MaxValidators: 1})
+
+	if updates := vm.ProcessBlockEnd(ctx.WithHeight(types.EpochDuration + 1)); updates != nil {
+		t.Fatalf("expected no updates off epoch boundary, got %+v", updates)
+	}
+
+	updates := vm.ProcessBlockEnd(ctx.WithHeight(2 * types.EpochDuration))
+	if len(updates) != 1 || updates[0].ID != "val1" || updates[0].Status != ValidatorStatusCandidate {
+		t.Fatalf("unexpected updates: %+v", updates)
+	}
+}
+
+func TestAllocateTokens_CommissionAndDelegators(t *testing.T) {
+	ctx := testContext(0)
+	tm := treasury.NewTreasuryManager()
+	vm := NewValidatorManager()
+	vm.SetBankKeeper(tm)
+	vm.SetParams(testRewardParams(types.NewDecWithPrec(1, 1), types.ZeroDec()))
+
+	operator1 := types.Address{1}
+	operator2 := types.Address{2}
+	delegator := types.Address{3}
+	fundAccounts(tm, operator1, operator2, delegator)
+
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val1", Address: operator1, StakeAmount: types.NewInt(30000), Commission: types.NewDecWithPrec(1, 1)})
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val2", Address: operator2, StakeAmount: types.NewInt(40000)})
+	if err := vm.Delegate(ctx, delegator, "val1", types.NewInt(10000)); err != nil {
+		t.Fatalf("delegate: %v", err)
+	}
+
+	tm.MintTokens(ctx, types.ModuleAddress(types.FeeCollectorName), types.NewUECoins(types.NewInt(10000)))
+	if err := vm.AllocateTokens(ctx, "val1"); err != nil {
+		t.Fatalf("allocate tokens: %v", err)
+	}
+
+	// Proposer bonus 1000, then 9000 split 40000:40000 => 4500 each.
+	// val1 accrues 5500: 550 commission and 4950 shared 3:1 between the
+	// self-bond and the delegator.
+	if got := vm.CalculateRewards(ctx, "val1"); !got.Equal(types.NewInt(550 + 3712)) {
+		t.Errorf("val1 pending rewards = %s, want %d", got, 550+3712)
+	}
+
+	// Rewards are withdrawn lazily
+	rewards, err := vm.WithdrawRewards(ctx, operator2, "val2")
+	if err != nil || !rewards.Equal(types.NewInt(4500)) {
+		t.Fatalf("withdraw val2 self-bond = %s, %v; want 4500", rewards, err)
+	}
+	commission, err := vm.WithdrawCommission(ctx, "val1")
+	if err != nil || !commission.Equal(types.NewInt(550)) {
+		t.Fatalf("withdraw val1 commission = %s, %v; want 550", commission, err)
+	}
+	if got := tm.GetBalance(ctx, operator1).Amount; !got.Equal(types.NewInt(testFunds - 30000 + 550)) {
+		t.Errorf("operator1 balance = %s, want %d", got, testFunds-30000+550)
+	}
+}
+
+func TestWithdrawRewards_AcrossSlash(t *testing.T) {
+	ctx := testContext(0)
+	tm := treasury.NewTreasuryManager()
+	vm := NewValidatorManager()
+	vm.SetBankKeeper(tm)
+	params := testRewardParams(types.NewDecWithPrec(1, 1), types.ZeroDec())
+	params.SlashFractionDowntime = types.NewDecWithPrec(1, 1)
+	vm.SetParams(params)
+
+	delegator := types.Address{3}
+	fundAccounts(tm, types.Address{1}, types.Address{2}, delegator)
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val1", Address: types.Address{1}, StakeAmount: types.NewInt(30000), Commission: types.NewDecWithPrec(1, 1)})
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val2", Address: types.Address{2}, StakeAmount: types.NewInt(40000)})
+	vm.Delegate(ctx, delegator, "val1", types.NewInt(10000))
+
+	feeCollector := types.ModuleAddress(types.FeeCollectorName)
+	tm.MintTokens(ctx, feeCollector, types.NewUECoins(types.NewInt(10000)))
+	vm.AllocateTokens(ctx, "val1")
+
+	// A 10% downtime slash reduces the delegation from 10000 to 9000
+	if err := vm.SlashNode(ctx, "val1", SlashReasonDowntime); err != nil {
+		t.Fatalf("slash: %v", err)
+	}
+	delegation, err := vm.GetDelegation(ctx, delegator, "val1")
+	if err != nil || !delegation.Amount.Equal(types.NewInt(9000)) {
+		t.Fatalf("delegation after slash = %+v, %v; want 9000", delegation, err)
+	}
+
+	// Slashed stake is burned from the bonded pool
+	if got := tm.GetBurned(ctx, types.NativeDenom).Amount; !got.Equal(types.NewInt(4000)) {
+		t.Fatalf("burned = %s, want 4000", got)
+	}
+
+	// Jailed validators leave the active set, so val2 takes the whole pool
+	tm.MintTokens(ctx, feeCollector, types.NewUECoins(types.NewInt(10000)))
+	vm.AllocateTokens(ctx, "val2")
+
+	// Before the slash: 4950 * 10000/40000 = 1237.5
+	rewards, err := vm.WithdrawRewards(ctx, delegator, "val1")
+	if err != nil || !rewards.Equal(types.NewInt(1237)) {
+		t.Fatalf("withdraw delegator rewards = %s, %v; want 1237", rewards, err)
+	}
+	if rewards, _ := vm.WithdrawRewards(ctx, delegator, "val1"); !rewards.IsZero() {
+		t.Fatalf("second withdrawal = %s, want 0", rewards)
+	}
+	if got := vm.CalculateRewards(ctx, "val2"); !got.Equal(types.NewInt(4500 + 10000)) {
+		t.Fatalf("val2 pending rewards = %s, want 14500", got)
+	}
+}
+
+func TestAllocateTokens_CommunityTax(t *testing.T) {
+	ctx := testContext(0)
+	tm := treasury.NewTreasuryManager()
+	vm := NewValidatorManager()
+	vm.SetBankKeeper(tm)
+	vm.SetParams(testRewardParams(types.NewDecWithPrec(5, 2), types.NewDecWithPrec(2, 2)))
+	fundAccounts(tm, types.Address{1})
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val1", Address: types.Address{1}, StakeAmount: types.NewInt(30000)})
+
+	tm.MintTokens(ctx, types.ModuleAddress(types.FeeCollectorName), types.NewUECoins(types.NewInt(10000)))
+	if err := vm.AllocateTokens(ctx, "val1"); err != nil {
+		t.Fatalf("allocate tokens: %v", err)
+	}
+
+	if got := tm.GetBalance(ctx, types.ModuleAddress(types.CommunityPoolName)).Amount; !got.Equal(types.NewInt(200)) {
+		t.Errorf("community pool balance = %s, want 200", got)
+	}
+	if got := vm.CalculateRewards(ctx, "val1"); !got.Equal(types.NewInt(9800)) {
+		t.Errorf("val1 pending rewards = %s, want 9800", got)
+	}
+}
+
+func TestSlashWithEvidence_RecordsLedger(t *testing.T) {
+	ctx := testContext(120)
+	vm := NewValidatorManager()
+
+	ledger, err := OpenFileSlashLedger(filepath.Join(t.TempDir(), "data", SlashLedgerFile))
+	if err != nil {
+		t.Fatalf("open ledger: %v", err)
+	}
+	vm.SetSlashLedger(ledger)
+
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val1", Address: types.Address{1}, StakeAmount: types.NewInt(40000)})
+	vm.Delegate(ctx, types.Address{2}, "val1", types.NewInt(10000))
+
+	record, err := vm.SlashWithEvidence(ctx, "val1", SlashReasonInvalidBlock, Evidence{Height: 118, Hash: "0xabc"})
+	if err != nil {
+		t.Fatalf("slash: %v", err)
+	}
+	if !record.SelfStakeAmount.Equal(types.NewInt(10000)) || !record.DelegatedAmount.Equal(types.NewInt(2500)) ||
+		!record.Amount.Equal(types.NewInt(12500)) {
+		t.Fatalf("unexpected slash amounts: %+v", record)
+	}
+
+	// Reopening the ledger restores the history
+	reopened, err := OpenFileSlashLedger(ledger.path)
+	if err != nil {
+		t.Fatalf("reopen ledger: %v", err)
+	}
+	history := reopened.Records("val1")
+	if len(history) != 1 || history[0].InfractionHeight != 118 || history[0].EvidenceHash != "0xabc" ||
+		!history[0].Amount.Equal(record.Amount) || !history[0].Fraction.Equal(record.Fraction) {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if got := vm.GetSlashHistory(ctx, "val1"); len(got) != 1 {
+		t.Fatalf("expected 1 slash record, got %d", len(got))
+	}
+}
+
+func TestSlashWithEvidence_FailureLeavesNoTrace(t *testing.T) {
+	ctx := testContext(50)
+	vm := NewValidatorManager()
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val1", Address: types.Address{1}, StakeAmount: types.NewInt(40000)})
+	vm.Delegate(ctx, types.Address{2}, "val1", types.NewInt(10000))
+
+	// The stake was bonded without a bank, so the pool cannot cover the burn
+	vm.SetBankKeeper(treasury.NewTreasuryManager())
+	if _, err := vm.SlashWithEvidence(ctx, "val1", SlashReasonInvalidBlock, Evidence{Height: 49}); err == nil {
+		t.Fatal("expected the slash to fail")
+	}
+
+	val, _ := vm.GetValidator(ctx, "val1")
+	delegation, _ := vm.GetDelegation(ctx, types.Address{2}, "val1")
+	if !val.StakeAmount.Equal(types.NewInt(40000)) || val.Status != ValidatorStatusActive || !delegation.Amount.Equal(types.NewInt(10000)) {
+		t.Fatalf("failed slash changed stake: %+v, delegation %s", val, delegation.Amount)
+	}
+	if events := vm.distribution["val1"].slashEvents; len(events) != 0 {
+		t.Fatalf("failed slash left slash events %+v", events)
+	}
+	if history := vm.GetSlashHistory(ctx, "val1"); len(history) != 0 {
+		t.Fatalf("failed slash was recorded: %+v", history)
+	}
+}
+
+// failingLedger rejects every record
+type failingLedger struct {
+	*MemorySlashLedger
+}
+
+func (failingLedger) Append(record SlashRecord) error {
+	return errors.New("disk full")
+}
+
+func TestSlashWithEvidence_UnrecordedSlashStaysApplied(t *testing.T) {
+	ctx := testContext(50).WithTimestamp(time.Unix(1700000000, 0))
+	vm := NewValidatorManager()
+	vm.SetSlashLedger(failingLedger{NewMemorySlashLedger()})
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val1", Address: types.Address{1}, StakeAmount: types.NewInt(40000)})
+
+	record, err := vm.SlashWithEvidence(ctx, "val1", SlashReasonInvalidBlock, Evidence{Height: 49})
+	if err == nil {
+		t.Fatal("expected the ledger failure to be reported")
+	}
+	val, _ := vm.GetValidator(ctx, "val1")
+	if !val.StakeAmount.Equal(types.NewInt(40000).Sub(record.SelfStakeAmount)) || val.Status != ValidatorStatusJailed {
+		t.Fatalf("unrecorded slash was not applied: %+v, record %+v", val, record)
+	}
+	if !record.Timestamp.Equal(ctx.Timestamp) {
+		t.Fatalf("record time %s, want the block time %s", record.Timestamp, ctx.Timestamp)
+	}
+}
+
+func TestSlashWithEvidence_JailAndTombstone(t *testing.T) {
+	ctx := testContext(10)
+	vm := NewValidatorManager()
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val1", Address: types.Address{1}, StakeAmount: types.NewInt(40000)})
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val2", Address: types.Address{2}, StakeAmount: types.NewInt(30000)})
+
+	// Downtime jails temporarily
+	vm.SlashNode(ctx, "val1", SlashReasonDowntime)
+	val1, _ := vm.GetValidator(ctx, "val1")
+	if val1.Status != ValidatorStatusJailed || !val1.StakeAmount.Equal(types.NewInt(39600)) {
+		t.Fatalf("unexpected validator after downtime: %+v", val1)
+	}
+	if err := vm.Unjail(ctx, "val1"); err == nil {
+		t.Fatal("expected unjail to fail during jail period")
+	}
+	if err := vm.Unjail(ctx.WithHeight(val1.JailedUntil), "val1"); err != nil {
+		t.Fatalf("unjail: %v", err)
+	}
+
+	// Falling below the minimum stake deactivates without wiping the stake
+	vm.SlashNode(ctx, "val2", SlashReasonInvalidBlock)
+	val2, _ := vm.GetValidator(ctx, "val2")
+	if val2.Status != ValidatorStatusInactive || !val2.StakeAmount.Equal(types.NewInt(22500)) {
+		t.Fatalf("unexpected validator below minimum: %+v", val2)
+	}
+
+	// Double-signing tombstones permanently
+	vm.SlashNode(ctx, "val1", SlashReasonDoubleSigning)
+	if err := vm.Unjail(ctx.WithHeight(1_000_000), "val1"); err == nil {
+		t.Fatal("expected tombstoned validator to stay jailed")
+	}
+}
+
+// testFunds is the balance given to every test account
+const testFunds = 1_000_000
+
+// testContext returns a context at a height with the minimum validator
+// stake lowered to MinValidatorStake aue, keeping test amounts small
+func testContext(height uint64) types.Context {
+	params := types.DefaultChainParams()
+	params.MinValidatorStake = types.NewInt(types.MinValidatorStake)
+	return types.Context{Height: height, Params: params}
+}
+
+// fundAccounts mints testFunds to each address
+func fundAccounts(tm *treasury.TreasuryManager, addresses ...types.Address) {
+	for _, address := range addresses {
+		tm.MintTokens(testContext(0), address, types.NewUECoins(types.NewInt(testFunds)))
+	}
+}
+
+// testRewardParams returns default params with the given reward split
+func testRewardParams(proposerBonus, communityTax types.Dec) Params {
+	params := DefaultParams()
+	params.ProposerBonus = proposerBonus
+	params.CommunityTax = communityTax
+	return params
+}
+
+func TestUpdateValidator_KeepsStatusAndJailing(t *testing.T) {
+	ctx := testContext(10)
+	vm := NewValidatorManager()
+	vm.RegisterNode(ctx, ValidatorNode{ID: "val1", Address: types.Address{1}, StakeAmount: types.NewInt(40000)})
+	vm.SlashNode(ctx, "val1", SlashReasonDoubleSigning)
+	jailed, _ := vm.GetValidator(ctx, "val1")
+
+	update := jailed
+	update.Status = ValidatorStatusActive
+	update.Tombstoned = false
+	update.JailedUntil = 0
+	update.Commission = types.NewDecWithPrec(2, 1)
+	update.Description = "back again"
+	if err := vm.UpdateValidator(ctx, update); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	got, _ := vm.GetValidator(ctx, "val1")
+	if got.Status != ValidatorStatusJailed || !got.Tombstoned || got.JailedUntil != jailed.JailedUntil {
+		t.Fatalf("update changed the jailing of the validator: %+v", got)
+	}
+	if !got.Commission.Equal(update.Commission) || got.Description != "back again" {
+		t.Fatalf("update did not change the editable fields: %+v", got)
+	}
+
+	update.Commission = types.NewDecWithPrec(11, 1)
+	if err := vm.UpdateValidator(ctx, update); err == nil {
+		t.Fatal("expected a commission above 1 to be rejected")
+	}
+}
+
+func TestExecuteTransaction_ValidatorMessages(t *testing.T) {
+	ctx := testContext(0)
+	tm := treasury.NewTreasuryManager()
+	vm := NewValidatorManager()
+	vm.SetBankKeeper(tm)
+
+	operator := types.Address{1}
+	other := types.Address{2}
+	fundAccounts(tm, operator, other)
+
+	send := func(from types.Address, msgType string, content interface{}) error {
+		data, err := types.NewModuleMsg(msgType, content)
+		if err != nil {
+			t.Fatalf("encode %s: %v", msgType, err)
+		}
+		tx := types.NewTransaction(from, types.ModuleAddress(types.ValidatorModuleName), types.NewUECoins(types.ZeroInt()), 1, 1, data, 0)
+		return types.ExecuteModuleTx(ctx, tx, types.ValidatorModuleName, tm, vm.HandleMsg)
+	}
+
+	err := send(operator, MsgTypeCreateValidator, MsgCreateValidator{ID: "val1", Stake: types.NewInt(30000), Commission: types.NewDecWithPrec(1, 1)})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if got := tm.GetBalance(ctx, operator).Amount; !got.Equal(types.NewInt(testFunds - 30000 - 1)) {
+		t.Fatalf("operator balance = %s, want the stake and fee deducted", got)
+	}
+
+	commission := types.NewDecWithPrec(2, 1)
+	if err := send(other, MsgTypeEditValidator, MsgEditValidator{ID: "val1", Commission: &commission}); err == nil {
+		t.Fatal("expected an edit by another account to be rejected")
+	}
+	website := "https://val1.example"
+	if err := send(operator, MsgTypeEditValidator, MsgEditValidator{ID: "val1", Commission: &commission, Website: &website}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	val, _ := vm.GetValidator(ctx, "val1")
+	if !val.Commission.Equal(commission) || val.Website != website || !val.StakeAmount.Equal(types.NewInt(30000)) {
+		t.Fatalf("unexpected validator after edit: %+v", val)
+	}
+
+	if err := send(operator, MsgTypeUnjail, MsgUnjail{ID: "val1"}); err == nil {
+		t.Fatal("expected unjailing a bonded validator to fail")
+	}
+}
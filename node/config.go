@@ -0,0 +1,332 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"undergroundempire/core/types"
+)
+
+// ConfigFile is the file name of the node configuration inside the node's config directory
+const ConfigFile = "config.toml"
+
+// EnvPrefix prefixes the environment variables that override the config
+// file, such as UED_P2P_LISTEN_ADDRESS for p2p.listen_address. UED_HOME
+// selects the node home.
+const EnvPrefix = "UED_"
+
+// P2PConfig defines how the node reaches its peers
+type P2PConfig struct {
+	ListenAddress   string   `toml:"listen_address"`
+	PersistentPeers []string `toml:"persistent_peers"`
+}
+
+// RPCConfig defines the client API of the node
+type RPCConfig struct {
+	ListenAddress             string        `toml:"listen_address"`
+	CORSAllowedOrigins        []string      `toml:"cors_allowed_origins"`         // Origins browsers may call the API from, "*" allows any
+	MaxBodyBytes              int           `toml:"max_body_bytes"`               // Maximum size of a request body
+	TimeoutBroadcastTxCommit  time.Duration `toml:"timeout_broadcast_tx_commit"`  // How long broadcast_tx in commit mode waits for a block
+	MaxSubscriptionsPerClient int           `toml:"max_subscriptions_per_client"` // Event subscriptions of one WebSocket connection
+	SubscriptionBufferSize    int           `toml:"subscription_buffer_size"`     // Events a subscription buffers before it is cancelled as too slow
+}
+
+// ConsensusConfig holds the timeouts of a consensus round. The in-process
+// engine completes a round immediately and only waits TimeoutCommit
+// between blocks; the other timeouts apply once votes cross the network.
+type ConsensusConfig struct {
+	TimeoutPropose   time.Duration `toml:"timeout_propose"`
+	TimeoutPrevote   time.Duration `toml:"timeout_prevote"`
+	TimeoutPrecommit time.Duration `toml:"timeout_precommit"`
+	TimeoutCommit    time.Duration `toml:"timeout_commit"` // 0 waits the chain's BlockTime
+}
+
+// MempoolConfig limits the pending transactions of the node
+type MempoolConfig struct {
+	Size       int `toml:"size"`         // Maximum number of pending transactions
+	MaxTxBytes int `toml:"max_tx_bytes"` // Maximum encoded size of one transaction
+}
+
+// PruningConfig limits the blocks the node keeps in memory. Blocks are
+// never deleted from disk, since the node rebuilds its state by replaying
+// them on start.
+type PruningConfig struct {
+	KeepRecent int `toml:"keep_recent"` // Recent blocks kept in memory, 0 keeps all
+}
+
+// LogConfig defines the node's log output
+type LogConfig struct {
+	Level string `toml:"level"` // debug, info or error
+	File  string `toml:"file"`  // Log file, standard output when empty
+}
+
+// Config is the local configuration of a node. Unlike the genesis
+// document it may differ between the nodes of a network.
+type Config struct {
+	Moniker   string          `toml:"moniker"`
+	P2P       P2PConfig       `toml:"p2p"`
+	RPC       RPCConfig       `toml:"rpc"`
+	Consensus ConsensusConfig `toml:"consensus"`
+	Mempool   MempoolConfig   `toml:"mempool"`
+	Pruning   PruningConfig   `toml:"pruning"`
+	Log       LogConfig       `toml:"log"`
+}
+
+// DefaultConfig returns the configuration of a single node listening on
+// the default ports
+func DefaultConfig() Config {
+	moniker, err := os.Hostname()
+	if err != nil {
+		moniker = "ue-node"
+	}
+
+	return Config{
+		Moniker: moniker,
+		P2P: P2PConfig{
+			ListenAddress:   "0.0.0.0:26656",
+			PersistentPeers: []string{},
+		},
+		RPC: RPCConfig{
+			ListenAddress:             "127.0.0.1:26657",
+			CORSAllowedOrigins:        []string{},
+			MaxBodyBytes:              2 * 1024 * 1024,
+			TimeoutBroadcastTxCommit:  30 * time.Second,
+			MaxSubscriptionsPerClient: 5,
+			SubscriptionBufferSize:    200,
+		},
+		Consensus: ConsensusConfig{
+			TimeoutPropose:   3 * time.Second,
+			TimeoutPrevote:   time.Second,
+			TimeoutPrecommit: time.Second,
+			TimeoutCommit:    0,
+		},
+		Mempool: MempoolConfig{
+			Size:       DefaultMempoolSize,
+			MaxTxBytes: 1024 * 1024,
+		},
+		Pruning: PruningConfig{
+			KeepRecent: 10000,
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+	}
+}
+
+// Validate checks that the configuration is usable
+func (c Config) Validate() error {
+	if c.P2P.ListenAddress == "" {
+		return fmt.Errorf("p2p listen address cannot be empty")
+	}
+	if c.RPC.ListenAddress == "" {
+		return fmt.Errorf("rpc listen address cannot be empty")
+	}
+	if c.RPC.MaxBodyBytes <= 0 {
+		return fmt.Errorf("rpc max body bytes must be positive")
+	}
+	if c.RPC.TimeoutBroadcastTxCommit <= 0 {
+		return fmt.Errorf("rpc broadcast tx commit timeout must be positive")
+	}
+	if c.RPC.MaxSubscriptionsPerClient <= 0 || c.RPC.SubscriptionBufferSize <= 0 {
+		return fmt.Errorf("rpc subscription limits must be positive")
+	}
+	for _, peer := range c.P2P.PersistentPeers {
+		if peer == "" {
+			return fmt.Errorf("persistent peer address cannot be empty")
+		}
+	}
+
+	if c.Consensus.TimeoutPropose <= 0 || c.Consensus.TimeoutPrevote <= 0 || c.Consensus.TimeoutPrecommit <= 0 {
+		return fmt.Errorf("consensus propose, prevote and precommit timeouts must be positive")
+	}
+	if c.Consensus.TimeoutCommit < 0 {
+		return fmt.Errorf("consensus commit timeout cannot be negative")
+	}
+
+	if c.Mempool.Size <= 0 {
+		return fmt.Errorf("mempool size must be positive")
+	}
+	if c.Mempool.MaxTxBytes <= 0 {
+		return fmt.Errorf("mempool max tx bytes must be positive")
+	}
+	if c.Pruning.KeepRecent < 0 {
+		return fmt.Errorf("pruning keep recent cannot be negative")
+	}
+
+	if _, err := types.ParseLogLevel(c.Log.Level); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadConfig loads and validates a node configuration. Settings missing
+// from the file keep their defaults.
+func ReadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config: %v", err)
+	}
+
+	config := DefaultConfig()
+	if err := toml.Unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("corrupt config %s: %v", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// LoadConfig resolves the configuration of a node home: the defaults,
+// overridden by the config file if there is one, overridden by UED_*
+// environment variables. Command-line flags are applied on top by the
+// caller with Set.
+func LoadConfig(home string) (Config, error) {
+	config := DefaultConfig()
+
+	path := filepath.Join(home, "config", ConfigFile)
+	if _, err := os.Stat(path); err == nil {
+		if config, err = ReadConfig(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, key := range ConfigKeys() {
+		if value, ok := os.LookupEnv(ConfigEnvVar(key)); ok {
+			if err := config.Set(key, value); err != nil {
+				return Config{}, fmt.Errorf("invalid %s: %v", ConfigEnvVar(key), err)
+			}
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// Save writes the configuration to a file
+func (c Config) Save(path string) error {
+	data, err := c.Encode()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Encode returns the configuration as TOML
+func (c Config) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ConfigKeys returns the dotted keys of every setting, such as
+// "p2p.listen_address", in sorted order
+func ConfigKeys() []string {
+	var keys []string
+	walkConfig(reflect.ValueOf(&Config{}).Elem(), "", func(key string, _ reflect.Value) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// ConfigEnvVar returns the environment variable overriding a setting
+func ConfigEnvVar(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Get returns a setting as a string, lists joined by commas
+func (c *Config) Get(key string) (string, error) {
+	field, err := c.field(key)
+	if err != nil {
+		return "", err
+	}
+
+	switch value := field.Interface().(type) {
+	case []string:
+		return strings.Join(value, ","), nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// Set parses a value into a setting. Lists are comma-separated and
+// durations use Go syntax, such as "500ms".
+func (c *Config) Set(key, value string) error {
+	field, err := c.field(key)
+	if err != nil {
+		return err
+	}
+
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case []string:
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration for %s: %v", key, err)
+		}
+		field.SetInt(int64(d))
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer for %s: %v", key, err)
+		}
+		field.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported setting %s", key)
+	}
+	return nil
+}
+
+// field returns the settable field of a dotted key
+func (c *Config) field(key string) (reflect.Value, error) {
+	var found reflect.Value
+	walkConfig(reflect.ValueOf(c).Elem(), "", func(k string, v reflect.Value) {
+		if k == key {
+			found = v
+		}
+	})
+	if !found.IsValid() {
+		return reflect.Value{}, fmt.Errorf("unknown config key %s", key)
+	}
+	return found, nil
+}
+
+// walkConfig visits the settings of a config section, keyed by their
+// dotted TOML names
+func walkConfig(section reflect.Value, prefix string, visit func(key string, value reflect.Value)) {
+	for i := 0; i < section.NumField(); i++ {
+		key := prefix + strings.Split(section.Type().Field(i).Tag.Get("toml"), ",")[0]
+		value := section.Field(i)
+		if value.Kind() == reflect.Struct {
+			walkConfig(value, key+".", visit)
+			continue
+		}
+		visit(key, value)
+	}
+}
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EventQuery selects events by conditions joined with AND, such as
+//
+//	event='Tx' AND tx.from='0x…'
+//	event='NewBlock' AND height>=100
+//
+// A condition compares an attribute with a quoted string (= or !=) or with
+// a number (=, !=, <, <=, >, >=). The event attribute is the event type and
+// height its block height. An empty query matches every event.
+type EventQuery struct {
+	source     string
+	conditions []condition
+}
+
+// condition compares one attribute of an event
+type condition struct {
+	key    string
+	op     string
+	value  string
+	number float64
+	isNum  bool
+}
+
+// queryOperators are tried in order, longest first
+var queryOperators = []string{"<=", ">=", "!=", "=", "<", ">"}
+
+// ParseEventQuery parses a query
+func ParseEventQuery(query string) (EventQuery, error) {
+	q := EventQuery{source: strings.TrimSpace(query)}
+	rest := q.source
+	for rest != "" {
+		c, remaining, err := parseCondition(rest)
+		if err != nil {
+			return EventQuery{}, fmt.Errorf("invalid query %q: %v", query, err)
+		}
+		q.conditions = append(q.conditions, c)
+
+		rest = strings.TrimSpace(remaining)
+		if rest == "" {
+			break
+		}
+		if !strings.HasPrefix(rest, "AND ") {
+			return EventQuery{}, fmt.Errorf("invalid query %q: expected AND before %q", query, rest)
+		}
+		rest = strings.TrimSpace(rest[len("AND "):])
+		if rest == "" {
+			return EventQuery{}, fmt.Errorf("invalid query %q: missing condition after AND", query)
+		}
+	}
+	return q, nil
+}
+
+// parseCondition parses the condition at the start of s and returns the
+// rest of s
+func parseCondition(s string) (condition, string, error) {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '.' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if end <= 0 {
+		return condition{}, "", fmt.Errorf("expected an attribute at %q", s)
+	}
+	c := condition{key: s[:end]}
+
+	s = strings.TrimSpace(s[end:])
+	for _, op := range queryOperators {
+		if strings.HasPrefix(s, op) {
+			c.op = op
+			break
+		}
+	}
+	if c.op == "" {
+		return condition{}, "", fmt.Errorf("expected an operator after %s", c.key)
+	}
+	s = strings.TrimSpace(s[len(c.op):])
+
+	if strings.HasPrefix(s, "'") {
+		closing := strings.IndexByte(s[1:], '\'')
+		if closing < 0 {
+			return condition{}, "", fmt.Errorf("unterminated string after %s", c.key)
+		}
+		if c.op != "=" && c.op != "!=" {
+			return condition{}, "", fmt.Errorf("%s compares a string with %s; strings only support = and !=", c.key, c.op)
+		}
+		c.value = s[1 : closing+1]
+		return c, s[closing+2:], nil
+	}
+
+	end = strings.IndexByte(s, ' ')
+	if end < 0 {
+		end = len(s)
+	}
+	number, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return condition{}, "", fmt.Errorf("%s must be compared with a quoted string or a number, got %q", c.key, s[:end])
+	}
+	c.value, c.number, c.isNum = s[:end], number, true
+	return c, s[end:], nil
+}
+
+// String returns the query as it was parsed
+func (q EventQuery) String() string {
+	return q.source
+}
+
+// Matches reports whether an event meets every condition of the query
+func (q EventQuery) Matches(event Event) bool {
+	for _, c := range q.conditions {
+		var value string
+		switch c.key {
+		case "event":
+			value = event.Type
+		case "height":
+			value = strconv.FormatUint(event.Height, 10)
+		default:
+			var exists bool
+			if value, exists = event.Attributes[c.key]; !exists {
+				return false
+			}
+		}
+		if !c.matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches compares a value with the condition
+func (c condition) matches(value string) bool {
+	if !c.isNum {
+		return (value == c.value) == (c.op == "=")
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	switch c.op {
+	case "=":
+		return number == c.number
+	case "!=":
+		return number != c.number
+	case "<":
+		return number < c.number
+	case "<=":
+		return number <= c.number
+	case ">":
+		return number > c.number
+	default:
+		return number >= c.number
+	}
+}
@@ -0,0 +1,265 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/governance"
+	"undergroundempire/modules/validator"
+)
+
+// Event types published by the node once a block is committed
+const (
+	EventNewBlock              = "NewBlock"
+	EventTx                    = "Tx"
+	EventVote                  = "Vote" // A governance vote
+	EventValidatorSetUpdate    = "ValidatorSetUpdate"
+	EventSlash                 = "Slash"
+	EventProposalStatusChanged = "ProposalStatusChanged"
+)
+
+// ErrSlowSubscriber cancels a subscription whose buffer is full
+var ErrSlowSubscriber = errors.New("subscriber is not reading events fast enough")
+
+// Event is something that happened in a committed block. Queries match
+// its type, its height and its attributes.
+type Event struct {
+	Type       string
+	Height     uint64
+	Attributes map[string]string // Such as tx.from or proposal.id
+	Data       interface{}       // The block, transaction, vote, validators, slash or status change
+}
+
+// VoteEvent is the data of a Vote event
+type VoteEvent struct {
+	ProposalID uint64
+	Voter      types.Address
+	Option     types.VoteOption
+}
+
+// ProposalStatusChange is the data of a ProposalStatusChanged event
+type ProposalStatusChange struct {
+	ProposalID     uint64
+	PreviousStatus ProposalStatus
+	Status         ProposalStatus
+}
+
+// Subscription receives the events matching a query until it is
+// unsubscribed or cancelled
+type Subscription struct {
+	query  EventQuery
+	events chan Event
+	err    error
+}
+
+// Events returns the matching events. The channel is closed when the
+// subscription ends.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns why the subscription ended, nil if it was unsubscribed. It
+// is only meaningful once Events is closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Query returns the query of the subscription
+func (s *Subscription) Query() EventQuery {
+	return s.query
+}
+
+// EventBus delivers published events to subscriptions. Publishing never
+// blocks: each subscription buffers a bounded number of events, and one
+// that falls behind is cancelled with ErrSlowSubscriber so it cannot stall
+// block production.
+type EventBus struct {
+	mu            sync.Mutex
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewEventBus creates an event bus without subscriptions
+func NewEventBus() *EventBus {
+	return &EventBus{subscriptions: make(map[*Subscription]struct{})}
+}
+
+// Subscribe registers a subscription buffering up to capacity events
+func (b *EventBus) Subscribe(query EventQuery, capacity int) (*Subscription, error) {
+	if capacity < 1 {
+		return nil, errors.New("subscription capacity must be positive")
+	}
+	sub := &Subscription{query: query, events: make(chan Event, capacity)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions[sub] = struct{}{}
+	return sub, nil
+}
+
+// Unsubscribe ends a subscription. Ending one twice has no effect.
+func (b *EventBus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.end(sub, nil)
+}
+
+// end removes a subscription and closes its channel. Callers must hold
+// b.mu.
+func (b *EventBus) end(sub *Subscription, err error) {
+	if _, exists := b.subscriptions[sub]; !exists {
+		return
+	}
+	delete(b.subscriptions, sub)
+	sub.err = err
+	close(sub.events)
+}
+
+// NumSubscriptions returns the number of active subscriptions
+func (b *EventBus) NumSubscriptions() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscriptions)
+}
+
+// Publish delivers events, in order, to the subscriptions whose query
+// they match
+func (b *EventBus) Publish(events ...Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, event := range events {
+		for sub := range b.subscriptions {
+			if !sub.query.Matches(event) {
+				continue
+			}
+			select {
+			case sub.events <- event:
+			default:
+				b.end(sub, ErrSlowSubscriber)
+			}
+		}
+	}
+}
+
+// blockEvents returns the events of a committed block: the block itself,
+// then its transactions and the governance votes among them
+func blockEvents(block types.BlockData, results []TxResult) []Event {
+	events := []Event{{
+		Type:   EventNewBlock,
+		Height: block.Height,
+		Attributes: map[string]string{
+			"block.hash":     block.Hash,
+			"block.proposer": block.Proposer,
+			"block.txs":      strconv.Itoa(len(block.Transactions)),
+		},
+		Data: block,
+	}}
+
+	for i, tx := range block.Transactions {
+		result := results[i]
+		events = append(events, Event{
+			Type:   EventTx,
+			Height: block.Height,
+			Attributes: map[string]string{
+				"tx.hash":    tx.Hash,
+				"tx.from":    tx.From.String(),
+				"tx.to":      tx.To.String(),
+				"tx.nonce":   strconv.FormatUint(tx.Nonce, 10),
+				"tx.success": strconv.FormatBool(result.Error == ""),
+			},
+			Data: CommittedTx{Tx: tx, Result: result},
+		})
+
+		if vote, ok := decodeVote(tx); ok && result.Error == "" {
+			events = append(events, Event{
+				Type:   EventVote,
+				Height: block.Height,
+				Attributes: map[string]string{
+					"vote.proposal_id": strconv.FormatUint(vote.ProposalID, 10),
+					"vote.voter":       vote.Voter.String(),
+					"vote.option":      string(vote.Option),
+				},
+				Data: vote,
+			})
+		}
+	}
+	return events
+}
+
+// decodeVote returns the governance vote carried by a transaction, if any
+func decodeVote(tx types.Transaction) (VoteEvent, bool) {
+	if tx.To != types.ModuleAddress(types.GovernanceModuleName) {
+		return VoteEvent{}, false
+	}
+	var msg types.ModuleMsg
+	if err := json.Unmarshal(tx.Data, &msg); err != nil || msg.Type != governance.MsgTypeVote {
+		return VoteEvent{}, false
+	}
+	var vote governance.MsgVote
+	if err := msg.Decode(&vote); err != nil {
+		return VoteEvent{}, false
+	}
+	return VoteEvent{ProposalID: vote.ProposalID, Voter: tx.From, Option: vote.Option}, true
+}
+
+// validatorSetEvent returns the event of a validator set update
+func validatorSetEvent(height uint64, updates []validator.ValidatorNode) Event {
+	return Event{
+		Type:       EventValidatorSetUpdate,
+		Height:     height,
+		Attributes: map[string]string{"validator_set.updates": strconv.Itoa(len(updates))},
+		Data:       updates,
+	}
+}
+
+// slashEvent returns the event of a slash
+func slashEvent(record validator.SlashRecord) Event {
+	return Event{
+		Type:   EventSlash,
+		Height: record.Height,
+		Attributes: map[string]string{
+			"slash.validator":         record.ValidatorID,
+			"slash.reason":            string(record.Reason),
+			"slash.infraction_height": strconv.FormatUint(record.InfractionHeight, 10),
+		},
+		Data: record,
+	}
+}
+
+// proposalStatusEvent returns the event of a proposal changing status
+func proposalStatusEvent(height uint64, change ProposalStatusChange) Event {
+	return Event{
+		Type:   EventProposalStatusChanged,
+		Height: height,
+		Attributes: map[string]string{
+			"proposal.id":              strconv.FormatUint(change.ProposalID, 10),
+			"proposal.status":          string(change.Status),
+			"proposal.previous_status": string(change.PreviousStatus),
+		},
+		Data: change,
+	}
+}
+
+// eventSlashLedger records slashes for the events of the block being
+// executed before appending them to the node's ledger
+type eventSlashLedger struct {
+	validator.SlashLedger
+	app *UEApp
+}
+
+// Append appends a record and queues its Slash event. It is called while
+// a block executes, with app.mu held. Slashes of replayed blocks are
+// already in the ledger and are not appended again.
+func (l eventSlashLedger) Append(record validator.SlashRecord) error {
+	if l.app.replaying {
+		return nil
+	}
+	if err := l.SlashLedger.Append(record); err != nil {
+		return err
+	}
+	l.app.pending = append(l.app.pending, slashEvent(record))
+	return nil
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"testing"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/governance"
+)
+
+func TestEventQuery(t *testing.T) {
+	event := Event{
+		Type:       EventTx,
+		Height:     12,
+		Attributes: map[string]string{"tx.from": "0xab", "tx.nonce": "3"},
+	}
+
+	cases := []struct {
+		query   string
+		matches bool
+	}{
+		{"", true},
+		{"event='Tx'", true},
+		{"event='NewBlock'", false},
+		{"event='Tx' AND tx.from='0xab'", true},
+		{"event = 'Tx' AND tx.from != '0xab'", false},
+		{"height>=12 AND height<13 AND tx.nonce=3", true},
+		{"height>12", false},
+		{"tx.to='0xab'", false},
+	}
+	for _, c := range cases {
+		query, err := ParseEventQuery(c.query)
+		if err != nil {
+			t.Fatalf("parse %q: %v", c.query, err)
+		}
+		if got := query.Matches(event); got != c.matches {
+			t.Errorf("%q matches = %v, want %v", c.query, got, c.matches)
+		}
+	}
+
+	for _, invalid := range []string{"event", "event='Tx", "event='Tx' tx.from='0xab'", "event='Tx' AND", "height>'12'", "height=twelve"} {
+		if _, err := ParseEventQuery(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestEventBusCancelsSlowSubscribers(t *testing.T) {
+	bus := NewEventBus()
+	blocks, _ := ParseEventQuery("event='NewBlock'")
+	slow, err := bus.Subscribe(blocks, 2)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	fast, _ := bus.Subscribe(EventQuery{}, 10)
+
+	for height := uint64(1); height <= 3; height++ {
+		bus.Publish(Event{Type: EventNewBlock, Height: height}, Event{Type: EventTx, Height: height})
+	}
+
+	// The slow subscription keeps what it buffered, then ends
+	var heights []uint64
+	for event := range slow.Events() {
+		heights = append(heights, event.Height)
+	}
+	if len(heights) != 2 || heights[0] != 1 || heights[1] != 2 || slow.Err() != ErrSlowSubscriber {
+		t.Fatalf("slow subscription received %v and ended with %v", heights, slow.Err())
+	}
+
+	if len(fast.Events()) != 6 || bus.NumSubscriptions() != 1 {
+		t.Fatalf("fast subscription buffered %d events, %d subscriptions left", len(fast.Events()), bus.NumSubscriptions())
+	}
+	bus.Unsubscribe(fast)
+	bus.Unsubscribe(fast)
+	if bus.NumSubscriptions() != 0 || fast.Err() != nil {
+		t.Fatalf("unsubscribe: %d subscriptions left, err %v", bus.NumSubscriptions(), fast.Err())
+	}
+}
+
+func TestBlockEventsIncludeVotes(t *testing.T) {
+	data, err := types.NewModuleMsg(governance.MsgTypeVote, governance.MsgVote{ProposalID: 7, Option: types.VoteOptionYes})
+	if err != nil {
+		t.Fatalf("new msg: %v", err)
+	}
+	voter := types.Address{0x01}
+	vote := types.Transaction{Hash: "0xvote", From: voter, To: types.ModuleAddress(types.GovernanceModuleName), Data: data}
+	failed := types.Transaction{Hash: "0xfailed", From: voter, To: vote.To, Data: data, Nonce: 1}
+	block := types.BlockData{Height: 4, Hash: "0xblock", Transactions: []types.Transaction{vote, failed}}
+
+	events := blockEvents(block, []TxResult{{Height: 4}, {Height: 4, Index: 1, Error: "voting period ended"}})
+	var kinds []string
+	for _, event := range events {
+		kinds = append(kinds, event.Type)
+	}
+	if len(events) != 4 || kinds[0] != EventNewBlock || kinds[1] != EventTx || kinds[2] != EventVote || kinds[3] != EventTx {
+		t.Fatalf("unexpected events %v", kinds)
+	}
+	if events[2].Attributes["vote.proposal_id"] != "7" || events[2].Attributes["vote.voter"] != voter.String() {
+		t.Fatalf("unexpected vote attributes %v", events[2].Attributes)
+	}
+	if events[3].Attributes["tx.success"] != "false" {
+		t.Fatalf("expected the failed transaction to be marked, got %v", events[3].Attributes)
+	}
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"undergroundempire/core/crypto"
+	"undergroundempire/core/types"
+	"undergroundempire/modules/governance"
+	"undergroundempire/modules/mint"
+	"undergroundempire/modules/tokenfactory"
+	"undergroundempire/modules/validator"
+)
+
+// GenesisFile is the file name of the genesis document inside the node's config directory
+const GenesisFile = "genesis.json"
+
+// GenesisAccount is an account funded at genesis
+type GenesisAccount struct {
+	Address types.Address
+	Coins   types.Coins
+}
+
+// GenesisValidator is a validator bonded at genesis. Its stake is bonded
+// from the balance of its operator account, which must be funded.
+type GenesisValidator struct {
+	ID         string
+	Address    types.Address
+	PubKey     crypto.PubKey // Consensus key, set by genesis transactions
+	Stake      types.Int
+	Commission types.Dec
+}
+
+// GenesisParams holds the initial parameters of every module
+type GenesisParams struct {
+	Chain        types.ChainParams
+	Validator    validator.Params
+	Mint         mint.Params
+	Governance   governance.Params
+	TokenFactory tokenfactory.Params
+}
+
+// GenesisDoc defines the initial state of a chain
+type GenesisDoc struct {
+	ChainID     string
+	GenesisTime time.Time
+	Accounts    []GenesisAccount
+	Validators  []GenesisValidator
+	Params      GenesisParams
+}
+
+// DefaultGenesis returns a genesis document with default parameters and no
+// accounts or validators. An empty chain ID selects types.DefaultChainID.
+func DefaultGenesis(chainID string) GenesisDoc {
+	if chainID == "" {
+		chainID = types.DefaultChainID
+	}
+
+	return GenesisDoc{
+		ChainID:     chainID,
+		GenesisTime: time.Now().UTC().Truncate(time.Second),
+		Accounts:    []GenesisAccount{},
+		Validators:  []GenesisValidator{},
+		Params: GenesisParams{
+			Chain:        types.DefaultChainParams(),
+			Validator:    validator.DefaultParams(),
+			Mint:         mint.DefaultParams(),
+			Governance:   governance.DefaultParams(),
+			TokenFactory: tokenfactory.DefaultParams(),
+		},
+	}
+}
+
+// Validate checks that the genesis document describes a consistent chain
+func (g GenesisDoc) Validate() error {
+	if g.ChainID == "" {
+		return fmt.Errorf("chain ID cannot be empty")
+	}
+	if g.GenesisTime.IsZero() {
+		return fmt.Errorf("genesis time cannot be empty")
+	}
+
+	if err := g.Params.Chain.Validate(); err != nil {
+		return fmt.Errorf("invalid chain params: %v", err)
+	}
+	if err := g.Params.Validator.Validate(); err != nil {
+		return fmt.Errorf("invalid validator params: %v", err)
+	}
+	if err := g.Params.Mint.Validate(); err != nil {
+		return fmt.Errorf("invalid mint params: %v", err)
+	}
+	if err := g.Params.Governance.Validate(); err != nil {
+		return fmt.Errorf("invalid governance params: %v", err)
+	}
+	if err := g.Params.TokenFactory.Validate(); err != nil {
+		return fmt.Errorf("invalid token factory params: %v", err)
+	}
+
+	balances := make(map[types.Address]types.Coins)
+	for _, account := range g.Accounts {
+		if account.Address.IsZero() {
+			return fmt.Errorf("genesis account address cannot be zero")
+		}
+		if _, exists := balances[account.Address]; exists {
+			return fmt.Errorf("duplicate genesis account %s", account.Address)
+		}
+		if err := account.Coins.Validate(); err != nil {
+			return fmt.Errorf("invalid coins for genesis account %s: %v", account.Address, err)
+		}
+		balances[account.Address] = account.Coins
+	}
+
+	ids := make(map[string]bool)
+	for _, val := range g.Validators {
+		if val.ID == "" {
+			return fmt.Errorf("genesis validator ID cannot be empty")
+		}
+		if ids[val.ID] {
+			return fmt.Errorf("duplicate genesis validator %s", val.ID)
+		}
+		ids[val.ID] = true
+
+		if !g.Params.Chain.IsValidatorEligible(val.Stake) {
+			return fmt.Errorf("genesis validator %s stake %s is below the minimum %s", val.ID,
+				types.FormatCoin(types.NewUECoins(val.Stake)), types.FormatCoin(types.NewUECoins(g.Params.Chain.MinValidatorStake)))
+		}
+		if val.Commission.IsNegative() || val.Commission.GT(types.OneDec()) {
+			return fmt.Errorf("genesis validator %s commission must be between 0 and 1, got %s", val.ID, val.Commission)
+		}
+
+		// Each stake is bonded from the operator's genesis balance
+		remaining, err := balances[val.Address].Sub(types.Coins{types.NewUECoins(val.Stake)})
+		if err != nil {
+			return fmt.Errorf("genesis account %s cannot fund the stake of validator %s: %v", val.Address, val.ID, err)
+		}
+		balances[val.Address] = remaining
+	}
+
+	return nil
+}
+
+// ReadGenesis loads and validates a genesis document
+func ReadGenesis(path string) (GenesisDoc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GenesisDoc{}, fmt.Errorf("failed to read genesis: %v", err)
+	}
+
+	var genesis GenesisDoc
+	if err := json.Unmarshal(data, &genesis); err != nil {
+		return GenesisDoc{}, fmt.Errorf("corrupt genesis %s: %v", path, err)
+	}
+	if err := genesis.Validate(); err != nil {
+		return GenesisDoc{}, fmt.Errorf("invalid genesis %s: %v", path, err)
+	}
+	return genesis, nil
+}
+
+// Save writes the genesis document to a file
+func (g GenesisDoc) Save(path string) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create genesis directory: %v", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
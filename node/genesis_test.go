@@ -0,0 +1,189 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"undergroundempire/core/crypto"
+	"undergroundempire/core/types"
+)
+
+func testGenesis() GenesisDoc {
+	genesis := DefaultGenesis("")
+	operator := types.Address{1}
+	stake := genesis.Params.Chain.MinValidatorStake
+
+	genesis.Accounts = []GenesisAccount{
+		{Address: operator, Coins: types.Coins{types.NewUECoins(stake.Add(types.NewUEAmount(1000)))}},
+		{Address: types.Address{2}, Coins: types.Coins{types.NewUECoins(types.NewUEAmount(500))}},
+	}
+	genesis.Validators = []GenesisValidator{
+		{ID: "val1", Address: operator, Stake: stake, Commission: types.NewDecWithPrec(5, 2)},
+	}
+	return genesis
+}
+
+func TestGenesisRoundTripAndInitialize(t *testing.T) {
+	genesis := testGenesis()
+	if genesis.ChainID != types.DefaultChainID {
+		t.Fatalf("expected default chain ID, got %s", genesis.ChainID)
+	}
+
+	path := filepath.Join(t.TempDir(), "config", GenesisFile)
+	if err := genesis.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := ReadGenesis(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	app := NewUEApp("test")
+	if err := app.InitializeChain(loaded); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	if app.GetChainID() != types.DefaultChainID {
+		t.Fatalf("unexpected chain ID %s", app.GetChainID())
+	}
+
+	ctx := types.Context{Params: app.params.GetChainParams()}
+	if got := app.treasury.GetBalance(ctx, types.Address{1}).Amount; !got.Equal(types.NewUEAmount(1000)) {
+		t.Fatalf("operator balance after bonding = %s, want 1000UE", got)
+	}
+	active := app.validators.GetActiveValidators(ctx)
+	if len(active) != 1 || !active[0].StakeAmount.Equal(genesis.Params.Chain.MinValidatorStake) {
+		t.Fatalf("unexpected validators: %+v", active)
+	}
+
+	if err := app.InitializeChain(loaded); err == nil {
+		t.Fatal("expected a second initialization to fail")
+	}
+}
+
+func TestGenesisValidate(t *testing.T) {
+	cases := map[string]func(*GenesisDoc){
+		"empty chain ID":     func(g *GenesisDoc) { g.ChainID = "" },
+		"duplicate account":  func(g *GenesisDoc) { g.Accounts = append(g.Accounts, g.Accounts[0]) },
+		"stake below min":    func(g *GenesisDoc) { g.Validators[0].Stake = types.NewUEAmount(1) },
+		"unfunded validator": func(g *GenesisDoc) { g.Validators[0].Address = types.Address{9} },
+		"invalid params":     func(g *GenesisDoc) { g.Params.Chain.ConsensusThreshold = 50 },
+	}
+
+	for name, corrupt := range cases {
+		genesis := testGenesis()
+		corrupt(&genesis)
+		if err := genesis.Validate(); err == nil {
+			t.Errorf("%s: expected genesis to be rejected", name)
+		}
+	}
+}
+
+func TestCollectGenTxs(t *testing.T) {
+	genesis := DefaultGenesis("")
+	stake := genesis.Params.Chain.MinValidatorStake
+	dir := t.TempDir()
+
+	for _, id := range []string{"val1", "val2"} {
+		priv, _ := crypto.GenerateKey()
+		tx := NewGenTx(priv, MsgCreateValidator{ChainID: genesis.ChainID, ID: id, Stake: stake, Commission: types.NewDecWithPrec(1, 1)})
+		if err := genesis.AddAccount(tx.Msg.Address, types.Coins{types.NewUECoins(stake)}); err != nil {
+			t.Fatalf("add account: %v", err)
+		}
+		if err := tx.Save(filepath.Join(dir, "gentx-"+id+".json")); err != nil {
+			t.Fatalf("save gentx: %v", err)
+		}
+	}
+
+	count, err := genesis.CollectGenTxs(dir)
+	if err != nil || count != 2 || len(genesis.Validators) != 2 {
+		t.Fatalf("collect: count=%d validators=%d err=%v", count, len(genesis.Validators), err)
+	}
+	if err := NewUEApp("test").InitializeChain(genesis); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+
+	// A second collection duplicates the validators and leaves the genesis untouched
+	if _, err := genesis.CollectGenTxs(dir); err == nil || len(genesis.Validators) != 2 {
+		t.Fatalf("expected duplicate gentxs to be rejected, err=%v", err)
+	}
+}
+
+func TestGenTxRejected(t *testing.T) {
+	priv, _ := crypto.GenerateKey()
+	valid := func() (GenesisDoc, GenTx) {
+		genesis := DefaultGenesis("")
+		stake := genesis.Params.Chain.MinValidatorStake
+		tx := NewGenTx(priv, MsgCreateValidator{ChainID: genesis.ChainID, ID: "val1", Stake: stake})
+		_ = genesis.AddAccount(tx.Msg.Address, types.Coins{types.NewUECoins(stake)})
+		return genesis, tx
+	}
+
+	genesis, tx := valid()
+	if err := genesis.AddGenTx(tx); err != nil {
+		t.Fatalf("expected valid gentx to be accepted: %v", err)
+	}
+
+	cases := map[string]func(*GenesisDoc, *GenTx){
+		"tampered stake": func(g *GenesisDoc, tx *GenTx) { tx.Msg.Stake = tx.Msg.Stake.Add(types.NewInt(1)) },
+		"wrong chain":    func(g *GenesisDoc, tx *GenTx) { g.ChainID = "other-chain" },
+		"stake below min": func(g *GenesisDoc, tx *GenTx) {
+			*tx = NewGenTx(priv, MsgCreateValidator{ChainID: g.ChainID, ID: "val1", Stake: types.NewUEAmount(1)})
+		},
+		"unfunded stake": func(g *GenesisDoc, tx *GenTx) { g.Accounts = nil },
+	}
+
+	for name, corrupt := range cases {
+		genesis, tx := valid()
+		corrupt(&genesis, &tx)
+		if err := genesis.AddGenTx(tx); err == nil {
+			t.Errorf("%s: expected gentx to be rejected", name)
+		}
+		if len(genesis.Validators) != 0 {
+			t.Errorf("%s: rejected gentx modified the genesis", name)
+		}
+	}
+}
+
+func TestInitTestnet(t *testing.T) {
+	opts := DefaultTestnetOptions()
+	opts.Validators = 3
+	opts.OutputDir = t.TempDir()
+
+	nodes, err := InitTestnet(opts)
+	if err != nil {
+		t.Fatalf("init testnet: %v", err)
+	}
+
+	ports := make(map[string]bool)
+	for _, node := range nodes {
+		genesis, err := ReadGenesis(filepath.Join(node.Home, "config", GenesisFile))
+		if err != nil {
+			t.Fatalf("%s: %v", node.Moniker, err)
+		}
+		if len(genesis.Validators) != 3 || genesis.ChainID != opts.ChainID {
+			t.Fatalf("%s: unexpected genesis with %d validators on %s", node.Moniker, len(genesis.Validators), genesis.ChainID)
+		}
+
+		config, err := ReadConfig(filepath.Join(node.Home, "config", ConfigFile))
+		if err != nil {
+			t.Fatalf("%s: %v", node.Moniker, err)
+		}
+		if len(config.P2P.PersistentPeers) != 0 {
+			t.Fatalf("%s: expected no peers without peer networking, got %v", node.Moniker, config.P2P.PersistentPeers)
+		}
+		for _, addr := range []string{config.P2P.ListenAddress, config.RPC.ListenAddress} {
+			if ports[addr] {
+				t.Fatalf("%s: address %s assigned twice", node.Moniker, addr)
+			}
+			ports[addr] = true
+		}
+
+		if err := NewUEApp("test").InitializeChain(genesis); err != nil {
+			t.Fatalf("%s: initialize: %v", node.Moniker, err)
+		}
+	}
+
+	if _, err := InitTestnet(opts); err == nil {
+		t.Fatal("expected a non-empty output directory to be rejected")
+	}
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"undergroundempire/core/crypto"
+	"undergroundempire/core/types"
+)
+
+// ValidatorKeyFile is the file name of the validator's operator key inside
+// the node's config directory
+const ValidatorKeyFile = "validator_key.json"
+
+// GenTxDir is the directory, inside the node's config directory, holding
+// the genesis transactions collected for a launch
+const GenTxDir = "gentx"
+
+// MsgCreateValidator registers a validator bonded at genesis
+type MsgCreateValidator struct {
+	ChainID    string
+	ID         string
+	Address    types.Address // Operator account, derived from PubKey
+	PubKey     crypto.PubKey
+	Stake      types.Int
+	Commission types.Dec
+}
+
+// GenTx is a create-validator message signed offline by its operator
+type GenTx struct {
+	Msg       MsgCreateValidator
+	Signature []byte
+}
+
+// SignBytes returns the bytes the operator signs
+func (msg MsgCreateValidator) SignBytes() []byte {
+	data, _ := json.Marshal(msg)
+	return data
+}
+
+// NewGenTx signs a create-validator message with the operator key. The
+// operator address is derived from the key.
+func NewGenTx(priv crypto.PrivKey, msg MsgCreateValidator) GenTx {
+	msg.PubKey = priv.PubKey()
+	msg.Address = msg.PubKey.Address()
+	return GenTx{Msg: msg, Signature: priv.Sign(msg.SignBytes())}
+}
+
+// Verify checks the signature of the genesis transaction and that it
+// belongs to the chain
+func (tx GenTx) Verify(chainID string) error {
+	if tx.Msg.ChainID != chainID {
+		return fmt.Errorf("gentx of %s is for chain %s, not %s", tx.Msg.ID, tx.Msg.ChainID, chainID)
+	}
+	if tx.Msg.PubKey.Address() != tx.Msg.Address {
+		return fmt.Errorf("gentx of %s: address %s does not match the public key", tx.Msg.ID, tx.Msg.Address)
+	}
+	if !tx.Msg.PubKey.Verify(tx.Msg.SignBytes(), tx.Signature) {
+		return fmt.Errorf("gentx of %s has an invalid signature", tx.Msg.ID)
+	}
+	return nil
+}
+
+// Save writes the genesis transaction to a file
+func (tx GenTx) Save(path string) error {
+	data, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create gentx directory: %v", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ReadGenTx loads a genesis transaction
+func ReadGenTx(path string) (GenTx, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GenTx{}, fmt.Errorf("failed to read gentx: %v", err)
+	}
+
+	var tx GenTx
+	if err := json.Unmarshal(data, &tx); err != nil {
+		return GenTx{}, fmt.Errorf("corrupt gentx %s: %v", path, err)
+	}
+	return tx, nil
+}
+
+// AddAccount funds an account in the genesis document, merging with any
+// balance it already has
+func (g *GenesisDoc) AddAccount(address types.Address, coins types.Coins) error {
+	if address.IsZero() {
+		return fmt.Errorf("genesis account address cannot be zero")
+	}
+	if err := coins.Validate(); err != nil {
+		return err
+	}
+
+	for i, account := range g.Accounts {
+		if account.Address == address {
+			merged, err := account.Coins.SafeAdd(coins)
+			if err != nil {
+				return err
+			}
+			g.Accounts[i].Coins = merged
+			return nil
+		}
+	}
+
+	g.Accounts = append(g.Accounts, GenesisAccount{Address: address, Coins: coins})
+	return nil
+}
+
+// AddGenTx verifies a genesis transaction and adds its validator to the
+// genesis document. The stake must meet MinValidatorStake and be covered
+// by the operator's genesis balance.
+func (g *GenesisDoc) AddGenTx(tx GenTx) error {
+	if err := tx.Verify(g.ChainID); err != nil {
+		return err
+	}
+
+	if !g.Params.Chain.IsValidatorEligible(tx.Msg.Stake) {
+		return fmt.Errorf("gentx of %s stakes %s, below the minimum %s", tx.Msg.ID,
+			types.FormatCoin(types.NewUECoins(tx.Msg.Stake)), types.FormatCoin(types.NewUECoins(g.Params.Chain.MinValidatorStake)))
+	}
+	for _, val := range g.Validators {
+		if val.ID == tx.Msg.ID || val.Address == tx.Msg.Address {
+			return fmt.Errorf("gentx of %s duplicates genesis validator %s", tx.Msg.ID, val.ID)
+		}
+	}
+
+	updated := *g
+	updated.Validators = append(append([]GenesisValidator{}, g.Validators...), GenesisValidator{
+		ID:         tx.Msg.ID,
+		Address:    tx.Msg.Address,
+		PubKey:     tx.Msg.PubKey,
+		Stake:      tx.Msg.Stake,
+		Commission: tx.Msg.Commission,
+	})
+	if err := updated.Validate(); err != nil {
+		return fmt.Errorf("gentx of %s: %v", tx.Msg.ID, err)
+	}
+
+	*g = updated
+	return nil
+}
+
+// CollectGenTxs adds the validators of every genesis transaction in a
+// directory, in file name order. Nothing is added if any of them is invalid.
+func (g *GenesisDoc) CollectGenTxs(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read gentx directory: %v", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+
+	updated := *g
+	for _, file := range files {
+		tx, err := ReadGenTx(filepath.Join(dir, file))
+		if err != nil {
+			return 0, err
+		}
+		if err := updated.AddGenTx(tx); err != nil {
+			return 0, fmt.Errorf("%s: %v", file, err)
+		}
+	}
+
+	*g = updated
+	return len(files), nil
+}
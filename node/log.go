@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"undergroundempire/core/types"
+)
+
+// openLogger builds the logger of a node from its log config. Messages are
+// appended to the log file, relative to the node home, or written to
+// standard output when no file is set. The returned closer closes the file.
+func openLogger(home string, config LogConfig) (types.Logger, io.Closer, error) {
+	level, err := types.ParseLogLevel(config.Level)
+	if err != nil {
+		return types.Logger{}, nil, err
+	}
+	if config.File == "" {
+		return types.NewLogger(os.Stdout, level), io.NopCloser(nil), nil
+	}
+
+	path := config.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(home, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return types.Logger{}, nil, fmt.Errorf("failed to create log directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return types.Logger{}, nil, fmt.Errorf("failed to open log file: %v", err)
+	}
+	return types.NewLogger(file, level), file, nil
+}
+
+// SetLogger sets the logger the node and its modules report to. Open sets
+// it from the log config; until then nothing is logged.
+func (app *UEApp) SetLogger(logger types.Logger) {
+	app.logger = logger.With("Node")
+	app.treasury.SetLogger(logger.With("Treasury"))
+	app.governance.SetLogger(logger.With("Governance"))
+	app.params.SetLogger(logger.With("Params"))
+	app.upgrade.SetLogger(logger.With("Upgrade"))
+	app.circuit.SetLogger(logger.With("Circuit"))
+	app.tokenFactory.SetLogger(logger.With("TokenFactory"))
+	if app.consensus != nil {
+		app.consensus.SetLogger(logger.With("Consensus"))
+	}
+}
+
+// Logger returns the logger of the node, for the services running next to
+// it to derive their own from
+func (app *UEApp) Logger() types.Logger {
+	return app.logger
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"undergroundempire/core/types"
+)
+
+// DefaultMempoolSize is the default maximum number of pending transactions
+const DefaultMempoolSize = 5000
+
+// Mempool holds validated transactions waiting to be included in a block,
+// in arrival order
+type Mempool struct {
+	mu         sync.Mutex
+	maxSize    int
+	maxTxBytes int
+	txs        []types.Transaction
+	hashes     map[string]bool
+}
+
+// NewMempool creates an empty mempool holding at most maxSize
+// transactions of at most maxTxBytes each
+func NewMempool(maxSize, maxTxBytes int) *Mempool {
+	return &Mempool{
+		maxSize:    maxSize,
+		maxTxBytes: maxTxBytes,
+		hashes:     make(map[string]bool),
+	}
+}
+
+// Add validates a transaction and queues it. It returns the transaction
+// with its hash set.
+func (m *Mempool) Add(tx types.Transaction) (types.Transaction, error) {
+	if err := tx.Validate(); err != nil {
+		return types.Transaction{}, err
+	}
+	tx.Hash = tx.CalculateHash()
+	if encoded, err := json.Marshal(tx); err != nil || len(encoded) > m.maxTxBytes {
+		return types.Transaction{}, fmt.Errorf("transaction exceeds the maximum size of %d bytes", m.maxTxBytes)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.hashes[tx.Hash] {
+		return types.Transaction{}, fmt.Errorf("transaction %s is already in the mempool", tx.Hash)
+	}
+	if len(m.txs) >= m.maxSize {
+		return types.Transaction{}, fmt.Errorf("mempool is full (%d transactions)", m.maxSize)
+	}
+
+	m.txs = append(m.txs, tx)
+	m.hashes[tx.Hash] = true
+	return tx, nil
+}
+
+// ReapTxs removes and returns up to max transactions, oldest first
+func (m *Mempool) ReapTxs(max int) []types.Transaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if max > len(m.txs) {
+		max = len(m.txs)
+	}
+	reaped := make([]types.Transaction, max)
+	copy(reaped, m.txs[:max])
+	m.txs = append([]types.Transaction{}, m.txs[max:]...)
+
+	for _, tx := range reaped {
+		delete(m.hashes, tx.Hash)
+	}
+	return reaped
+}
+
+// Pending returns the number of pending transactions sent by an account
+func (m *Mempool) Pending(address types.Address) int {
+	return len(m.PendingTxs(address))
+}
+
+// PendingTxs returns the pending transactions sent by an account, oldest
+// first
+func (m *Mempool) PendingTxs(address types.Address) []types.Transaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var txs []types.Transaction
+	for _, tx := range m.txs {
+		if tx.From == address {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+// Size returns the number of pending transactions
+func (m *Mempool) Size() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.txs)
+}
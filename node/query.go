@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/governance"
+	"undergroundempire/modules/treasury"
+	"undergroundempire/modules/validator"
+)
+
+// NodeStatus describes a running node and the tip of its chain
+type NodeStatus struct {
+	ChainID         string
+	Moniker         string
+	Version         string
+	Height          uint64
+	LatestBlockHash string
+	LatestBlockTime time.Time
+	MempoolSize     int
+}
+
+// Account is the on-chain state of an account
+type Account struct {
+	Address  types.Address
+	Balances types.Coins
+	Nonce    uint64 // Nonce of the account's next transaction
+}
+
+// Supply is the token supply of the chain
+type Supply struct {
+	Total         types.Coins
+	Burned        types.CoinAmount // Native tokens burned so far
+	CommunityPool types.CoinAmount
+}
+
+// queryContext returns a context for reading state at the last committed
+// height. Callers must hold app.mu.
+func (app *UEApp) queryContext() types.Context {
+	var height uint64
+	if app.store != nil {
+		height = app.store.Height()
+	}
+	return types.NewContext(context.Background(), height, time.Now(), app.chainID).WithChainParams(app.params.GetChainParams())
+}
+
+// Status returns the status of the node
+func (app *UEApp) Status() NodeStatus {
+	status := NodeStatus{
+		ChainID: app.chainID,
+		Moniker: app.config.Moniker,
+		Version: app.version,
+	}
+	if app.mempool != nil {
+		status.MempoolSize = app.mempool.Size()
+	}
+	if app.store != nil {
+		if status.Height = app.store.Height(); status.Height > 0 {
+			if block, err := app.store.GetBlock(status.Height); err == nil {
+				status.LatestBlockHash = block.Hash
+				status.LatestBlockTime = block.Timestamp
+			}
+		}
+	}
+	return status
+}
+
+// GetAccount returns the state of an account
+func (app *UEApp) GetAccount(address types.Address) Account {
+	app.mu.RLock()
+	balances := app.treasury.GetAllBalances(app.queryContext(), address)
+	app.mu.RUnlock()
+
+	return Account{Address: address, Balances: balances, Nonce: app.NextNonce(address)}
+}
+
+// GetBalance returns the balance of an account in a denom
+func (app *UEApp) GetBalance(address types.Address, denom string) types.CoinAmount {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+	return app.treasury.GetDenomBalance(app.queryContext(), address, denom)
+}
+
+// GetSupply returns the token supply of the chain
+func (app *UEApp) GetSupply() Supply {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+
+	ctx := app.queryContext()
+	return Supply{
+		Total:         app.treasury.GetTotalSupply(ctx),
+		Burned:        app.treasury.GetBurned(ctx, types.NativeDenom),
+		CommunityPool: app.treasury.GetCommunityPool(ctx),
+	}
+}
+
+// GetCommunityPoolSpends returns the community pool spends in the order
+// they were paid
+func (app *UEApp) GetCommunityPoolSpends() []treasury.CommunityPoolSpend {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+
+	return app.treasury.GetCommunityPoolSpends(app.queryContext())
+}
+
+// GetValidators returns every validator ordered by stake, or only those
+// with a status if one is given
+func (app *UEApp) GetValidators(status validator.ValidatorStatus) []validator.ValidatorNode {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+
+	validators := app.validators.GetValidators(app.queryContext())
+	if status == "" {
+		return validators
+	}
+
+	filtered := []validator.ValidatorNode{}
+	for _, val := range validators {
+		if val.Status == status {
+			filtered = append(filtered, val)
+		}
+	}
+	return filtered
+}
+
+// GetValidator returns a validator by ID
+func (app *UEApp) GetValidator(id string) (validator.ValidatorNode, error) {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+	return app.validators.GetValidator(app.queryContext(), id)
+}
+
+// GetSlashHistory returns the slash records of a validator
+func (app *UEApp) GetSlashHistory(id string) []validator.SlashRecord {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+	return app.validators.GetSlashHistory(app.queryContext(), id)
+}
+
+// GetProposals returns the proposals with a status ordered by ID, or all
+// proposals if the status is empty
+func (app *UEApp) GetProposals(status ProposalStatus) []GovernanceProposal {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+	return app.governanceSystem.GetProposals(app.queryContext(), status)
+}
+
+// GetProposal returns a proposal by ID
+func (app *UEApp) GetProposal(id uint64) (GovernanceProposal, error) {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+	return app.governanceSystem.GetProposal(app.queryContext(), id)
+}
+
+// GetTally returns the current stake-weighted tally of a proposal
+func (app *UEApp) GetTally(id uint64) (governance.TallyResult, error) {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+	return app.governanceSystem.Tally(app.queryContext(), id)
+}
+
+// GetBlock returns the committed block at a height, or the latest block if
+// height is 0
+func (app *UEApp) GetBlock(height uint64) (types.BlockData, error) {
+	if app.store == nil {
+		return types.BlockData{}, fmt.Errorf("node storage is not open")
+	}
+	if height == 0 {
+		if height = app.store.Height(); height == 0 {
+			return types.BlockData{}, fmt.Errorf("no block has been committed yet")
+		}
+	}
+	return app.store.GetBlock(height)
+}
+
+// GetBlockByHash returns the committed block with a hash
+func (app *UEApp) GetBlockByHash(hash string) (types.BlockData, error) {
+	if app.store == nil {
+		return types.BlockData{}, fmt.Errorf("node storage is not open")
+	}
+	return app.store.GetBlockByHash(hash)
+}
+
+// GetTx returns a committed transaction and the result of its execution
+func (app *UEApp) GetTx(hash string) (CommittedTx, error) {
+	if app.store == nil {
+		return CommittedTx{}, fmt.Errorf("node storage is not open")
+	}
+	tx, location, err := app.store.GetTx(hash)
+	if err != nil {
+		return CommittedTx{}, err
+	}
+
+	app.mu.RLock()
+	result, exists := app.txResults[hash]
+	app.mu.RUnlock()
+	if !exists {
+		result = TxResult{Height: location.Height, Index: location.Index}
+	}
+	return CommittedTx{Tx: tx, Result: result}, nil
+}
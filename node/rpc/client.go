@@ -0,0 +1,183 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/governance"
+	"undergroundempire/modules/treasury"
+	"undergroundempire/modules/validator"
+	app "undergroundempire/node"
+)
+
+// Client calls the JSON-RPC API of a node
+type Client struct {
+	url    string
+	http   *http.Client
+	nextID uint64
+}
+
+// NewClient creates a client for the node at address, either host:port or
+// a full http:// URL
+func NewClient(address string) *Client {
+	url := address
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = "http://" + url
+	}
+	// Outlasts the default wait of broadcast_tx in commit mode
+	return &Client{url: url, http: &http.Client{Timeout: time.Minute}}
+}
+
+// Call invokes a method and decodes its result
+func (c *Client) Call(method string, params, result interface{}) error {
+	req := Request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(fmt.Sprint(atomic.AddUint64(&c.nextID, 1))),
+		Method:  method,
+	}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = raw
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpResp, err := c.http.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to reach node at %s: %v", c.url, err)
+	}
+	defer httpResp.Body.Close()
+
+	var resp Response
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("invalid response from node (HTTP %d): %v", httpResp.StatusCode, err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// Status returns the status of the node
+func (c *Client) Status() (app.NodeStatus, error) {
+	var status app.NodeStatus
+	err := c.Call("status", nil, &status)
+	return status, err
+}
+
+// Block returns the committed block at a height, or the latest block if
+// height is 0
+func (c *Client) Block(height uint64) (types.BlockData, error) {
+	var block types.BlockData
+	err := c.Call("block", BlockParams{Height: height}, &block)
+	return block, err
+}
+
+// BlockByHash returns the committed block with a hash
+func (c *Client) BlockByHash(hash string) (types.BlockData, error) {
+	var block types.BlockData
+	err := c.Call("block", BlockParams{Hash: hash}, &block)
+	return block, err
+}
+
+// Tx returns a committed transaction and its result
+func (c *Client) Tx(hash string) (app.CommittedTx, error) {
+	var tx app.CommittedTx
+	err := c.Call("tx", TxParams{Hash: hash}, &tx)
+	return tx, err
+}
+
+// Account returns the state of an account
+func (c *Client) Account(address types.Address) (app.Account, error) {
+	var account app.Account
+	err := c.Call("account", AccountParams{Address: address}, &account)
+	return account, err
+}
+
+// Balance returns the balance of an account in a denom
+func (c *Client) Balance(address types.Address, denom string) (types.CoinAmount, error) {
+	var balance types.CoinAmount
+	err := c.Call("balance", BalanceParams{Address: address, Denom: denom}, &balance)
+	return balance, err
+}
+
+// Supply returns the token supply of the chain
+func (c *Client) Supply() (app.Supply, error) {
+	var supply app.Supply
+	err := c.Call("supply", nil, &supply)
+	return supply, err
+}
+
+// CommunityPoolSpends returns the community pool spends in the order they
+// were paid
+func (c *Client) CommunityPoolSpends() ([]treasury.CommunityPoolSpend, error) {
+	var spends []treasury.CommunityPoolSpend
+	err := c.Call("community_pool_spends", nil, &spends)
+	return spends, err
+}
+
+// Validators returns the validators with a status, or all of them if the
+// status is empty
+func (c *Client) Validators(status validator.ValidatorStatus) ([]validator.ValidatorNode, error) {
+	var validators []validator.ValidatorNode
+	err := c.Call("validators", ValidatorsParams{Status: status}, &validators)
+	return validators, err
+}
+
+// Validator returns a validator by ID
+func (c *Client) Validator(id string) (validator.ValidatorNode, error) {
+	var val validator.ValidatorNode
+	err := c.Call("validator", ValidatorParams{ID: id}, &val)
+	return val, err
+}
+
+// SlashHistory returns the slash records of a validator
+func (c *Client) SlashHistory(id string) ([]validator.SlashRecord, error) {
+	var records []validator.SlashRecord
+	err := c.Call("slash_history", ValidatorParams{ID: id}, &records)
+	return records, err
+}
+
+// Proposals returns the proposals with a status, or all of them if the
+// status is empty
+func (c *Client) Proposals(status types.ProposalStatus) ([]types.GovernanceProposal, error) {
+	var proposals []types.GovernanceProposal
+	err := c.Call("proposals", ProposalsParams{Status: status}, &proposals)
+	return proposals, err
+}
+
+// Proposal returns a proposal by ID
+func (c *Client) Proposal(id uint64) (types.GovernanceProposal, error) {
+	var proposal types.GovernanceProposal
+	err := c.Call("proposal", ProposalParams{ID: id}, &proposal)
+	return proposal, err
+}
+
+// Tally returns the current tally of a proposal
+func (c *Client) Tally(id uint64) (governance.TallyResult, error) {
+	var result governance.TallyResult
+	err := c.Call("tally", ProposalParams{ID: id}, &result)
+	return result, err
+}
+
+// BroadcastTx submits a signed transaction to the node in a broadcast
+// mode, BroadcastSync if mode is empty
+func (c *Client) BroadcastTx(tx types.Transaction, mode string) (BroadcastTxResult, error) {
+	var result BroadcastTxResult
+	err := c.Call("broadcast_tx", BroadcastTxParams{Tx: tx, Mode: mode}, &result)
+	return result, err
+}
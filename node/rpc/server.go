@@ -0,0 +1,463 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"undergroundempire/core/types"
+	"undergroundempire/modules/validator"
+	app "undergroundempire/node"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeServerError    = -32000 // The node rejected the request
+)
+
+// Modes of broadcast_tx
+const (
+	BroadcastSync   = "sync"   // Return once the node has checked the transaction and queued it
+	BroadcastAsync  = "async"  // Return once the transaction is queued; queued transactions are checked in arrival order
+	BroadcastCommit = "commit" // Return once a block has committed the transaction
+)
+
+// Request is a JSON-RPC 2.0 request. Params is an object of named
+// parameters.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC 2.0 error
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Parameters of the RPC methods
+type (
+	BlockParams struct {
+		Height uint64 // Ignored if Hash is set; 0 returns the latest block
+		Hash   string
+	}
+	TxParams struct {
+		Hash string
+	}
+	AccountParams struct {
+		Address types.Address
+	}
+	BalanceParams struct {
+		Address types.Address
+		Denom   string // Defaults to the native denom
+	}
+	ValidatorsParams struct {
+		Status validator.ValidatorStatus // Empty lists every validator
+	}
+	ValidatorParams struct {
+		ID string
+	}
+	ProposalsParams struct {
+		Status types.ProposalStatus // Empty lists every proposal
+	}
+	ProposalParams struct {
+		ID uint64
+	}
+	BroadcastTxParams struct {
+		Tx   types.Transaction
+		Mode string // Defaults to BroadcastSync
+	}
+)
+
+// BroadcastTxResult is the result of broadcast_tx. Height and Error are
+// only set in commit mode.
+type BroadcastTxResult struct {
+	Hash   string
+	Height uint64
+	Error  string // Why the committed transaction failed
+}
+
+// handler executes a method with its raw params
+type handler func(params json.RawMessage) (interface{}, error)
+
+// Server serves the JSON-RPC API of a node over HTTP. Every request is a
+// POST of a JSON-RPC 2.0 request to the root path, or a message on a
+// WebSocket connection to WebSocketPath, which can also subscribe to node
+// events. Browsers may call it from the origins allowed by the config.
+type Server struct {
+	node       *app.UEApp
+	config     app.RPCConfig
+	logger     types.Logger
+	methods    map[string]handler
+	httpServer *http.Server
+	listener   net.Listener
+	connMu     sync.Mutex // Guards conns
+	conns      map[*wsConnection]struct{}
+	asyncMu    sync.Mutex // Guards sending to asyncTxs once it may be closed
+	asyncTxs   chan types.Transaction
+	asyncDone  chan struct{}
+}
+
+// asyncQueueSize is the number of async broadcasts that may wait to be
+// checked. Further ones are refused until the queue drains.
+const asyncQueueSize = 1000
+
+// NewServer creates the RPC server of a node
+func NewServer(node *app.UEApp, config app.RPCConfig) *Server {
+	s := &Server{node: node, config: config, logger: node.Logger().With("RPC"), conns: make(map[*wsConnection]struct{})}
+	s.methods = map[string]handler{
+		"status":                s.status,
+		"block":                 s.block,
+		"tx":                    s.tx,
+		"account":               s.account,
+		"balance":               s.balance,
+		"supply":                s.supply,
+		"community_pool_spends": s.communityPoolSpends,
+		"validators":            s.validators,
+		"validator":             s.validator,
+		"slash_history":         s.slashHistory,
+		"proposals":             s.proposals,
+		"proposal":              s.proposal,
+		"tally":                 s.tally,
+		"broadcast_tx":          s.broadcastTx,
+	}
+	return s
+}
+
+// Start listens on the configured address and serves requests in the
+// background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.config.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", s.config.ListenAddress, err)
+	}
+	s.listener = listener
+	s.httpServer = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+
+	s.asyncTxs = make(chan types.Transaction, asyncQueueSize)
+	s.asyncDone = make(chan struct{})
+	go s.broadcastAsyncTxs(s.asyncTxs)
+
+	go s.httpServer.Serve(listener)
+	s.logger.Infof("Serving JSON-RPC on %s and WebSocket on %s", listener.Addr(), WebSocketPath)
+	return nil
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.config.ListenAddress
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop closes the listener and WebSocket connections, waits for requests
+// in progress, then hands the queued async broadcasts to the node
+func (s *Server) Stop() error {
+	if s.httpServer == nil {
+		return nil
+	}
+	s.connMu.Lock()
+	for c := range s.conns {
+		c.conn.Close()
+	}
+	s.connMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.httpServer.Shutdown(ctx)
+
+	s.asyncMu.Lock()
+	close(s.asyncTxs)
+	s.asyncTxs = nil
+	s.asyncMu.Unlock()
+	<-s.asyncDone
+	return err
+}
+
+// queueAsyncTx queues a transaction for broadcastAsyncTxs without waiting
+func (s *Server) queueAsyncTx(tx types.Transaction) error {
+	s.asyncMu.Lock()
+	defer s.asyncMu.Unlock()
+
+	if s.asyncTxs == nil {
+		return fmt.Errorf("server is stopping")
+	}
+	select {
+	case s.asyncTxs <- tx:
+		return nil
+	default:
+		return fmt.Errorf("async broadcast queue is full (%d transactions)", asyncQueueSize)
+	}
+}
+
+// broadcastAsyncTxs hands queued async transactions to the node one at a
+// time, in the order they were received, so consecutive nonces of one
+// sender reach the mempool in order
+func (s *Server) broadcastAsyncTxs(queue <-chan types.Transaction) {
+	defer close(s.asyncDone)
+	for tx := range queue {
+		if _, err := s.node.BroadcastTx(tx); err != nil {
+			s.logger.Infof("Rejected transaction %s: %v", tx.CalculateHash(), err)
+		}
+	}
+}
+
+// ServeHTTP handles one JSON-RPC request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == WebSocketPath {
+		s.serveWebSocket(w, r)
+		return
+	}
+	if s.allowCORS(w, r) && r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "JSON-RPC requests must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, int64(s.config.MaxBodyBytes))
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			message := fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)
+			writeResponse(w, http.StatusRequestEntityTooLarge, Response{Error: &Error{Code: CodeInvalidRequest, Message: message}})
+			return
+		}
+		writeResponse(w, http.StatusOK, Response{Error: &Error{Code: CodeParseError, Message: err.Error()}})
+		return
+	}
+	writeResponse(w, http.StatusOK, s.call(req))
+}
+
+// allowCORS sets the CORS headers of a request from an allowed origin. It
+// reports whether the origin is allowed.
+func (s *Server) allowCORS(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	for _, allowed := range s.config.CORSAllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Add("Vary", "Origin")
+			return true
+		}
+	}
+	return false
+}
+
+// call dispatches a request to its method
+func (s *Server) call(req Request) Response {
+	resp := Response{ID: req.ID}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		resp.Error = &Error{Code: CodeInvalidRequest, Message: "invalid JSON-RPC 2.0 request"}
+		return resp
+	}
+
+	method, exists := s.methods[req.Method]
+	if !exists {
+		resp.Error = &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %s not found", req.Method)}
+		return resp
+	}
+
+	result, err := method(req.Params)
+	if err != nil {
+		resp.Error = toError(err)
+		return resp
+	}
+
+	if resp.Result, err = json.Marshal(result); err != nil {
+		resp.Error = &Error{Code: CodeServerError, Message: err.Error()}
+	}
+	return resp
+}
+
+// toError returns the JSON-RPC error of a failed method. Errors other than
+// *Error are the node rejecting the request.
+func toError(err error) *Error {
+	if rpcErr, ok := err.(*Error); ok {
+		return rpcErr
+	}
+	return &Error{Code: CodeServerError, Message: err.Error()}
+}
+
+// writeResponse writes a JSON-RPC response with an HTTP status
+func writeResponse(w http.ResponseWriter, status int, resp Response) {
+	resp.JSONRPC = "2.0"
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// decodeParams unmarshals the params of a request
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) status(params json.RawMessage) (interface{}, error) {
+	return s.node.Status(), nil
+}
+
+func (s *Server) block(params json.RawMessage) (interface{}, error) {
+	var p BlockParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Hash != "" {
+		return s.node.GetBlockByHash(p.Hash)
+	}
+	return s.node.GetBlock(p.Height)
+}
+
+func (s *Server) tx(params json.RawMessage) (interface{}, error) {
+	var p TxParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.node.GetTx(p.Hash)
+}
+
+func (s *Server) account(params json.RawMessage) (interface{}, error) {
+	var p AccountParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.node.GetAccount(p.Address), nil
+}
+
+func (s *Server) balance(params json.RawMessage) (interface{}, error) {
+	var p BalanceParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Denom == "" {
+		p.Denom = types.NativeDenom
+	}
+	return s.node.GetBalance(p.Address, p.Denom), nil
+}
+
+func (s *Server) supply(params json.RawMessage) (interface{}, error) {
+	return s.node.GetSupply(), nil
+}
+
+func (s *Server) communityPoolSpends(params json.RawMessage) (interface{}, error) {
+	return s.node.GetCommunityPoolSpends(), nil
+}
+
+func (s *Server) validators(params json.RawMessage) (interface{}, error) {
+	var p ValidatorsParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.node.GetValidators(p.Status), nil
+}
+
+func (s *Server) validator(params json.RawMessage) (interface{}, error) {
+	var p ValidatorParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.node.GetValidator(p.ID)
+}
+
+func (s *Server) slashHistory(params json.RawMessage) (interface{}, error) {
+	var p ValidatorParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.node.GetSlashHistory(p.ID), nil
+}
+
+func (s *Server) proposals(params json.RawMessage) (interface{}, error) {
+	var p ProposalsParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.node.GetProposals(p.Status), nil
+}
+
+func (s *Server) proposal(params json.RawMessage) (interface{}, error) {
+	var p ProposalParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.node.GetProposal(p.ID)
+}
+
+func (s *Server) tally(params json.RawMessage) (interface{}, error) {
+	var p ProposalParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.node.GetTally(p.ID)
+}
+
+func (s *Server) broadcastTx(params json.RawMessage) (interface{}, error) {
+	var p BroadcastTxParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	switch p.Mode {
+	case "", BroadcastSync:
+		tx, err := s.node.BroadcastTx(p.Tx)
+		if err != nil {
+			return nil, err
+		}
+		return BroadcastTxResult{Hash: tx.Hash}, nil
+
+	case BroadcastAsync:
+		if err := p.Tx.Validate(); err != nil {
+			return nil, err
+		}
+		if err := s.queueAsyncTx(p.Tx); err != nil {
+			return nil, err
+		}
+		return BroadcastTxResult{Hash: p.Tx.CalculateHash()}, nil
+
+	case BroadcastCommit:
+		tx, result, err := s.node.BroadcastTxCommit(p.Tx, s.config.TimeoutBroadcastTxCommit)
+		if err != nil {
+			return nil, err
+		}
+		return BroadcastTxResult{Hash: tx.Hash, Height: result.Height, Error: result.Error}, nil
+
+	default:
+		return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("invalid broadcast mode %q, expected %s, %s or %s", p.Mode, BroadcastSync, BroadcastAsync, BroadcastCommit)}
+	}
+}
@@ -0,0 +1,290 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"undergroundempire/core/crypto"
+	"undergroundempire/core/types"
+	app "undergroundempire/node"
+)
+
+// testServer serves the RPC API of an in-process single validator node.
+// Blocks are only produced when the test calls ProduceBlock.
+func testServer(t *testing.T) (*app.UEApp, *Server, crypto.PrivKey) {
+	opts := app.DefaultTestnetOptions()
+	opts.Validators = 1
+	opts.OutputDir = t.TempDir()
+	nodes, err := app.InitTestnet(opts)
+	if err != nil {
+		t.Fatalf("init testnet: %v", err)
+	}
+	home := nodes[0].Home
+
+	config, err := app.LoadConfig(home)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	config.RPC.ListenAddress = "127.0.0.1:0"
+	config.RPC.MaxBodyBytes = 4096
+	config.RPC.CORSAllowedOrigins = []string{"https://explorer.example"}
+	config.RPC.TimeoutBroadcastTxCommit = 5 * time.Second
+
+	node, err := app.OpenNode("test", home, config)
+	if err != nil {
+		t.Fatalf("open node: %v", err)
+	}
+	key, err := crypto.LoadKeyFile(filepath.Join(home, "config", app.ValidatorKeyFile))
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+
+	server := NewServer(node, config.RPC)
+	if err := server.Start(); err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(func() { server.Stop() })
+	return node, server, key.PrivKey
+}
+
+// produceWhenPending commits a block once a transaction reaches the mempool
+func produceWhenPending(t *testing.T, node *app.UEApp) {
+	for i := 0; i < 100 && node.Status().MempoolSize == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := node.ProduceBlock(); err != nil {
+		t.Errorf("produce block: %v", err)
+	}
+}
+
+func TestServerQueriesAndBroadcast(t *testing.T) {
+	node, server, key := testServer(t)
+	client := NewClient(server.Addr())
+	recipient := types.Address{0xaa}
+	chainID := node.GetChainID()
+
+	status, err := client.Status()
+	if err != nil || status.ChainID != chainID {
+		t.Fatalf("status: %+v, %v", status, err)
+	}
+	if _, err := client.Block(0); err == nil {
+		t.Fatal("expected no latest block before the first commit")
+	}
+
+	transfer := func(nonce uint64) types.Transaction {
+		tx := types.NewTransaction(types.Address{}, recipient, types.NewUECoins(types.NewUEAmount(5)), 21000, types.DefaultGasPrice, nil, nonce)
+		return app.SignTx(key, chainID, tx)
+	}
+
+	// Sync mode returns once the transaction is in the mempool
+	synced, err := client.BroadcastTx(transfer(0), BroadcastSync)
+	if err != nil {
+		t.Fatalf("broadcast sync: %v", err)
+	}
+	if _, err := client.Tx(synced.Hash); err == nil {
+		t.Fatal("expected a pending transaction not to be found")
+	}
+	block, err := node.ProduceBlock()
+	if err != nil {
+		t.Fatalf("produce block: %v", err)
+	}
+
+	committed, err := client.Tx(synced.Hash)
+	if err != nil || committed.Tx.Hash != synced.Hash || committed.Result.Height != 1 || committed.Result.Error != "" {
+		t.Fatalf("tx: %+v, %v", committed, err)
+	}
+	if got, err := client.Block(1); err != nil || got.Hash != block.Hash {
+		t.Fatalf("block by height: %+v, %v", got, err)
+	}
+	if got, err := client.BlockByHash(block.Hash); err != nil || got.Height != 1 {
+		t.Fatalf("block by hash: %+v, %v", got, err)
+	}
+	if got, err := client.Block(0); err != nil || got.Hash != block.Hash {
+		t.Fatalf("latest block: %+v, %v", got, err)
+	}
+	if balance, err := client.Balance(recipient, ""); err != nil || !balance.Amount.Equal(types.NewUEAmount(5)) {
+		t.Fatalf("balance: %+v, %v", balance, err)
+	}
+
+	// Commit mode waits for the block including the transaction
+	go produceWhenPending(t, node)
+	result, err := client.BroadcastTx(transfer(1), BroadcastCommit)
+	if err != nil || result.Height != 2 || result.Error != "" {
+		t.Fatalf("broadcast commit: %+v, %v", result, err)
+	}
+
+	// Async mode returns before the transaction is checked
+	async, err := client.BroadcastTx(transfer(2), BroadcastAsync)
+	if err != nil || async.Hash == "" {
+		t.Fatalf("broadcast async: %+v, %v", async, err)
+	}
+	produceWhenPending(t, node)
+	if committed, err := client.Tx(async.Hash); err != nil || committed.Result.Height != 3 {
+		t.Fatalf("async tx: %+v, %v", committed, err)
+	}
+
+	if _, err := client.BroadcastTx(transfer(3), "later"); err == nil || err.(*Error).Code != CodeInvalidParams {
+		t.Fatalf("expected an invalid broadcast mode to be rejected, got %v", err)
+	}
+	if _, err := client.BroadcastTx(transfer(0), BroadcastCommit); err == nil {
+		t.Fatal("expected a replayed transaction to be rejected in commit mode")
+	}
+}
+
+func TestAsyncBroadcastKeepsOrder(t *testing.T) {
+	node, server, key := testServer(t)
+	client := NewClient(server.Addr())
+
+	var hashes []string
+	for nonce := uint64(0); nonce < 20; nonce++ {
+		tx := types.NewTransaction(types.Address{}, types.Address{0xaa}, types.NewUECoins(types.NewUEAmount(1)), 21000, types.DefaultGasPrice, nil, nonce)
+		result, err := client.BroadcastTx(app.SignTx(key, node.GetChainID(), tx), BroadcastAsync)
+		if err != nil {
+			t.Fatalf("broadcast nonce %d: %v", nonce, err)
+		}
+		hashes = append(hashes, result.Hash)
+	}
+
+	for i := 0; i < 100 && node.Status().MempoolSize < len(hashes); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	block, err := node.ProduceBlock()
+	if err != nil {
+		t.Fatalf("produce block: %v", err)
+	}
+	if len(block.Transactions) != len(hashes) {
+		t.Fatalf("block holds %d transactions, want %d", len(block.Transactions), len(hashes))
+	}
+	for i, hash := range hashes {
+		if committed, err := client.Tx(hash); err != nil || committed.Result.Index != i || committed.Result.Error != "" {
+			t.Fatalf("tx %d: %+v, %v", i, committed, err)
+		}
+	}
+}
+
+func TestServerLimitsRequestsAndAllowsCORS(t *testing.T) {
+	_, server, _ := testServer(t)
+	url := "http://" + server.Addr()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"status","params":{"Padding":"` + strings.Repeat("x", 5000) + `"}}`
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized request: HTTP %d, want %d", resp.StatusCode, http.StatusRequestEntityTooLarge)
+	}
+
+	preflight := func(origin string) *http.Response {
+		req, _ := http.NewRequest(http.MethodOptions, url, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("preflight: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := preflight("https://explorer.example"); resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Origin") != "https://explorer.example" {
+		t.Fatalf("allowed origin: HTTP %d, headers %v", resp.StatusCode, resp.Header)
+	}
+	if resp := preflight("https://elsewhere.example"); resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected no CORS headers for another origin, got %v", resp.Header)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(`{"jsonrpc":"2.0","id":1,"method":"status"}`)))
+	req.Header.Set("Origin", "https://explorer.example")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "https://explorer.example" {
+		t.Fatalf("cross-origin request: HTTP %d, headers %v", resp.StatusCode, resp.Header)
+	}
+}
+
+func TestWebSocketSubscriptions(t *testing.T) {
+	node, server, key := testServer(t)
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+server.Addr()+WebSocketPath, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	send := func(id int, method string, params interface{}) Response {
+		raw, _ := json.Marshal(params)
+		if err := conn.WriteJSON(Request{JSONRPC: "2.0", ID: json.RawMessage(strconv.Itoa(id)), Method: method, Params: raw}); err != nil {
+			t.Fatalf("write %s: %v", method, err)
+		}
+		var resp Response
+		if err := conn.ReadJSON(&resp); err != nil {
+			t.Fatalf("read %s: %v", method, err)
+		}
+		return resp
+	}
+
+	// Plain methods are served over the connection too
+	if resp := send(1, "status", nil); resp.Error != nil || string(resp.ID) != "1" {
+		t.Fatalf("status over websocket: %+v", resp)
+	}
+	if resp := send(2, "subscribe", SubscribeParams{Query: "event='Tx' AND"}); resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Fatalf("expected an invalid query to be rejected, got %+v", resp)
+	}
+
+	sender := crypto.PubKey(key.PubKey()).Address()
+	query := fmt.Sprintf("event='Tx' AND tx.from='%s'", sender)
+	if resp := send(3, "subscribe", SubscribeParams{Query: query}); resp.Error != nil {
+		t.Fatalf("subscribe: %+v", resp.Error)
+	}
+	if resp := send(4, "subscribe", SubscribeParams{Query: query}); resp.Error == nil {
+		t.Fatal("expected a duplicate subscription to be rejected")
+	}
+
+	tx := types.NewTransaction(sender, types.Address{0xaa}, types.NewUECoins(types.NewUEAmount(1)), 21000, types.DefaultGasPrice, nil, 0)
+	tx, err = node.BroadcastTx(app.SignTx(key, node.GetChainID(), tx))
+	if err != nil {
+		t.Fatalf("broadcast: %v", err)
+	}
+	if _, err := node.ProduceBlock(); err != nil {
+		t.Fatalf("produce block: %v", err)
+	}
+
+	var pushed Response
+	if err := conn.ReadJSON(&pushed); err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	var result struct {
+		Query string
+		Event struct {
+			Type       string
+			Height     uint64
+			Attributes map[string]string
+		}
+	}
+	if err := json.Unmarshal(pushed.Result, &result); err != nil || string(pushed.ID) != "3" {
+		t.Fatalf("pushed event %+v: %v", pushed, err)
+	}
+	if result.Query != query || result.Event.Type != app.EventTx || result.Event.Height != 1 || result.Event.Attributes["tx.hash"] != tx.Hash {
+		t.Fatalf("unexpected event %+v", result)
+	}
+
+	if resp := send(5, "unsubscribe", SubscribeParams{Query: query}); resp.Error != nil {
+		t.Fatalf("unsubscribe: %+v", resp.Error)
+	}
+	if n := node.EventBus().NumSubscriptions(); n != 0 {
+		t.Fatalf("%d subscriptions left after unsubscribe", n)
+	}
+}
@@ -0,0 +1,283 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	app "undergroundempire/node"
+)
+
+// WebSocketPath is the path of the WebSocket endpoint. Clients send
+// JSON-RPC requests over the connection, including subscribe, unsubscribe
+// and unsubscribe_all.
+const WebSocketPath = "/websocket"
+
+const (
+	wsWriteWait  = 10 * time.Second // A client must accept a message within this time
+	wsPongWait   = 60 * time.Second // A client must answer pings within this time
+	wsPingPeriod = wsPongWait / 2
+)
+
+// SubscribeParams are the params of subscribe and unsubscribe
+type SubscribeParams struct {
+	Query string // Such as "event='Tx' AND tx.from='0x…'"
+}
+
+// SubscriptionEvent is pushed to a WebSocket client for each event matching
+// one of its subscriptions, as the result of a response with the ID of the
+// subscribe request
+type SubscriptionEvent struct {
+	Query string
+	Event app.Event
+}
+
+// wsConnection serves the requests and subscriptions of one WebSocket
+// client. Each subscription forwards its events from its own goroutine;
+// while the client reads slowly, writes block and the subscription's buffer
+// fills until the event bus cancels it.
+type wsConnection struct {
+	server        *Server
+	conn          *websocket.Conn
+	writeMu       sync.Mutex // Serializes writes to conn
+	mu            sync.Mutex // Guards subscriptions
+	subscriptions map[string]*app.Subscription
+	closed        chan struct{}
+}
+
+// serveWebSocket upgrades a request to a WebSocket connection and serves
+// it until the client disconnects or the server stops
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // The upgrader has replied with an error
+	}
+
+	c := &wsConnection{
+		server:        s,
+		conn:          conn,
+		subscriptions: make(map[string]*app.Subscription),
+		closed:        make(chan struct{}),
+	}
+	s.connMu.Lock()
+	s.conns[c] = struct{}{}
+	s.connMu.Unlock()
+
+	c.run()
+
+	s.connMu.Lock()
+	delete(s.conns, c)
+	s.connMu.Unlock()
+}
+
+// checkOrigin accepts WebSocket connections from non-browser clients, from
+// the node's own origin and from the allowed CORS origins
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+		return true
+	}
+	for _, allowed := range s.config.CORSAllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// run reads requests until the connection fails, then ends the
+// connection's subscriptions
+func (c *wsConnection) run() {
+	defer func() {
+		close(c.closed)
+		c.unsubscribeAll()
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(int64(c.server.config.MaxBodyBytes))
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	go c.ping()
+
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req Request
+		if err := json.Unmarshal(message, &req); err != nil {
+			c.write(Response{Error: &Error{Code: CodeParseError, Message: err.Error()}})
+			continue
+		}
+		switch req.Method {
+		case "subscribe":
+			c.subscribe(req)
+		case "unsubscribe":
+			c.unsubscribe(req)
+		case "unsubscribe_all":
+			c.unsubscribeAll()
+			c.reply(req, struct{}{}, nil)
+		default:
+			c.write(c.server.call(req))
+		}
+	}
+}
+
+// ping keeps the connection alive until it closes
+func (c *wsConnection) ping() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write sends a response. A client that does not accept it in time is
+// disconnected.
+func (c *wsConnection) write(resp Response) error {
+	resp.JSONRPC = "2.0"
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	if err := c.conn.WriteJSON(resp); err != nil {
+		c.conn.Close()
+		return err
+	}
+	return nil
+}
+
+// reply answers a request with a result or an error
+func (c *wsConnection) reply(req Request, result interface{}, err error) {
+	resp := Response{ID: req.ID}
+	if err != nil {
+		resp.Error = toError(err)
+	} else {
+		resp.Result, _ = json.Marshal(result)
+	}
+	c.write(resp)
+}
+
+// subscribe starts forwarding the events matching a query
+func (c *wsConnection) subscribe(req Request) {
+	query, err := decodeQuery(req.Params)
+	if err != nil {
+		c.reply(req, nil, err)
+		return
+	}
+
+	c.mu.Lock()
+	if _, exists := c.subscriptions[query.String()]; exists {
+		c.mu.Unlock()
+		c.reply(req, nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("already subscribed to %s", query)})
+		return
+	}
+	if len(c.subscriptions) >= c.server.config.MaxSubscriptionsPerClient {
+		c.mu.Unlock()
+		c.reply(req, nil, fmt.Errorf("a connection may hold at most %d subscriptions", c.server.config.MaxSubscriptionsPerClient))
+		return
+	}
+	sub, err := c.server.node.EventBus().Subscribe(query, c.server.config.SubscriptionBufferSize)
+	if err != nil {
+		c.mu.Unlock()
+		c.reply(req, nil, err)
+		return
+	}
+	c.subscriptions[query.String()] = sub
+	c.mu.Unlock()
+
+	c.reply(req, struct{}{}, nil)
+	go c.forward(req.ID, sub)
+}
+
+// forward writes the events of a subscription until it ends. A client
+// whose subscription was cancelled is told why.
+func (c *wsConnection) forward(id json.RawMessage, sub *app.Subscription) {
+	query := sub.Query().String()
+	for event := range sub.Events() {
+		result, err := json.Marshal(SubscriptionEvent{Query: query, Event: event})
+		if err != nil {
+			continue
+		}
+		if err := c.write(Response{ID: id, Result: result}); err != nil {
+			return
+		}
+	}
+
+	if err := sub.Err(); err != nil {
+		c.mu.Lock()
+		if c.subscriptions[query] == sub {
+			delete(c.subscriptions, query)
+		}
+		c.mu.Unlock()
+
+		message := fmt.Sprintf("subscription to %s was cancelled: %v", query, err)
+		c.write(Response{ID: id, Error: &Error{Code: CodeServerError, Message: message}})
+	}
+}
+
+// unsubscribe ends the subscription to a query
+func (c *wsConnection) unsubscribe(req Request) {
+	query, err := decodeQuery(req.Params)
+	if err != nil {
+		c.reply(req, nil, err)
+		return
+	}
+
+	c.mu.Lock()
+	sub, exists := c.subscriptions[query.String()]
+	delete(c.subscriptions, query.String())
+	c.mu.Unlock()
+
+	if !exists {
+		c.reply(req, nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("not subscribed to %s", query)})
+		return
+	}
+	c.server.node.EventBus().Unsubscribe(sub)
+	c.reply(req, struct{}{}, nil)
+}
+
+// unsubscribeAll ends every subscription of the connection
+func (c *wsConnection) unsubscribeAll() {
+	c.mu.Lock()
+	subscriptions := c.subscriptions
+	c.subscriptions = make(map[string]*app.Subscription)
+	c.mu.Unlock()
+
+	for _, sub := range subscriptions {
+		c.server.node.EventBus().Unsubscribe(sub)
+	}
+}
+
+// decodeQuery parses the query of subscribe and unsubscribe params
+func decodeQuery(params json.RawMessage) (app.EventQuery, error) {
+	var p SubscribeParams
+	if err := decodeParams(params, &p); err != nil {
+		return app.EventQuery{}, err
+	}
+	query, err := app.ParseEventQuery(p.Query)
+	if err != nil {
+		return app.EventQuery{}, &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return query, nil
+}
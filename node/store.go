@@ -0,0 +1,237 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"undergroundempire/core/types"
+)
+
+// BlockStoreFile is the file name of the block store inside the node's data directory
+const BlockStoreFile = "blocks.jsonl"
+
+// TxLocation locates a committed transaction
+type TxLocation struct {
+	Height uint64
+	Index  int
+}
+
+// BlockStore appends committed blocks to a JSON lines file. Blocks are
+// indexed by height, block hash and transaction hash; the most recent
+// blocks are kept in memory and older ones are read back from the file.
+type BlockStore struct {
+	mu         sync.RWMutex
+	file       *os.File
+	size       int64
+	offsets    []int64 // File offset of each block, by height - 1
+	keepRecent int
+	recent     []types.BlockData
+	lastHash   string
+	byHash     map[string]uint64
+	txByHash   map[string]TxLocation
+}
+
+// OpenBlockStore opens the store at path, loading any existing blocks.
+// keepRecent limits the blocks kept in memory; zero keeps all of them. A
+// trailing line without a newline is a block whose write was interrupted,
+// and is truncated.
+func OpenBlockStore(path string, keepRecent int) (*BlockStore, error) {
+	store := &BlockStore{
+		keepRecent: keepRecent,
+		byHash:     make(map[string]uint64),
+		txByHash:   make(map[string]TxLocation),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create block store directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open block store: %v", err)
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			break
+		}
+		if err == io.EOF {
+			if err := file.Truncate(store.size); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("failed to truncate incomplete block: %v", err)
+			}
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to read block store: %v", err)
+		}
+
+		var block types.BlockData
+		if err := json.Unmarshal(line, &block); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("corrupt block store %s: %v", path, err)
+		}
+		if err := store.verify(block); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("corrupt block store %s: %v", path, err)
+		}
+		store.index(block, int64(len(line)))
+	}
+
+	store.file = file
+	return store, nil
+}
+
+// verify checks that a block extends the last stored block
+func (s *BlockStore) verify(block types.BlockData) error {
+	if block.Height != uint64(len(s.offsets))+1 {
+		return fmt.Errorf("block %d does not follow height %d", block.Height, len(s.offsets))
+	}
+	if block.ParentHash != s.lastHash {
+		return fmt.Errorf("block %d does not extend block %d", block.Height, len(s.offsets))
+	}
+	return nil
+}
+
+// index adds a verified block, stored in size bytes at the end of the file
+func (s *BlockStore) index(block types.BlockData, size int64) {
+	s.offsets = append(s.offsets, s.size)
+	s.size += size
+	s.lastHash = block.Hash
+
+	s.recent = append(s.recent, block)
+	if s.keepRecent > 0 && len(s.recent) > s.keepRecent {
+		s.recent = append([]types.BlockData{}, s.recent[len(s.recent)-s.keepRecent:]...)
+	}
+
+	s.byHash[block.Hash] = block.Height
+	for i, tx := range block.Transactions {
+		s.txByHash[tx.Hash] = TxLocation{Height: block.Height, Index: i}
+	}
+}
+
+// SaveBlock appends a committed block to the store and syncs it to disk
+func (s *BlockStore) SaveBlock(block types.BlockData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("block store is closed")
+	}
+	if err := s.verify(block); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := s.file.WriteAt(line, s.size); err != nil {
+		s.file.Truncate(s.size)
+		return fmt.Errorf("failed to write block %d: %v", block.Height, err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync block %d: %v", block.Height, err)
+	}
+
+	s.index(block, int64(len(line)))
+	return nil
+}
+
+// Height returns the height of the last stored block, 0 if there is none
+func (s *BlockStore) Height() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return uint64(len(s.offsets))
+}
+
+// GetBlock returns the block at a height
+func (s *BlockStore) GetBlock(height uint64) (types.BlockData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if height == 0 || height > uint64(len(s.offsets)) {
+		return types.BlockData{}, fmt.Errorf("block at height %d not found", height)
+	}
+
+	first := uint64(len(s.offsets)-len(s.recent)) + 1
+	if height >= first {
+		return s.recent[height-first], nil
+	}
+	return s.readBlock(height)
+}
+
+// readBlock reads a block that is no longer kept in memory from the file
+func (s *BlockStore) readBlock(height uint64) (types.BlockData, error) {
+	if s.file == nil {
+		return types.BlockData{}, fmt.Errorf("block store is closed")
+	}
+
+	end := s.size
+	if height < uint64(len(s.offsets)) {
+		end = s.offsets[height]
+	}
+	line := make([]byte, end-s.offsets[height-1])
+	if _, err := s.file.ReadAt(line, s.offsets[height-1]); err != nil {
+		return types.BlockData{}, fmt.Errorf("failed to read block %d: %v", height, err)
+	}
+
+	var block types.BlockData
+	if err := json.Unmarshal(line, &block); err != nil {
+		return types.BlockData{}, fmt.Errorf("corrupt block %d: %v", height, err)
+	}
+	return block, nil
+}
+
+// GetBlockByHash returns the block with a hash
+func (s *BlockStore) GetBlockByHash(hash string) (types.BlockData, error) {
+	s.mu.RLock()
+	height, exists := s.byHash[hash]
+	s.mu.RUnlock()
+
+	if !exists {
+		return types.BlockData{}, fmt.Errorf("block %s not found", hash)
+	}
+	return s.GetBlock(height)
+}
+
+// GetTx returns a committed transaction and its location
+func (s *BlockStore) GetTx(hash string) (types.Transaction, TxLocation, error) {
+	s.mu.RLock()
+	location, exists := s.txByHash[hash]
+	s.mu.RUnlock()
+
+	if !exists {
+		return types.Transaction{}, TxLocation{}, fmt.Errorf("transaction %s not found", hash)
+	}
+	block, err := s.GetBlock(location.Height)
+	if err != nil {
+		return types.Transaction{}, TxLocation{}, err
+	}
+	return block.Transactions[location.Index], location, nil
+}
+
+// Close flushes the store to disk and closes it
+func (s *BlockStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	defer func() { s.file = nil }()
+
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return fmt.Errorf("failed to flush block store: %v", err)
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"undergroundempire/core/crypto"
+	"undergroundempire/core/types"
+)
+
+// TestnetOptions configures the node homes of a local testnet
+type TestnetOptions struct {
+	Validators   int
+	OutputDir    string
+	ChainID      string
+	Host         string
+	StartingPort int // Node i is assigned the P2P port StartingPort+2i and the RPC port StartingPort+2i+1
+}
+
+// TestnetNode describes one generated node of a testnet
+type TestnetNode struct {
+	Moniker string
+	Home    string
+	Address types.Address
+	Config  Config
+}
+
+// DefaultTestnetOptions returns the options of a four-validator testnet on localhost
+func DefaultTestnetOptions() TestnetOptions {
+	return TestnetOptions{
+		Validators:   4,
+		OutputDir:    "./testnet",
+		ChainID:      "ue-testnet",
+		Host:         "127.0.0.1",
+		StartingPort: 26656,
+	}
+}
+
+// InitTestnet generates a home directory per validator under OutputDir,
+// each holding a validator key, a config with its own ports and the shared
+// genesis. Every validator is funded and bonds the minimum stake through a
+// signed gentx, so the genesis starts with equal voting power. Peer
+// networking is not implemented yet, so no persistent peers are written:
+// each node started from these homes runs its own copy of the chain.
+func InitTestnet(opts TestnetOptions) ([]TestnetNode, error) {
+	if opts.Validators < 1 {
+		return nil, fmt.Errorf("testnet needs at least one validator, got %d", opts.Validators)
+	}
+	if opts.StartingPort < 1 || opts.StartingPort+2*opts.Validators > 65536 {
+		return nil, fmt.Errorf("port range starting at %d does not fit %d validators", opts.StartingPort, opts.Validators)
+	}
+	if entries, err := os.ReadDir(opts.OutputDir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("output directory %s is not empty", opts.OutputDir)
+	}
+
+	genesis := DefaultGenesis(opts.ChainID)
+	stake := genesis.Params.Chain.MinValidatorStake
+	balance := types.Coins{types.NewUECoins(stake.Add(types.NewUEAmount(100000)))}
+
+	nodes := make([]TestnetNode, opts.Validators)
+	for i := range nodes {
+		moniker := fmt.Sprintf("node%d", i)
+		home := filepath.Join(opts.OutputDir, moniker)
+
+		key, err := crypto.LoadOrGenerateKeyFile(filepath.Join(home, "config", ValidatorKeyFile))
+		if err != nil {
+			return nil, err
+		}
+
+		tx := NewGenTx(key.PrivKey, MsgCreateValidator{
+			ChainID:    genesis.ChainID,
+			ID:         moniker,
+			Stake:      stake,
+			Commission: types.NewDecWithPrec(1, 1),
+		})
+		if err := tx.Save(filepath.Join(home, "config", GenTxDir, fmt.Sprintf("gentx-%s.json", moniker))); err != nil {
+			return nil, err
+		}
+		if err := genesis.AddAccount(key.Address, balance); err != nil {
+			return nil, err
+		}
+		if err := genesis.AddGenTx(tx); err != nil {
+			return nil, err
+		}
+
+		config := DefaultConfig()
+		config.Moniker = moniker
+		config.P2P.ListenAddress = testnetAddress(opts.Host, opts.StartingPort+2*i)
+		config.RPC.ListenAddress = testnetAddress(opts.Host, opts.StartingPort+2*i+1)
+		nodes[i] = TestnetNode{Moniker: moniker, Home: home, Address: key.Address, Config: config}
+	}
+
+	for i := range nodes {
+		if err := nodes[i].Config.Save(filepath.Join(nodes[i].Home, "config", ConfigFile)); err != nil {
+			return nil, err
+		}
+		if err := genesis.Save(filepath.Join(nodes[i].Home, "config", GenesisFile)); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Join(nodes[i].Home, "data"), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create data directory: %v", err)
+		}
+	}
+
+	return nodes, nil
+}
+
+func testnetAddress(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"undergroundempire/core/crypto"
+	"undergroundempire/core/types"
+)
+
+// TxResult is the outcome of a committed transaction
+type TxResult struct {
+	Height uint64
+	Index  int
+	Error  string // Why the transaction failed, empty if it succeeded
+}
+
+// CommittedTx is a committed transaction with its result
+type CommittedTx struct {
+	Tx     types.Transaction
+	Result TxResult
+}
+
+// SignTx signs a transaction for a chain with a private key, setting its
+// sender and public key
+func SignTx(priv crypto.PrivKey, chainID string, tx types.Transaction) types.Transaction {
+	tx.PubKey = priv.PubKey()
+	tx.From = priv.PubKey().Address()
+	tx.Signature = priv.Sign(tx.SignBytes(chainID))
+	tx.Hash = tx.CalculateHash()
+	return tx
+}
+
+// VerifyTx checks that a transaction is signed for a chain by the owner of
+// its sender address
+func VerifyTx(chainID string, tx types.Transaction) error {
+	pub := crypto.PubKey(tx.PubKey)
+	if len(pub) == 0 || len(tx.Signature) == 0 {
+		return fmt.Errorf("transaction is not signed")
+	}
+	if pub.Address() != tx.From {
+		return fmt.Errorf("public key does not match sender %s", tx.From)
+	}
+	if !pub.Verify(tx.SignBytes(chainID), tx.Signature) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// BroadcastTx checks the signature, nonce and cost of a transaction and
+// queues it for the next block. It returns the transaction with its hash
+// set.
+func (app *UEApp) BroadcastTx(tx types.Transaction) (types.Transaction, error) {
+	if app.mempool == nil {
+		return types.Transaction{}, fmt.Errorf("node storage is not open")
+	}
+	if err := tx.Validate(); err != nil {
+		return types.Transaction{}, err
+	}
+	if err := VerifyTx(app.chainID, tx); err != nil {
+		return types.Transaction{}, err
+	}
+
+	// Holding the state lock serializes broadcasts with each other and
+	// with block production, which reaps the mempool under the same lock
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if err := app.checkPending(tx); err != nil {
+		return types.Transaction{}, err
+	}
+	return app.mempool.Add(tx)
+}
+
+// checkPending verifies that a transaction can follow the sender's pending
+// transactions: its nonce must come right after theirs, and the sender's
+// balance must cover the fees and amounts of all of them. Otherwise a
+// sender could fill blocks with transactions that fail before paying a
+// fee. Callers must hold app.mu.
+func (app *UEApp) checkPending(tx types.Transaction) error {
+	pending := app.mempool.PendingTxs(tx.From)
+	if next := app.nonces[tx.From] + uint64(len(pending)); tx.Nonce != next {
+		return fmt.Errorf("invalid nonce %d, the next nonce of %s is %d", tx.Nonce, tx.From, next)
+	}
+
+	cost := types.Coins{}
+	for _, queued := range append(pending, tx) {
+		var err error
+		if cost, err = cost.SafeAdd(queued.Fee()); err == nil {
+			cost, err = cost.SafeAdd(types.Coins{queued.Amount})
+		}
+		if err != nil {
+			return fmt.Errorf("cost of pending transactions overflows: %v", err)
+		}
+	}
+
+	balance := app.treasury.GetAllBalances(app.queryContext(), tx.From)
+	if !balance.IsAllGTE(cost) {
+		return fmt.Errorf("balance of %s (%s) cannot cover the fees and amounts of its pending transactions (%s)", tx.From, balance, cost)
+	}
+	return nil
+}
+
+// BroadcastTxCommit queues a transaction like BroadcastTx, then waits up
+// to timeout for a block to commit it
+func (app *UEApp) BroadcastTxCommit(tx types.Transaction, timeout time.Duration) (types.Transaction, TxResult, error) {
+	// Subscribe before queueing so the block cannot be committed unnoticed
+	query, err := ParseEventQuery(fmt.Sprintf("event='%s' AND tx.hash='%s'", EventTx, tx.CalculateHash()))
+	if err != nil {
+		return types.Transaction{}, TxResult{}, err
+	}
+	sub, err := app.events.Subscribe(query, 1)
+	if err != nil {
+		return types.Transaction{}, TxResult{}, err
+	}
+	defer app.events.Unsubscribe(sub)
+
+	tx, err = app.BroadcastTx(tx)
+	if err != nil {
+		return types.Transaction{}, TxResult{}, err
+	}
+
+	select {
+	case event, ok := <-sub.Events():
+		if !ok {
+			return tx, TxResult{}, fmt.Errorf("stopped waiting for transaction %s: %v", tx.Hash, sub.Err())
+		}
+		return tx, event.Data.(CommittedTx).Result, nil
+	case <-time.After(timeout):
+		return tx, TxResult{}, fmt.Errorf("transaction %s was not committed within %s", tx.Hash, timeout)
+	}
+}
+
+// NextNonce returns the nonce the next transaction of an account should
+// use, counting its transactions still waiting in the mempool
+func (app *UEApp) NextNonce(address types.Address) uint64 {
+	app.mu.RLock()
+	next := app.nonces[address]
+	app.mu.RUnlock()
+
+	if app.mempool != nil {
+		next += uint64(app.mempool.Pending(address))
+	}
+	return next
+}
+
+// checkNonce consumes the nonce of a transaction being delivered. Each
+// account's transactions must use consecutive nonces from zero, so a
+// committed transaction cannot be replayed.
+func (app *UEApp) checkNonce(tx types.Transaction) error {
+	if next := app.nonces[tx.From]; tx.Nonce != next {
+		return fmt.Errorf("invalid nonce %d, expected %d", tx.Nonce, next)
+	}
+	app.nonces[tx.From]++
+	return nil
+}
@@ -1,10 +1,23 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"undergroundempire/core/types"
+	"undergroundempire/modules/circuit"
+	"undergroundempire/modules/consensus"
+	"undergroundempire/modules/governance"
+	"undergroundempire/modules/mint"
+	"undergroundempire/modules/params"
+	"undergroundempire/modules/tokenfactory"
+	"undergroundempire/modules/treasury"
+	"undergroundempire/modules/upgrade"
+	"undergroundempire/modules/validator"
 )
 
 // UEApp represents the main Underground Empire application
@@ -14,11 +27,42 @@ type UEApp struct {
 	startTime time.Time
 	isRunning bool
 
+	// Chain identity, set from the genesis document
+	chainID     string
+	genesisTime time.Time
+
 	// Core components (to be implemented in future commits)
 	validatorRegistry ValidatorRegistry
 	consensusEngine   ConsensusEngine
 	treasuryManager   TreasuryManager
 	governanceSystem  GovernanceSystem
+
+	// Modules
+	treasury     *treasury.TreasuryManager
+	validators   *validator.ValidatorManager
+	mint         *mint.MintManager
+	governance   *governance.GovernanceManager
+	params       *params.ParamsManager
+	upgrade      *upgrade.UpgradeManager
+	circuit      *circuit.CircuitBreaker
+	tokenFactory *tokenfactory.TokenFactoryManager
+
+	// Node runtime, set up by Open
+	config    Config
+	store     *BlockStore
+	mempool   *Mempool
+	consensus *consensus.InMemoryConsensusEngine
+	nonces    map[types.Address]uint64 // Next nonce of each account
+	txResults map[string]TxResult      // Result of each committed transaction by hash
+	events    *EventBus
+	pending   []Event // Events queued by the block being executed
+	replaying bool    // Set while Open replays the stored blocks
+	logger    types.Logger
+	logFile   io.Closer
+	mu        sync.RWMutex // Serializes block execution with state queries
+	quit      chan struct{}
+	done      chan struct{}
+	haltErr   error
 }
 
 // ValidatorRegistry interface for validator management
@@ -49,11 +93,17 @@ type TreasuryManager interface {
 // GovernanceSystem interface for governance operations
 type GovernanceSystem interface {
 	SubmitProposal(ctx types.Context, proposal GovernanceProposal) error
+	Deposit(ctx types.Context, proposalID uint64, depositor types.Address, amount types.Int) error
 	Vote(ctx types.Context, proposalID uint64, voter types.Address, option VoteOption) error
 	GetProposal(ctx types.Context, proposalID uint64) (GovernanceProposal, error)
+	GetProposals(ctx types.Context, status ProposalStatus) []GovernanceProposal
+	Tally(ctx types.Context, proposalID uint64) (governance.TallyResult, error)
 	ExecuteProposal(ctx types.Context, proposalID uint64) error
 }
 
+// Compile-time check that the governance module implements GovernanceSystem
+var _ GovernanceSystem = (*governance.GovernanceManager)(nil)
+
 // ValidatorNode represents a validator in the network
 type ValidatorNode struct {
 	ID          string
@@ -128,100 +178,478 @@ type Transaction struct {
 }
 
 // GovernanceProposal represents a governance proposal
-type GovernanceProposal struct {
-	ID          uint64
-	Title       string
-	Description string
-	Proposer    types.Address
-	Status      ProposalStatus
-	Votes       map[VoteOption]uint64
-	CreatedAt   time.Time
-	EndTime     time.Time
-}
+type GovernanceProposal = types.GovernanceProposal
 
 // ProposalStatus represents the status of a proposal
-type ProposalStatus string
+type ProposalStatus = types.ProposalStatus
 
 const (
-	ProposalStatusActive   ProposalStatus = "active"
-	ProposalStatusPassed   ProposalStatus = "passed"
-	ProposalStatusRejected ProposalStatus = "rejected"
-	ProposalStatusExecuted ProposalStatus = "executed"
+	ProposalStatusDeposit  = types.ProposalStatusDeposit
+	ProposalStatusActive   = types.ProposalStatusActive
+	ProposalStatusPassed   = types.ProposalStatusPassed
+	ProposalStatusRejected = types.ProposalStatusRejected
+	ProposalStatusExecuted = types.ProposalStatusExecuted
+	ProposalStatusFailed   = types.ProposalStatusFailed
 )
 
 // VoteOption represents a voting option
-type VoteOption string
+type VoteOption = types.VoteOption
 
 const (
-	VoteOptionYes     VoteOption = "yes"
-	VoteOptionNo      VoteOption = "no"
-	VoteOptionAbstain VoteOption = "abstain"
+	VoteOptionYes        = types.VoteOptionYes
+	VoteOptionNo         = types.VoteOptionNo
+	VoteOptionAbstain    = types.VoteOptionAbstain
+	VoteOptionNoWithVeto = types.VoteOptionNoWithVeto
 )
 
-// NewUEApp creates a new Underground Empire application
+// NewUEApp creates a new Underground Empire application and wires its modules
 func NewUEApp(version string) *UEApp {
-	return &UEApp{
-		version:   version,
-		startTime: time.Now(),
-		isRunning: false,
+	app := &UEApp{
+		version:    version,
+		startTime:  time.Now(),
+		isRunning:  false,
+		treasury:   treasury.NewTreasuryManager(),
+		validators: validator.NewValidatorManager(),
+		params:     params.NewParamsManager(types.DefaultChainParams()),
+		upgrade:    upgrade.NewUpgradeManager(),
+		circuit:    circuit.NewCircuitBreaker(treasury.TransfersRoute, validator.StakingRoute),
+		nonces:     make(map[types.Address]uint64),
+		txResults:  make(map[string]TxResult),
+		events:     NewEventBus(),
+	}
+
+	app.validators.SetBankKeeper(app.treasury)
+	app.treasury.SetCircuitKeeper(app.circuit)
+	app.validators.SetCircuitKeeper(app.circuit)
+	app.mint = mint.NewMintManager(app.treasury, app.validators)
+	app.governance = governance.NewGovernanceManager(app.treasury, app.validators)
+	app.tokenFactory = tokenfactory.NewTokenFactoryManager(app.treasury)
+
+	// Parameters governance can change
+	app.params.RegisterSubspace(params.NewSubspace("validator", app.validators.GetParams, app.validators.SetParams))
+	app.params.RegisterSubspace(params.NewSubspace("mint", app.mint.GetParams, app.mint.SetParams))
+	app.params.RegisterSubspace(params.NewSubspace("governance", app.governance.GetParams, app.governance.SetParams))
+	app.params.RegisterSubspace(params.NewSubspace("tokenfactory", app.tokenFactory.GetParams, app.tokenFactory.SetParams))
+
+	// Proposal types and the modules that execute them
+	app.governance.RegisterProposalHandler(types.ProposalTypeParameterChange, app.params.HandleParameterChangeProposal)
+	app.governance.RegisterProposalHandler(types.ProposalTypeSoftwareUpgrade, app.upgrade.HandleSoftwareUpgradeProposal)
+	app.governance.RegisterProposalHandler(types.ProposalTypeCommunitySpend, app.treasury.HandleCommunityPoolSpendProposal)
+	app.governance.RegisterProposalHandler(types.ProposalTypeCircuitBreaker, app.circuit.HandleCircuitBreakerProposal)
+
+	// Migrations of the upgrades this binary implements
+	for name, handler := range upgradeHandlers {
+		app.upgrade.SetUpgradeHandler(name, handler)
 	}
+
+	app.treasuryManager = app.treasury
+	app.governanceSystem = app.governance
+	return app
 }
 
-// InitializeChain initializes the blockchain
-func (app *UEApp) InitializeChain() error {
-	fmt.Println("Initializing Underground Empire blockchain...")
+// InitializeChain validates a genesis document and loads it into state:
+// module parameters, funded accounts and bonded validators
+func (app *UEApp) InitializeChain(genesis GenesisDoc) error {
+	if app.chainID != "" {
+		return fmt.Errorf("chain %s is already initialized", app.chainID)
+	}
+	if err := genesis.Validate(); err != nil {
+		return fmt.Errorf("invalid genesis: %v", err)
+	}
 
-	// TODO: Implement actual chain initialization
-	// This is a placeholder for the first commit
+	app.logger.Infof("Initializing Underground Empire blockchain %s...", genesis.ChainID)
 
-	fmt.Println("Blockchain initialization complete")
+	if err := app.params.SetChainParams(genesis.Params.Chain); err != nil {
+		return err
+	}
+	if err := app.validators.SetParams(genesis.Params.Validator); err != nil {
+		return err
+	}
+	if err := app.mint.SetParams(genesis.Params.Mint); err != nil {
+		return err
+	}
+	if err := app.governance.SetParams(genesis.Params.Governance); err != nil {
+		return err
+	}
+	if err := app.tokenFactory.SetParams(genesis.Params.TokenFactory); err != nil {
+		return err
+	}
+
+	ctx := types.NewContext(context.Background(), 0, genesis.GenesisTime, genesis.ChainID).WithChainParams(genesis.Params.Chain)
+
+	for _, account := range genesis.Accounts {
+		for _, coin := range account.Coins {
+			if err := app.treasury.MintTokens(ctx, account.Address, coin); err != nil {
+				return fmt.Errorf("failed to fund genesis account %s: %v", account.Address, err)
+			}
+		}
+	}
+
+	for _, val := range genesis.Validators {
+		err := app.validators.RegisterNode(ctx, validator.ValidatorNode{
+			ID:          val.ID,
+			Address:     val.Address,
+			StakeAmount: val.Stake,
+			Commission:  val.Commission,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to bond genesis validator %s: %v", val.ID, err)
+		}
+	}
+
+	app.chainID = genesis.ChainID
+	app.genesisTime = genesis.GenesisTime
+
+	app.logger.Infof("Blockchain initialization complete: %d accounts, %d validators",
+		len(genesis.Accounts), len(genesis.Validators))
 	return nil
 }
 
-// ProcessBlockStart processes the start of a block
+// EventBus returns the bus the node publishes the events of committed
+// blocks on
+func (app *UEApp) EventBus() *EventBus {
+	return app.events
+}
+
+// GetChainID returns the chain ID loaded from genesis
+func (app *UEApp) GetChainID() string {
+	return app.chainID
+}
+
+// ProcessBlockStart applies scheduled upgrades and parameter changes, then
+// mints the block provision. An error halts the chain.
 func (app *UEApp) ProcessBlockStart(ctx types.Context) error {
-	// TODO: Implement block start processing
-	// This is a placeholder for the first commit
+	if err := app.upgrade.ProcessBlockStart(ctx); err != nil {
+		return err
+	}
+	if err := app.params.ProcessBlockStart(ctx); err != nil {
+		return err
+	}
+	if _, err := app.mint.ProcessBlockStart(ctx.WithChainParams(app.params.GetChainParams())); err != nil {
+		return err
+	}
 	return nil
 }
 
-// ProcessBlockEnd processes the end of a block
+// ProcessBlockEnd closes governance periods and rotates the validator set
+// at epoch boundaries, handing the new active set to consensus
 func (app *UEApp) ProcessBlockEnd(ctx types.Context) error {
-	// TODO: Implement block end processing
-	// This is a placeholder for the first commit
+	if err := app.governance.ProcessBlockEnd(ctx); err != nil {
+		return err
+	}
+
+	if updates := app.validators.ProcessBlockEnd(ctx); len(updates) > 0 {
+		app.pending = append(app.pending, validatorSetEvent(ctx.Height, updates))
+		if app.consensus != nil {
+			app.consensus.SetValidators(app.validators.GetActiveValidators(ctx))
+			app.logger.Infof("Validator set updated at height %d: %d change(s)", ctx.Height, len(updates))
+		}
+	}
+	return nil
+}
+
+// applyBlock executes a finalized block: block start, its transactions,
+// reward allocation to the proposer and validators, then block end. Failed
+// transactions are skipped; they do not invalidate the block. It returns
+// the events of the block, to publish once it is committed. Callers must
+// hold app.mu.
+func (app *UEApp) applyBlock(block types.BlockData) ([]Event, error) {
+	ctx := types.NewContext(context.Background(), block.Height, block.Timestamp, app.chainID).WithChainParams(app.params.GetChainParams())
+	app.pending = nil
+	statuses := app.proposalStatuses(ctx)
+
+	if err := app.ProcessBlockStart(ctx); err != nil {
+		return nil, err
+	}
+	// Parameter changes scheduled for this height took effect at block
+	// start, so the rest of the block runs with them
+	ctx = ctx.WithChainParams(app.params.GetChainParams())
+
+	results := make([]TxResult, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		results[i] = TxResult{Height: block.Height, Index: i}
+		if err := app.deliverTx(ctx, tx); err != nil {
+			results[i].Error = err.Error()
+			app.logger.Infof("Transaction %s failed at height %d: %v", tx.Hash, block.Height, err)
+		}
+		app.txResults[tx.Hash] = results[i]
+	}
+
+	if err := app.validators.AllocateTokens(ctx, block.Proposer); err != nil {
+		return nil, fmt.Errorf("failed to allocate rewards: %v", err)
+	}
+	if err := app.ProcessBlockEnd(ctx); err != nil {
+		return nil, err
+	}
+
+	events := append(blockEvents(block, results), app.pending...)
+	app.pending = nil
+	for _, proposal := range app.governanceSystem.GetProposals(ctx, "") {
+		if previous, exists := statuses[proposal.ID]; !exists || previous != proposal.Status {
+			change := ProposalStatusChange{ProposalID: proposal.ID, PreviousStatus: previous, Status: proposal.Status}
+			events = append(events, proposalStatusEvent(block.Height, change))
+		}
+	}
+	return events, nil
+}
+
+// proposalStatuses returns the status of every proposal by ID
+func (app *UEApp) proposalStatuses(ctx types.Context) map[uint64]ProposalStatus {
+	statuses := make(map[uint64]ProposalStatus)
+	for _, proposal := range app.governanceSystem.GetProposals(ctx, "") {
+		statuses[proposal.ID] = proposal.Status
+	}
+	return statuses
+}
+
+// deliverTx checks the signature and nonce of a transaction, then routes
+// it to the module it is addressed to. Plain transfers go to the treasury.
+func (app *UEApp) deliverTx(ctx types.Context, tx types.Transaction) error {
+	if err := VerifyTx(app.chainID, tx); err != nil {
+		return err
+	}
+	if err := app.checkNonce(tx); err != nil {
+		return err
+	}
+
+	switch tx.To {
+	case types.ModuleAddress(types.TokenFactoryModuleName):
+		return types.ExecuteModuleTx(ctx, tx, types.TokenFactoryModuleName, app.treasury, app.tokenFactory.HandleMsg)
+	case types.ModuleAddress(types.ValidatorModuleName):
+		return types.ExecuteModuleTx(ctx, tx, types.ValidatorModuleName, app.treasury, app.validators.HandleMsg)
+	case types.ModuleAddress(types.GovernanceModuleName):
+		return types.ExecuteModuleTx(ctx, tx, types.GovernanceModuleName, app.treasury, app.governance.HandleMsg)
+	default:
+		return app.treasury.ExecuteTransaction(ctx, tx)
+	}
+}
+
+// OpenNode creates an application and opens a node home with it. The
+// config is usually resolved by LoadConfig.
+func OpenNode(version, home string, config Config) (*UEApp, error) {
+	app := NewUEApp(version)
+	if err := app.Open(home, config); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// SetUpgradeHandler registers the migrations of an upgrade the binary
+// implements. Handlers must be registered before Open, since replaying the
+// stored blocks runs the upgrades they contain.
+func (app *UEApp) SetUpgradeHandler(name string, handler upgrade.UpgradeHandler) {
+	app.upgrade.SetUpgradeHandler(name, handler)
+}
+
+// Open loads the genesis of a node home, initializes the chain, opens the
+// node's storage and replays the stored blocks so the node resumes at the
+// height it stopped at. The node logs as set by the log config from here
+// on.
+func (app *UEApp) Open(home string, config Config) (err error) {
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+	logger, logFile, err := openLogger(home, config.Log)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			logFile.Close()
+		}
+	}()
+	app.SetLogger(logger)
+	app.logFile = logFile
+
+	genesis, err := ReadGenesis(filepath.Join(home, "config", GenesisFile))
+	if err != nil {
+		return err
+	}
+	if len(genesis.Validators) == 0 {
+		return fmt.Errorf("genesis has no validators; add them with gentx and collect-gentxs")
+	}
+
+	app.config = config
+	if err := app.InitializeChain(genesis); err != nil {
+		return err
+	}
+
+	dataDir := filepath.Join(home, "data")
+	ledger, err := validator.OpenFileSlashLedger(filepath.Join(dataDir, validator.SlashLedgerFile))
+	if err != nil {
+		return err
+	}
+	app.validators.SetSlashLedger(eventSlashLedger{SlashLedger: ledger, app: app})
+	app.upgrade.SetUpgradeInfoPath(filepath.Join(dataDir, upgrade.UpgradeInfoFile))
+
+	ctx := types.Context{Params: app.params.GetChainParams()}
+	app.mempool = NewMempool(config.Mempool.Size, config.Mempool.MaxTxBytes)
+	app.consensus = consensus.NewInMemoryConsensusEngine(app.validators, app.validators.GetActiveValidators(ctx))
+	app.consensus.SetMempool(app.mempool)
+	app.consensus.SetLogger(logger.With("Consensus"))
+	app.consensus.SetRetainBlocks(config.Pruning.KeepRecent)
+
+	app.store, err = OpenBlockStore(filepath.Join(dataDir, BlockStoreFile), config.Pruning.KeepRecent)
+	if err != nil {
+		return err
+	}
+
+	height := app.store.Height()
+	app.setReplaying(true)
+	defer app.setReplaying(false)
+	for h := uint64(1); h <= height; h++ {
+		block, err := app.store.GetBlock(h)
+		if err == nil {
+			app.mu.Lock()
+			_, err = app.applyBlock(block)
+			app.mu.Unlock()
+		}
+		if err != nil {
+			app.store.Close()
+			return fmt.Errorf("failed to replay block %d: %v", h, err)
+		}
+		app.consensus.SetLastBlock(block)
+	}
+	if height > 0 {
+		app.logger.Infof("Replayed %d stored blocks", height)
+	}
+
 	return nil
 }
 
-// Start starts the application
+// setReplaying marks the blocks being executed as replayed from the store
+func (app *UEApp) setReplaying(replaying bool) {
+	app.replaying = replaying
+	app.upgrade.SetReplaying(replaying)
+}
+
+// ProduceBlock runs a consensus round for the next height, executes the
+// finalized block and commits it to the block store
+func (app *UEApp) ProduceBlock() (types.BlockData, error) {
+	if app.consensus == nil || app.store == nil {
+		return types.BlockData{}, fmt.Errorf("node storage is not open")
+	}
+
+	// The state lock is held from reaping the mempool until the block is
+	// applied, so broadcasts see each transaction either pending or
+	// committed
+	app.mu.Lock()
+	block, events, err := app.executeNextBlock()
+	app.mu.Unlock()
+	if err != nil {
+		return types.BlockData{}, err
+	}
+	if err := app.store.SaveBlock(*block); err != nil {
+		return types.BlockData{}, err
+	}
+	app.events.Publish(events...)
+	return *block, nil
+}
+
+// executeNextBlock runs a consensus round for the next height and applies
+// the finalized block. Callers must hold app.mu.
+func (app *UEApp) executeNextBlock() (*types.BlockData, []Event, error) {
+	app.consensus.SetChainParams(app.params.GetChainParams())
+	block, err := app.consensus.ProposeBlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	app.consensus.PreVote(block)
+	app.consensus.PreCommit(block)
+	if err := app.consensus.FinalizeBlock(block); err != nil {
+		return nil, nil, err
+	}
+
+	events, err := app.applyBlock(*block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, events, nil
+}
+
+// Start begins producing a block every BlockTime seconds in the
+// background. Peer networking is not implemented yet: the in-process
+// consensus engine votes for every validator, so each node runs its own
+// copy of the chain.
 func (app *UEApp) Start() error {
 	if app.isRunning {
 		return fmt.Errorf("application is already running")
 	}
+	if app.store == nil {
+		return fmt.Errorf("node storage is not open")
+	}
 
-	fmt.Println("Starting Underground Empire application...")
-	app.isRunning = true
+	app.logger.Infof("Starting Underground Empire application...")
+	if peers := len(app.config.P2P.PersistentPeers); peers > 0 {
+		app.logger.Infof("Peer networking is not available yet, ignoring %d persistent peer(s)", peers)
+	}
 
-	// TODO: Implement actual application startup
-	// This is a placeholder for the first commit
+	app.quit = make(chan struct{})
+	app.done = make(chan struct{})
+	app.haltErr = nil
+	app.startTime = time.Now()
+	app.isRunning = true
+	go app.run()
 
-	fmt.Println("Application started successfully")
+	app.logger.Infof("Application started successfully at height %d", app.store.Height())
 	return nil
 }
 
-// Stop stops the application
+// run produces blocks until the node is stopped or a block halts the chain
+func (app *UEApp) run() {
+	defer close(app.done)
+
+	for {
+		interval := app.config.Consensus.TimeoutCommit
+		if interval == 0 {
+			interval = time.Duration(app.params.GetChainParams().BlockTime) * time.Second
+		}
+		select {
+		case <-app.quit:
+			return
+		case <-time.After(interval):
+		}
+
+		block, err := app.ProduceBlock()
+		if err != nil {
+			app.haltErr = err
+			app.logger.Errorf("Chain halted: %v", err)
+			return
+		}
+		app.logger.Infof("Committed block %d (%s) with %d transaction(s)", block.Height, block.Hash, len(block.Transactions))
+	}
+}
+
+// Halted is closed when block production stops, either because the node
+// was stopped or because a block halted the chain
+func (app *UEApp) Halted() <-chan struct{} {
+	return app.done
+}
+
+// HaltError returns the error that halted the chain, if any
+func (app *UEApp) HaltError() error {
+	<-app.done
+	return app.haltErr
+}
+
+// Stop waits for the block in progress to be committed, then flushes and
+// closes the node's storage and its log file
 func (app *UEApp) Stop() error {
 	if !app.isRunning {
 		return fmt.Errorf("application is not running")
 	}
 
-	fmt.Println("Stopping Underground Empire application...")
+	app.logger.Infof("Stopping Underground Empire application...")
+	close(app.quit)
+	<-app.done
 	app.isRunning = false
 
-	// TODO: Implement actual application shutdown
-	// This is a placeholder for the first commit
+	if err := app.store.Close(); err != nil {
+		return err
+	}
 
-	fmt.Println("Application stopped successfully")
-	return nil
+	app.logger.Infof("Application stopped successfully at height %d", app.store.Height())
+	return app.logFile.Close()
 }
 
 // GetVersion returns the application version
@@ -0,0 +1,534 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"undergroundempire/core/crypto"
+	"undergroundempire/core/types"
+	"undergroundempire/modules/governance"
+	"undergroundempire/modules/params"
+	"undergroundempire/modules/upgrade"
+	"undergroundempire/modules/validator"
+)
+
+func testNodeHome(t *testing.T) (string, TestnetNode) {
+	opts := DefaultTestnetOptions()
+	opts.Validators = 2
+	opts.OutputDir = t.TempDir()
+
+	nodes, err := InitTestnet(opts)
+	if err != nil {
+		t.Fatalf("init testnet: %v", err)
+	}
+	return nodes[0].Home, nodes[0]
+}
+
+// testConfig loads the config of a node home, keeping a single block in
+// memory so older blocks are read back from disk
+func testConfig(t *testing.T, home string) Config {
+	config, err := LoadConfig(home)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	config.Pruning.KeepRecent = 1
+	return config
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	home := t.TempDir()
+	path := filepath.Join(home, "config", ConfigFile)
+
+	file := DefaultConfig()
+	if err := file.Set("consensus.timeout_commit", "2s"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := file.Set("p2p.persistent_peers", "127.0.0.1:1, 127.0.0.1:2"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	file.Mempool.Size = 10
+	if err := file.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	t.Setenv("UED_MEMPOOL_SIZE", "20")
+	config, err := LoadConfig(home)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if config.Mempool.Size != 20 {
+		t.Fatalf("expected the environment to override the file, got mempool size %d", config.Mempool.Size)
+	}
+	if config.Consensus.TimeoutCommit != 2*time.Second || len(config.P2P.PersistentPeers) != 2 {
+		t.Fatalf("expected file settings to be loaded: %+v", config)
+	}
+	if config.RPC.ListenAddress != DefaultConfig().RPC.ListenAddress {
+		t.Fatalf("expected defaults for settings missing from the file, got %s", config.RPC.ListenAddress)
+	}
+
+	if err := config.Set("p2p.unknown", "x"); err == nil {
+		t.Fatal("expected an unknown key to be rejected")
+	}
+	if err := config.Set("mempool.size", "many"); err == nil {
+		t.Fatal("expected a non-numeric size to be rejected")
+	}
+
+	t.Setenv("UED_LOG_LEVEL", "verbose")
+	if _, err := LoadConfig(home); err == nil {
+		t.Fatal("expected an invalid environment override to be rejected")
+	}
+}
+
+func TestNodeProducesAndReplaysBlocks(t *testing.T) {
+	home, operator := testNodeHome(t)
+	recipient := types.Address{0xaa}
+
+	node, err := OpenNode("test", home, testConfig(t, home))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	key, err := crypto.LoadKeyFile(filepath.Join(home, "config", ValidatorKeyFile))
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+
+	unsigned := types.NewTransaction(operator.Address, recipient, types.NewUECoins(types.NewUEAmount(5)), 21000, types.DefaultGasPrice, nil, 0)
+	if _, err := node.BroadcastTx(unsigned); err == nil {
+		t.Fatal("expected an unsigned transaction to be rejected")
+	}
+	if _, err := node.BroadcastTx(SignTx(key.PrivKey, "other-chain", unsigned)); err == nil {
+		t.Fatal("expected a transaction signed for another chain to be rejected")
+	}
+
+	tx, err := node.BroadcastTx(SignTx(key.PrivKey, node.GetChainID(), unsigned))
+	if err != nil {
+		t.Fatalf("broadcast tx: %v", err)
+	}
+	if _, err := node.BroadcastTx(tx); err == nil {
+		t.Fatal("expected a duplicate transaction to be rejected")
+	}
+	if next := node.GetAccount(operator.Address).Nonce; next != 1 {
+		t.Fatalf("next nonce with a pending tx = %d, want 1", next)
+	}
+
+	first, err := node.ProduceBlock()
+	if err != nil {
+		t.Fatalf("block 1: %v", err)
+	}
+	second, err := node.ProduceBlock()
+	if err != nil {
+		t.Fatalf("block 2: %v", err)
+	}
+	if len(first.Transactions) != 1 || second.ParentHash != first.Hash || node.mempool.Size() != 0 {
+		t.Fatalf("unexpected blocks: %+v %+v", first, second)
+	}
+	if _, err := node.BroadcastTx(tx); err == nil {
+		t.Fatal("expected a committed transaction to be rejected as a replay")
+	}
+
+	ctx := types.Context{Params: node.params.GetChainParams()}
+	if got := node.treasury.GetBalance(ctx, recipient).Amount; !got.Equal(types.NewUEAmount(5)) {
+		t.Fatalf("recipient balance = %s, want 5UE", got)
+	}
+	operatorBalance := node.treasury.GetBalance(ctx, operator.Address).Amount
+
+	if err := node.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := node.Stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if err := node.HaltError(); err != nil {
+		t.Fatalf("unexpected halt: %v", err)
+	}
+
+	// A restarted node replays the stored blocks into the same state
+	restarted, err := OpenNode("test", home, testConfig(t, home))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer restarted.store.Close()
+
+	if restarted.store.Height() != 2 {
+		t.Fatalf("restarted at height %d, want 2", restarted.store.Height())
+	}
+	if got := restarted.treasury.GetBalance(ctx, operator.Address).Amount; !got.Equal(operatorBalance) {
+		t.Fatalf("operator balance after replay = %s, want %s", got, operatorBalance)
+	}
+	if stored, _, err := restarted.store.GetTx(tx.Hash); err != nil || stored.Hash != tx.Hash {
+		t.Fatalf("stored tx lookup: %v", err)
+	}
+
+	third, err := restarted.ProduceBlock()
+	if err != nil || third.Height != 3 || third.ParentHash != second.Hash {
+		t.Fatalf("block 3 after restart: %+v, %v", third, err)
+	}
+}
+
+func TestBlockStoreTruncatesIncompleteBlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", BlockStoreFile)
+	store, err := OpenBlockStore(path, 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	first := types.BlockData{Height: 1, Hash: "0x01"}
+	if err := store.SaveBlock(first); err != nil {
+		t.Fatalf("save block 1: %v", err)
+	}
+	store.Close()
+
+	// A crash while writing block 2 leaves part of its line behind
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open file: %v", err)
+	}
+	file.WriteString(`{"Height":2,"Pare`)
+	file.Close()
+
+	store, err = OpenBlockStore(path, 0)
+	if err != nil {
+		t.Fatalf("reopen after an interrupted write: %v", err)
+	}
+	if store.Height() != 1 {
+		t.Fatalf("height after truncation = %d, want 1", store.Height())
+	}
+	if err := store.SaveBlock(types.BlockData{Height: 2, Hash: "0x02", ParentHash: first.Hash}); err != nil {
+		t.Fatalf("save block 2: %v", err)
+	}
+	store.Close()
+
+	store, err = OpenBlockStore(path, 0)
+	if err != nil || store.Height() != 2 {
+		t.Fatalf("reopen: height %d, %v", store.Height(), err)
+	}
+	store.Close()
+}
+
+func TestReplayDoesNotReappendSlashes(t *testing.T) {
+	home, _ := testNodeHome(t)
+	node, err := OpenNode("test", home, testConfig(t, home))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer node.store.Close()
+
+	ledger, err := validator.OpenFileSlashLedger(filepath.Join(home, "data", validator.SlashLedgerFile))
+	if err != nil {
+		t.Fatalf("open ledger: %v", err)
+	}
+	record := validator.SlashRecord{ValidatorID: "node0", Reason: validator.SlashReasonDowntime, Height: 1}
+	events := eventSlashLedger{SlashLedger: ledger, app: node}
+
+	node.replaying = true
+	if err := events.Append(record); err != nil {
+		t.Fatalf("append while replaying: %v", err)
+	}
+	if records := ledger.Records("node0"); len(records) != 0 {
+		t.Fatalf("replayed slash was appended again: %+v", records)
+	}
+
+	node.replaying = false
+	if err := events.Append(record); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if len(ledger.Records("node0")) != 1 || len(node.pending) != 1 {
+		t.Fatalf("expected the slash to be recorded once with its event, got %d events", len(node.pending))
+	}
+}
+
+func TestNodeAppliesUpgradeAfterHalt(t *testing.T) {
+	opts := DefaultTestnetOptions()
+	opts.Validators = 1
+	opts.OutputDir = t.TempDir()
+	nodes, err := InitTestnet(opts)
+	if err != nil {
+		t.Fatalf("init testnet: %v", err)
+	}
+	home := nodes[0].Home
+
+	genesisPath := filepath.Join(home, "config", GenesisFile)
+	genesis, err := ReadGenesis(genesisPath)
+	if err != nil {
+		t.Fatalf("read genesis: %v", err)
+	}
+	genesis.Params.Governance.VotingPeriod = 2
+	genesis.Params.Governance.ExpeditedVotingPeriod = 1
+	if err := genesis.Save(genesisPath); err != nil {
+		t.Fatalf("save genesis: %v", err)
+	}
+	key, err := crypto.LoadKeyFile(filepath.Join(home, "config", ValidatorKeyFile))
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+
+	node, err := OpenNode("v1", home, testConfig(t, home))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	send := func(nonce uint64, msgType string, content interface{}) {
+		data, err := types.NewModuleMsg(msgType, content)
+		if err != nil {
+			t.Fatalf("new msg: %v", err)
+		}
+		tx := types.NewTransaction(key.Address, types.ModuleAddress(types.GovernanceModuleName), types.NewUECoins(types.ZeroInt()), 200000, types.DefaultGasPrice, data, nonce)
+		if _, err := node.BroadcastTx(SignTx(key.PrivKey, node.GetChainID(), tx)); err != nil {
+			t.Fatalf("broadcast %s: %v", msgType, err)
+		}
+		if _, err := node.ProduceBlock(); err != nil {
+			t.Fatalf("produce block: %v", err)
+		}
+	}
+
+	// The old binary schedules the upgrade through governance and halts at
+	// its height
+	plan, _ := json.Marshal(upgrade.Plan{Name: "v2", Height: 6})
+	send(0, governance.MsgTypeSubmitProposal, governance.MsgSubmitProposal{
+		Title:          "Upgrade to v2",
+		Description:    "Switch to the v2 binary",
+		Type:           types.ProposalTypeSoftwareUpgrade,
+		Content:        plan,
+		InitialDeposit: genesis.Params.Governance.MinDeposit,
+	})
+	send(1, governance.MsgTypeVote, governance.MsgVote{ProposalID: 1, Option: types.VoteOptionYes})
+
+	var halt upgrade.UpgradeNeededError
+	for node.store.Height() < 6 {
+		if _, err := node.ProduceBlock(); err != nil {
+			if !errors.As(err, &halt) {
+				t.Fatalf("block %d: %v", node.store.Height()+1, err)
+			}
+			break
+		}
+	}
+	if halt.Plan.Name != "v2" || node.store.Height() != 5 {
+		t.Fatalf("expected a halt for v2 after block 5, got %v at height %d", halt, node.store.Height())
+	}
+	node.store.Close()
+
+	// The new binary replays the old blocks, then runs the migration at the
+	// upgrade height and keeps producing blocks, also after a restart
+	applied := 0
+	for restart := 0; restart < 2; restart++ {
+		upgraded := NewUEApp("v2")
+		upgraded.SetUpgradeHandler("v2", func(ctx types.Context, plan upgrade.Plan) error {
+			applied++
+			return nil
+		})
+		if err := upgraded.Open(home, testConfig(t, home)); err != nil {
+			t.Fatalf("open with the upgrade handler: %v", err)
+		}
+		block, err := upgraded.ProduceBlock()
+		if err != nil {
+			t.Fatalf("block after the upgrade: %v", err)
+		}
+		if block.Height != uint64(6+restart) || upgraded.upgrade.GetDoneHeight("v2") != 6 {
+			t.Fatalf("produced block %d, upgrade done at %d", block.Height, upgraded.upgrade.GetDoneHeight("v2"))
+		}
+		upgraded.store.Close()
+	}
+	if applied != 2 {
+		t.Fatalf("migration ran %d times, want once per state rebuild", applied)
+	}
+}
+
+func TestNewUEAppRegistersUpgradeHandlers(t *testing.T) {
+	applied := 0
+	upgradeHandlers["v2"] = func(ctx types.Context, plan upgrade.Plan) error {
+		applied++
+		return nil
+	}
+	defer delete(upgradeHandlers, "v2")
+
+	node := NewUEApp("v2")
+	ctx := types.Context{Height: 1, Params: types.DefaultChainParams()}
+	if err := node.upgrade.ScheduleUpgrade(ctx, upgrade.Plan{Name: "v2", Height: 2}); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if err := node.upgrade.ProcessBlockStart(ctx.WithHeight(2)); err != nil {
+		t.Fatalf("apply upgrade: %v", err)
+	}
+	if applied != 1 || node.upgrade.GetDoneHeight("v2") != 2 {
+		t.Fatalf("migration ran %d times, upgrade done at %d", applied, node.upgrade.GetDoneHeight("v2"))
+	}
+}
+
+func TestNodeLogsToFile(t *testing.T) {
+	home, _ := testNodeHome(t)
+	config := testConfig(t, home)
+	config.Log.Level = "debug"
+	config.Log.File = "ued.log"
+
+	node, err := OpenNode("test", home, config)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := node.ProduceBlock(); err != nil {
+		t.Fatalf("produce block: %v", err)
+	}
+	if err := node.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := node.Stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, "ued.log"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	for _, line := range []string{"[Node] Initializing Underground Empire blockchain", "[Consensus] Block 1 finalized", "[Node] Application stopped"} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("log file is missing %q:\n%s", line, data)
+		}
+	}
+}
+
+func TestBroadcastRequiresNextNonceAndFunds(t *testing.T) {
+	home, operator := testNodeHome(t)
+	node, err := OpenNode("test", home, testConfig(t, home))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer node.store.Close()
+	key, err := crypto.LoadKeyFile(filepath.Join(home, "config", ValidatorKeyFile))
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+
+	ctx := types.Context{Params: node.params.GetChainParams()}
+	liquid := node.treasury.GetBalance(ctx, operator.Address).Amount
+	send := func(priv crypto.PrivKey, amount types.Int, nonce uint64) error {
+		tx := types.NewTransaction(types.Address{}, types.Address{0xaa}, types.NewUECoins(amount), 21000, types.DefaultGasPrice, nil, nonce)
+		_, err := node.BroadcastTx(SignTx(priv, node.GetChainID(), tx))
+		return err
+	}
+
+	if err := send(key.PrivKey, types.NewUEAmount(1), 1); err == nil {
+		t.Fatal("expected a nonce gap to be rejected")
+	}
+	stranger, _ := crypto.GenerateKey()
+	if err := send(stranger, types.ZeroInt(), 0); err == nil {
+		t.Fatal("expected a sender unable to pay the fee to be rejected")
+	}
+
+	// Pending transactions count against the balance
+	half := liquid.QuoRaw(2)
+	if err := send(key.PrivKey, half, 0); err != nil {
+		t.Fatalf("broadcast: %v", err)
+	}
+	if err := send(key.PrivKey, half, 1); err == nil {
+		t.Fatal("expected a transaction the balance cannot cover after the pending one to be rejected")
+	}
+	if err := send(key.PrivKey, types.NewUEAmount(1), 1); err != nil {
+		t.Fatalf("broadcast the next nonce: %v", err)
+	}
+	if node.mempool.Size() != 2 {
+		t.Fatalf("mempool holds %d transactions, want 2", node.mempool.Size())
+	}
+}
+
+func TestScheduledChainParamsApplyAtTheirHeight(t *testing.T) {
+	home, operator := testNodeHome(t)
+	node, err := OpenNode("test", home, testConfig(t, home))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer node.store.Close()
+	key, err := crypto.LoadKeyFile(filepath.Join(home, "config", ValidatorKeyFile))
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+
+	// From height 3 a 1 UE stake is enough to become a validator, height 3
+	// is an epoch boundary with a single active slot and the community tax
+	// is zero
+	const height = 3
+	value := func(v interface{}) json.RawMessage {
+		raw, _ := json.Marshal(v)
+		return raw
+	}
+	changes := []params.ParamChange{
+		{Subspace: params.ChainSubspace, Key: "MinValidatorStake", Value: value(types.NewUEAmount(1))},
+		{Subspace: params.ChainSubspace, Key: "EpochDuration", Value: value(height)},
+		{Subspace: "validator", Key: "MaxValidators", Value: value(1)},
+		{Subspace: "validator", Key: "CommunityTax", Value: value(types.ZeroDec())},
+	}
+	ctx := types.Context{Params: node.params.GetChainParams()}
+	if _, err := node.params.ScheduleChanges(ctx, 1, height, changes); err != nil {
+		t.Fatalf("schedule changes: %v", err)
+	}
+
+	for h := 1; h < height; h++ {
+		if _, err := node.ProduceBlock(); err != nil {
+			t.Fatalf("produce block %d: %v", h, err)
+		}
+	}
+	if active := node.validators.GetActiveValidators(ctx); len(active) != 2 {
+		t.Fatalf("%d active validators before the change, want 2", len(active))
+	}
+	pool := node.treasury.GetCommunityPool(ctx).Amount
+
+	data, err := types.NewModuleMsg(validator.MsgTypeCreateValidator, validator.MsgCreateValidator{
+		ID:         "small",
+		Stake:      types.NewUEAmount(2),
+		Commission: types.NewDecWithPrec(1, 1),
+	})
+	if err != nil {
+		t.Fatalf("new msg: %v", err)
+	}
+	tx := types.NewTransaction(operator.Address, types.ModuleAddress(types.ValidatorModuleName), types.NewUECoins(types.ZeroInt()), 200000, types.DefaultGasPrice, data, 0)
+	tx, err = node.BroadcastTx(SignTx(key.PrivKey, node.GetChainID(), tx))
+	if err != nil {
+		t.Fatalf("broadcast: %v", err)
+	}
+	if _, err := node.ProduceBlock(); err != nil {
+		t.Fatalf("produce block %d: %v", height, err)
+	}
+
+	if result := node.txResults[tx.Hash]; result.Height != height || result.Error != "" {
+		t.Fatalf("create validator at the change height: %+v", result)
+	}
+	if got := node.treasury.GetCommunityPool(ctx).Amount; !got.Equal(pool) {
+		t.Fatalf("community pool grew from %s to %s without a community tax", pool, got)
+	}
+	if active := node.validators.GetActiveValidators(ctx); len(active) != 1 {
+		t.Fatalf("%d active validators after the epoch at height %d, want 1", len(active), height)
+	}
+}
+
+func TestQueryCommunityPoolSpends(t *testing.T) {
+	home, operator := testNodeHome(t)
+	node, err := OpenNode("test", home, testConfig(t, home))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer node.store.Close()
+
+	if spends := node.GetCommunityPoolSpends(); len(spends) != 0 {
+		t.Fatalf("got %d spends before any payout, want 0", len(spends))
+	}
+
+	ctx := node.queryContext()
+	amount := types.NewCoinAmount(types.NewUEAmount(5), types.NativeDenom)
+	if err := node.treasury.FundCommunityPool(ctx, operator.Address, amount); err != nil {
+		t.Fatalf("fund community pool: %v", err)
+	}
+	recipient := types.Address{9}
+	if err := node.treasury.SpendCommunityPool(ctx, 7, recipient, amount); err != nil {
+		t.Fatalf("spend community pool: %v", err)
+	}
+
+	spends := node.GetCommunityPoolSpends()
+	if len(spends) != 1 {
+		t.Fatalf("got %d spends, want 1", len(spends))
+	}
+	if spends[0].ProposalID != 7 || spends[0].Recipient != recipient || !spends[0].Amount.Amount.Equal(amount.Amount) {
+		t.Fatalf("unexpected spend %+v", spends[0])
+	}
+}
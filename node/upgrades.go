@@ -0,0 +1,8 @@
+package app
+
+import "undergroundempire/modules/upgrade"
+
+// upgradeHandlers holds the migrations of the upgrades this binary
+// implements, by upgrade name. A release shipping an upgrade adds its
+// handler here; NewUEApp registers them on every node.
+var upgradeHandlers = map[string]upgrade.UpgradeHandler{}